@@ -0,0 +1,47 @@
+package stats
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+// abortRateThreshold is the share of aborted requests from which a route is listed as Aborted in the summary.
+const abortRateThreshold = 0.05
+
+// WithAbortedLatency includes requests aborted by the client in the response times.
+// By default they are only counted, the time until the client gave up says little about the route.
+func WithAbortedLatency() Option {
+	return func(stats *Collector) {
+		stats.abortedLatency = true
+	}
+}
+
+// Aborted tells you whether the client of the request has disconnected or cancelled it.
+// Framework adapters use it to set RequestRecord.Aborted.
+func Aborted(ctx context.Context) bool {
+	return errors.Is(ctx.Err(), context.Canceled)
+}
+
+// abortRate returns the share of all requests of the route that were aborted by the client.
+func (stats *RouteStatistics) abortRate() float64 {
+	requestCount := atomic.LoadUint64(&stats.requestCount)
+
+	if requestCount == 0 {
+		return 0
+	}
+
+	return float64(atomic.LoadUint64(&stats.abortedCount)) / float64(requestCount)
+}
+
+// averageAbortTime returns the average time after which clients aborted their requests.
+func (stats *RouteStatistics) averageAbortTime() time.Duration {
+	count := atomic.LoadUint64(&stats.abortedCount)
+
+	if count == 0 {
+		return 0
+	}
+
+	return time.Duration(atomic.LoadUint64(&stats.abortedTime) / count)
+}
@@ -0,0 +1,56 @@
+package stats
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// defaultApdexThreshold is the response time up to which a request satisfies the user.
+const defaultApdexThreshold = 500 * time.Millisecond
+
+// WithApdexThreshold sets the Apdex threshold T, 500ms by default.
+// Requests up to T are satisfied, requests up to 4T are tolerated and slower or failed requests frustrate the user.
+func WithApdexThreshold(threshold time.Duration) Option {
+	return func(stats *Collector) {
+		stats.apdexThreshold = threshold
+	}
+}
+
+// apdexCounters counts the measured requests of a route by user satisfaction.
+type apdexCounters struct {
+	satisfied  uint64
+	tolerating uint64
+	total      uint64
+}
+
+// record adds a measured request.
+func (counters *apdexCounters) record(record *RequestRecord, threshold time.Duration) {
+	atomic.AddUint64(&counters.total, 1)
+
+	switch {
+	case record.failed():
+	case record.Duration <= threshold:
+		atomic.AddUint64(&counters.satisfied, 1)
+	case record.Duration <= 4*threshold:
+		atomic.AddUint64(&counters.tolerating, 1)
+	}
+}
+
+// fold adds the counts of another route.
+func (counters *apdexCounters) fold(other *apdexCounters) {
+	atomic.AddUint64(&counters.satisfied, atomic.LoadUint64(&other.satisfied))
+	atomic.AddUint64(&counters.tolerating, atomic.LoadUint64(&other.tolerating))
+	atomic.AddUint64(&counters.total, atomic.LoadUint64(&other.total))
+}
+
+// score returns the Apdex score between 0 and 1, nil if no request was measured.
+func (counters *apdexCounters) score() *float64 {
+	total := atomic.LoadUint64(&counters.total)
+
+	if total == 0 {
+		return nil
+	}
+
+	score := (float64(atomic.LoadUint64(&counters.satisfied)) + float64(atomic.LoadUint64(&counters.tolerating))/2) / float64(total)
+	return &score
+}
@@ -0,0 +1,33 @@
+package stats
+
+// appGauge is a value of the application that is sampled for every snapshot.
+type appGauge struct {
+	name string
+	read func() uint64
+}
+
+// WithAppGauge adds a value of the application to the App section, e.g. the number of active sessions.
+// The function is called whenever a snapshot is taken, so it must be cheap and safe for concurrent use.
+func WithAppGauge(name string, read func() uint64) Option {
+	return func(stats *Collector) {
+		stats.appGauges = append(stats.appGauges, appGauge{
+			name: name,
+			read: read,
+		})
+	}
+}
+
+// sampleAppGauges reads the current value of every application gauge.
+func (stats *Collector) sampleAppGauges() map[string]uint64 {
+	if len(stats.appGauges) == 0 {
+		return nil
+	}
+
+	values := make(map[string]uint64, len(stats.appGauges))
+
+	for _, gauge := range stats.appGauges {
+		values[gauge.name] = gauge.read()
+	}
+
+	return values
+}
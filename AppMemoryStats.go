@@ -0,0 +1,132 @@
+package stats
+
+import (
+	"runtime/metrics"
+	"strings"
+
+	humanize "github.com/dustin/go-humanize"
+)
+
+// AppMemoryStats contains the memory usage of the application.
+// Byte counts are available humanized and raw in the fields with the Bytes suffix.
+// The names match the runtime.MemStats fields they correspond to.
+type AppMemoryStats struct {
+	Allocated        string
+	AllocatedBytes   uint64
+	GCThreshold      string
+	GCThresholdBytes uint64
+	Objects          uint64
+
+	HeapSys           string
+	HeapSysBytes      uint64
+	HeapInuse         string
+	HeapInuseBytes    uint64
+	HeapIdle          string
+	HeapIdleBytes     uint64
+	HeapReleased      string
+	HeapReleasedBytes uint64
+	StackInuse        string
+	StackInuseBytes   uint64
+	MSpanInuse        string
+	MSpanInuseBytes   uint64
+	Sys               string
+	SysBytes          uint64
+	TotalAlloc        string
+	TotalAllocBytes   uint64
+
+	// Completed GC cycles
+	GCCycles uint64
+
+	// All byte counts of the /memory/classes/ runtime metrics, keyed by their name without the prefix
+	Classes map[string]uint64
+}
+
+// Runtime metrics that the memory statistics are based on
+const (
+	memoryClassesPrefix = "/memory/classes/"
+	metricHeapObjects   = "/memory/classes/heap/objects:bytes"
+	metricHeapUnused    = "/memory/classes/heap/unused:bytes"
+	metricHeapFree      = "/memory/classes/heap/free:bytes"
+	metricHeapReleased  = "/memory/classes/heap/released:bytes"
+	metricStacks        = "/memory/classes/heap/stacks:bytes"
+	metricMSpanInuse    = "/memory/classes/metadata/mspan/inuse:bytes"
+	metricTotal         = "/memory/classes/total:bytes"
+	metricHeapGoal      = "/gc/heap/goal:bytes"
+	metricObjectCount   = "/gc/heap/objects:objects"
+	metricAllocs        = "/gc/heap/allocs:bytes"
+	metricGCCycles      = "/gc/cycles/total:gc-cycles"
+)
+
+// memorySamples lists the runtime metrics read for the memory statistics.
+var memorySamples = func() []metrics.Sample {
+	var samples []metrics.Sample
+
+	for _, description := range metrics.All() {
+		if description.Kind != metrics.KindUint64 {
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(description.Name, memoryClassesPrefix),
+			description.Name == metricHeapGoal,
+			description.Name == metricObjectCount,
+			description.Name == metricAllocs,
+			description.Name == metricGCCycles:
+			samples = append(samples, metrics.Sample{Name: description.Name})
+		}
+	}
+
+	return samples
+}()
+
+// readMemoryStats reads the memory statistics via runtime/metrics,
+// which unlike runtime.ReadMemStats doesn't stop the world.
+func readMemoryStats() AppMemoryStats {
+	samples := make([]metrics.Sample, len(memorySamples))
+	copy(samples, memorySamples)
+	metrics.Read(samples)
+
+	values := make(map[string]uint64, len(samples))
+	classes := make(map[string]uint64)
+
+	for _, sample := range samples {
+		if sample.Value.Kind() != metrics.KindUint64 {
+			continue
+		}
+
+		values[sample.Name] = sample.Value.Uint64()
+
+		if strings.HasPrefix(sample.Name, memoryClassesPrefix) {
+			classes[strings.TrimPrefix(sample.Name, memoryClassesPrefix)] = sample.Value.Uint64()
+		}
+	}
+
+	heapInuse := values[metricHeapObjects] + values[metricHeapUnused]
+	heapIdle := values[metricHeapFree] + values[metricHeapReleased]
+
+	return AppMemoryStats{
+		Allocated:         humanize.Bytes(values[metricHeapObjects]),
+		AllocatedBytes:    values[metricHeapObjects],
+		GCThreshold:       humanize.Bytes(values[metricHeapGoal]),
+		GCThresholdBytes:  values[metricHeapGoal],
+		Objects:           values[metricObjectCount],
+		HeapSys:           humanize.Bytes(heapInuse + heapIdle),
+		HeapSysBytes:      heapInuse + heapIdle,
+		HeapInuse:         humanize.Bytes(heapInuse),
+		HeapInuseBytes:    heapInuse,
+		HeapIdle:          humanize.Bytes(heapIdle),
+		HeapIdleBytes:     heapIdle,
+		HeapReleased:      humanize.Bytes(values[metricHeapReleased]),
+		HeapReleasedBytes: values[metricHeapReleased],
+		StackInuse:        humanize.Bytes(values[metricStacks]),
+		StackInuseBytes:   values[metricStacks],
+		MSpanInuse:        humanize.Bytes(values[metricMSpanInuse]),
+		MSpanInuseBytes:   values[metricMSpanInuse],
+		Sys:               humanize.Bytes(values[metricTotal]),
+		SysBytes:          values[metricTotal],
+		TotalAlloc:        humanize.Bytes(values[metricAllocs]),
+		TotalAllocBytes:   values[metricAllocs],
+		GCCycles:          values[metricGCCycles],
+		Classes:           classes,
+	}
+}
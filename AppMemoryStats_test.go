@@ -0,0 +1,31 @@
+package stats_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aerogo/stats"
+)
+
+// TestMemoryStatsGolden pins the JSON shape of the memory statistics,
+// which must not depend on the Go version that provides the runtime metrics.
+func TestMemoryStatsGolden(t *testing.T) {
+	collector := stats.NewCollector()
+	request := httptest.NewRequest(http.MethodGet, stats.DefaultPath+"?format=json&sections=app", nil)
+	response := httptest.NewRecorder()
+	collector.ServeHTTP(response, request)
+
+	var output struct {
+		App struct {
+			Memory map[string]interface{}
+		}
+	}
+
+	if err := json.Unmarshal(response.Body.Bytes(), &output); err != nil {
+		t.Fatal(err)
+	}
+
+	checkGolden(t, "memory.golden", fieldList("App.Memory", output.App.Memory))
+}
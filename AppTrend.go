@@ -0,0 +1,103 @@
+package stats
+
+import (
+	"html/template"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// appTrendSize is the number of samples of the memory and GC trends.
+const appTrendSize = 60
+
+// App trend metrics in addition to the route metrics
+const (
+	metricMemory  = "memory"
+	metricGCPause = "gc"
+)
+
+// appTrend keeps the last samples of the memory usage and the GC pauses taken by the sampler.
+type appTrend struct {
+	memory  [appTrendSize]uint64
+	gcPause [appTrendSize]time.Duration
+	next    int
+	count   int
+	numGC   int64
+	mutex   sync.Mutex
+}
+
+// sample adds the allocated heap and the longest GC pause since the previous sample.
+func (trend *appTrend) sample() {
+	memory := readMemoryStats().AllocatedBytes
+	var gc debug.GCStats
+	debug.ReadGCStats(&gc)
+
+	trend.mutex.Lock()
+	defer trend.mutex.Unlock()
+
+	// Pause lists the most recent pauses first
+	var longest time.Duration
+
+	for i := 0; i < int(gc.NumGC-trend.numGC) && i < len(gc.Pause); i++ {
+		if gc.Pause[i] > longest {
+			longest = gc.Pause[i]
+		}
+	}
+
+	trend.numGC = gc.NumGC
+	trend.memory[trend.next] = memory
+	trend.gcPause[trend.next] = longest
+	trend.next = (trend.next + 1) % appTrendSize
+
+	if trend.count < appTrendSize {
+		trend.count++
+	}
+}
+
+// values returns the samples of the metric in chronological order,
+// the memory in megabytes and the GC pauses in milliseconds.
+func (trend *appTrend) values(metric string) []float64 {
+	trend.mutex.Lock()
+	defer trend.mutex.Unlock()
+
+	values := make([]float64, trend.count)
+	first := (trend.next - trend.count + appTrendSize) % appTrendSize
+
+	for i := range values {
+		index := (first + i) % appTrendSize
+
+		if metric == metricMemory {
+			values[i] = float64(trend.memory[index]) / (1 << 20)
+		} else {
+			values[i] = float64(trend.gcPause[index]) / float64(time.Millisecond)
+		}
+	}
+
+	return values
+}
+
+// trend is the template function rendering a trend of the whole app:
+// "rps" and "latency" per minute over the last hour,
+// "memory" and "gc" per sample interval.
+func (stats *Collector) trend(metric string) template.HTML {
+	var values []float64
+
+	switch metric {
+	case metricRPS, metricLatency:
+		for _, bucket := range stats.minutes.Buckets(stats.clock.Now()) {
+			if metric == metricRPS {
+				values = append(values, float64(bucket.Requests)/time.Minute.Seconds())
+			} else {
+				values = append(values, bucket.ResponseTimeMs)
+			}
+		}
+
+	case metricMemory, metricGCPause:
+		values = stats.appTrend.values(metric)
+
+	default:
+		return ""
+	}
+
+	return template.HTML(Sparkline(values))
+}
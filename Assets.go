@@ -0,0 +1,100 @@
+package stats
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"html/template"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// assetHashLength is the number of hex digits of the content hash used in asset URLs.
+const assetHashLength = 12
+
+// asset is a static dashboard file.
+type asset struct {
+	content     []byte
+	contentType string
+}
+
+// assetServer serves the static dashboard files under content-hashed names,
+// so they can be cached forever.
+type assetServer struct {
+	files map[string]*asset
+	urls  map[string]string
+}
+
+// WithDashboard replaces the HTML dashboard with a template and its static assets.
+// The template can reference assets via the "asset" function, e.g. {{asset "style.css"}},
+// which must be defined (with any implementation) when the template is parsed.
+// Import the dashboard sub-package for the full-featured built-in dashboard.
+func WithDashboard(tmpl *template.Template, files fs.FS) Option {
+	return func(stats *Collector) {
+		stats.html = &htmlTemplate{
+			template: tmpl,
+		}
+
+		stats.assetFiles = files
+	}
+}
+
+// newAssetServer reads all files and computes their URLs under the given prefix.
+func newAssetServer(files fs.FS, prefix string) (*assetServer, error) {
+	server := &assetServer{
+		files: map[string]*asset{},
+		urls:  map[string]string{},
+	}
+
+	err := fs.WalkDir(files, ".", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+
+		content, err := fs.ReadFile(files, name)
+
+		if err != nil {
+			return err
+		}
+
+		hash := sha256.Sum256(content)
+		hashedName := hex.EncodeToString(hash[:])[:assetHashLength] + "-" + path.Base(name)
+		contentType := mime.TypeByExtension(path.Ext(name))
+
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+
+		server.files[hashedName] = &asset{
+			content:     content,
+			contentType: contentType,
+		}
+
+		server.urls[name] = prefix + hashedName
+		return nil
+	})
+
+	return server, err
+}
+
+// url returns the content-hashed URL of an asset.
+func (server *assetServer) url(name string) string {
+	return server.urls[name]
+}
+
+// ServeHTTP serves an asset. Assets never change under the same URL.
+func (server *assetServer) ServeHTTP(response http.ResponseWriter, request *http.Request) {
+	name := request.URL.Path[strings.LastIndex(request.URL.Path, "/")+1:]
+	file, exists := server.files[name]
+
+	if !exists {
+		http.NotFound(response, request)
+		return
+	}
+
+	response.Header().Set("Content-Type", file.contentType)
+	response.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	response.Write(file.content)
+}
@@ -0,0 +1,71 @@
+package stats
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// atomicMax raises the stored value to value if it is higher.
+func atomicMax(address *uint64, value uint64) {
+	for {
+		current := atomic.LoadUint64(address)
+
+		if value <= current || atomic.CompareAndSwapUint64(address, current, value) {
+			return
+		}
+	}
+}
+
+// atomicMin lowers the stored value to value if it is lower.
+// A stored value of 0 is treated as unset.
+func atomicMin(address *uint64, value uint64) {
+	for {
+		current := atomic.LoadUint64(address)
+
+		if (current != 0 && value >= current) || atomic.CompareAndSwapUint64(address, current, value) {
+			return
+		}
+	}
+}
+
+// timedMax is a maximum value together with the time it was observed.
+// The value and the time are updated separately,
+// a concurrent reader may see the time of a slightly smaller value.
+type timedMax struct {
+	value uint64
+	time  int64
+}
+
+// record raises the maximum if the value is higher.
+func (max *timedMax) record(now time.Time, value uint64) {
+	for {
+		current := atomic.LoadUint64(&max.value)
+
+		if value <= current {
+			return
+		}
+
+		if atomic.CompareAndSwapUint64(&max.value, current, value) {
+			atomic.StoreInt64(&max.time, now.UnixNano())
+			return
+		}
+	}
+}
+
+// load returns the maximum and the time it was observed.
+// The time is zero if no value has been recorded.
+func (max *timedMax) load() (uint64, time.Time) {
+	value := atomic.LoadUint64(&max.value)
+
+	if value == 0 {
+		return 0, time.Time{}
+	}
+
+	return value, time.Unix(0, atomic.LoadInt64(&max.time))
+}
+
+// reset clears the maximum and its time.
+func (max *timedMax) reset() {
+	atomic.StoreUint64(&max.value, 0)
+	atomic.StoreInt64(&max.time, 0)
+}
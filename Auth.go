@@ -0,0 +1,113 @@
+package stats
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// authRealm is the realm announced to clients that failed to authenticate.
+const authRealm = "Statistics"
+
+// WithBearerToken protects the whole endpoint tree with BearerToken.
+func WithBearerToken(token string) Option {
+	return WithEndpointMiddleware(BearerToken(token))
+}
+
+// WithBasicAuth protects the whole endpoint tree with BasicAuth.
+func WithBasicAuth(user string, password string) Option {
+	return WithEndpointMiddleware(BasicAuth(user, password))
+}
+
+// BearerToken returns a middleware that only passes requests with the header "Authorization: Bearer <token>".
+// An empty token rejects all requests.
+func BearerToken(token string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+			header := request.Header.Get("Authorization")
+			scheme, credentials, _ := strings.Cut(header, " ")
+
+			if token == "" || !strings.EqualFold(scheme, "Bearer") || !secretEqual(credentials, token) {
+				response.Header().Set("WWW-Authenticate", `Bearer realm="`+authRealm+`"`)
+				http.Error(response, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+
+			next.ServeHTTP(response, request)
+		})
+	}
+}
+
+// BasicAuth returns a middleware that only passes requests with the given HTTP basic auth credentials.
+func BasicAuth(user string, password string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+			requestUser, requestPassword, ok := request.BasicAuth()
+
+			// Both are compared so that the response time doesn't reveal which one was wrong
+			userOK := secretEqual(requestUser, user)
+			passwordOK := secretEqual(requestPassword, password)
+
+			if !ok || !userOK || !passwordOK {
+				response.Header().Set("WWW-Authenticate", `Basic realm="`+authRealm+`", charset="UTF-8"`)
+				http.Error(response, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+
+			next.ServeHTTP(response, request)
+		})
+	}
+}
+
+// AllowIPs returns a middleware that only passes requests from the given addresses or networks,
+// e.g. "10.0.0.0/8" or "::1". The client address is the remote address of the connection,
+// behind a reverse proxy it is the address of the proxy.
+func AllowIPs(networks ...string) (func(http.Handler) http.Handler, error) {
+	var allowed []*net.IPNet
+
+	for _, network := range networks {
+		if !strings.Contains(network, "/") {
+			ip := net.ParseIP(network)
+
+			if ip == nil {
+				return nil, errors.New("Invalid IP address: " + network)
+			}
+
+			allowed = append(allowed, &net.IPNet{IP: ip, Mask: net.CIDRMask(len(ip)*8, len(ip)*8)})
+			continue
+		}
+
+		_, ipNet, err := net.ParseCIDR(network)
+
+		if err != nil {
+			return nil, err
+		}
+
+		allowed = append(allowed, ipNet)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+			ip := net.ParseIP(clientAddress(request))
+
+			for _, ipNet := range allowed {
+				if ip != nil && ipNet.Contains(ip) {
+					next.ServeHTTP(response, request)
+					return
+				}
+			}
+
+			http.Error(response, "Forbidden", http.StatusForbidden)
+		})
+	}, nil
+}
+
+// secretEqual compares a credential in constant time, independent of the lengths of the values.
+func secretEqual(given string, expected string) bool {
+	givenHash := sha256.Sum256([]byte(given))
+	expectedHash := sha256.Sum256([]byte(expected))
+	return subtle.ConstantTimeCompare(givenHash[:], expectedHash[:]) == 1
+}
@@ -0,0 +1,100 @@
+package stats
+
+import (
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	humanize "github.com/dustin/go-humanize"
+)
+
+// bandwidth counts the bytes received and sent by the application.
+type bandwidth struct {
+	received uint64
+	sent     uint64
+
+	mutex        sync.Mutex
+	lastSample   time.Time
+	lastReceived uint64
+	lastSent     uint64
+	receiveRate  float64
+	sendRate     float64
+}
+
+// BandwidthStats contains the bytes transferred since the start
+// and the throughput in bytes per second during the last sample interval.
+type BandwidthStats struct {
+	Received      string
+	Sent          string
+	ReceivedBytes uint64
+	SentBytes     uint64
+	ReceiveRate   float64
+	SendRate      float64
+}
+
+// record adds the sizes of a request and its response.
+func (bandwidth *bandwidth) record(record *RequestRecord) {
+	atomic.AddUint64(&bandwidth.received, record.RequestSize)
+	atomic.AddUint64(&bandwidth.sent, record.ResponseSize)
+}
+
+// sample computes the throughput since the last sample.
+// Counters that went backwards (e.g. after a reset) are measured from zero.
+func (bandwidth *bandwidth) sample(now time.Time) {
+	received := atomic.LoadUint64(&bandwidth.received)
+	sent := atomic.LoadUint64(&bandwidth.sent)
+
+	bandwidth.mutex.Lock()
+	defer bandwidth.mutex.Unlock()
+
+	if !bandwidth.lastSample.IsZero() {
+		seconds := now.Sub(bandwidth.lastSample).Seconds()
+		bandwidth.receiveRate = float64(counterDelta(received, bandwidth.lastReceived)) / seconds
+		bandwidth.sendRate = float64(counterDelta(sent, bandwidth.lastSent)) / seconds
+	}
+
+	bandwidth.lastSample = now
+	bandwidth.lastReceived = received
+	bandwidth.lastSent = sent
+}
+
+// Stats returns the exported bandwidth statistics.
+func (bandwidth *bandwidth) Stats() BandwidthStats {
+	received := atomic.LoadUint64(&bandwidth.received)
+	sent := atomic.LoadUint64(&bandwidth.sent)
+
+	bandwidth.mutex.Lock()
+	defer bandwidth.mutex.Unlock()
+
+	return BandwidthStats{
+		Received:      humanize.Bytes(received),
+		Sent:          humanize.Bytes(sent),
+		ReceivedBytes: received,
+		SentBytes:     sent,
+		ReceiveRate:   bandwidth.receiveRate,
+		SendRate:      bandwidth.sendRate,
+	}
+}
+
+// counterDelta returns the increase of a counter, assuming a reset if it decreased.
+func counterDelta(current uint64, previous uint64) uint64 {
+	if current < previous {
+		return current
+	}
+
+	return current - previous
+}
+
+// countingReader counts the bytes read from a request body.
+type countingReader struct {
+	io.ReadCloser
+	count uint64
+}
+
+// Read counts the bytes read.
+func (reader *countingReader) Read(data []byte) (int, error) {
+	n, err := reader.ReadCloser.Read(data)
+	reader.count += uint64(n)
+	return n, err
+}
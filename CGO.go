@@ -0,0 +1,6 @@
+//go:build cgo
+
+package stats
+
+// cgoEnabled tells you whether the binary was built with cgo.
+const cgoEnabled = true
@@ -0,0 +1,68 @@
+package stats
+
+import (
+	"net/http"
+	"sync/atomic"
+)
+
+// CachingStats shows how effective conditional requests are.
+// ConditionalHitRate is the ratio of 304 responses to all 200 and 304 responses.
+// ETagChurn counts requests with If-None-Match that still received a full 200 response.
+type CachingStats struct {
+	NotModified        uint64
+	ConditionalHitRate float64
+	ETagChurn          uint64
+}
+
+// cachingCounters are the counters needed for the caching statistics.
+type cachingCounters struct {
+	ok          uint64
+	notModified uint64
+	etagChurn   uint64
+}
+
+// record counts the response status of a request.
+func (counters *cachingCounters) record(record *RequestRecord) {
+	switch record.StatusCode {
+	case http.StatusOK:
+		atomic.AddUint64(&counters.ok, 1)
+
+		if record.Conditional {
+			atomic.AddUint64(&counters.etagChurn, 1)
+		}
+
+	case http.StatusNotModified:
+		atomic.AddUint64(&counters.notModified, 1)
+	}
+}
+
+// add adds the values of other counters.
+func (counters *cachingCounters) add(other *cachingCounters) {
+	counters.ok += atomic.LoadUint64(&other.ok)
+	counters.notModified += atomic.LoadUint64(&other.notModified)
+	counters.etagChurn += atomic.LoadUint64(&other.etagChurn)
+}
+
+// Stats returns the exported caching statistics.
+func (counters *cachingCounters) Stats() CachingStats {
+	ok := atomic.LoadUint64(&counters.ok)
+	notModified := atomic.LoadUint64(&counters.notModified)
+
+	return CachingStats{
+		NotModified:        notModified,
+		ConditionalHitRate: hitRate(notModified, ok),
+		ETagChurn:          atomic.LoadUint64(&counters.etagChurn),
+	}
+}
+
+// cachingStats sums up the caching statistics of all routes.
+func (stats *Collector) cachingStats() *CachingStats {
+	total := cachingCounters{}
+
+	for _, route := range stats.trackedRoutes() {
+		total.add(&route.stats.caching)
+	}
+
+	caching := total.Stats()
+	return &caching
+}
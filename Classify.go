@@ -0,0 +1,109 @@
+package stats
+
+import (
+	"path"
+	"strings"
+	"sync/atomic"
+)
+
+// Built-in route classes
+const (
+	ClassAPI    = "api"
+	ClassStatic = "static"
+)
+
+// staticExtensions are the file extensions of routes that are classified as static by default.
+var staticExtensions = map[string]bool{
+	".css":   true,
+	".js":    true,
+	".map":   true,
+	".png":   true,
+	".jpg":   true,
+	".jpeg":  true,
+	".gif":   true,
+	".webp":  true,
+	".avif":  true,
+	".svg":   true,
+	".ico":   true,
+	".woff":  true,
+	".woff2": true,
+	".ttf":   true,
+	".otf":   true,
+	".mp4":   true,
+	".webm":  true,
+}
+
+// classifier assigns a class to the routes matching a prefix or pattern.
+type classifier struct {
+	pattern string
+	class   string
+}
+
+// ClassTotals contains the totals of all routes of a class.
+// Requests and TimeMs cover the summary window, Bytes always covers the whole lifetime.
+type ClassTotals struct {
+	Routes   int
+	Requests uint64
+	Bytes    uint64
+	TimeMs   float64
+}
+
+// Classify assigns the class to all routes that start with the prefix,
+// or match the pattern in path.Match syntax if it contains wildcards.
+// Later rules take precedence over earlier ones and all of them over the built-in
+// classification, which puts routes with common asset extensions into ClassStatic and the rest into ClassAPI.
+// Routes are classified once when they are created, existing routes are reclassified by this call.
+func (stats *Collector) Classify(pattern string, class string) {
+	stats.routesMutex.Lock()
+	defer stats.routesMutex.Unlock()
+
+	stats.classifiers = append(stats.classifiers, classifier{pattern: pattern, class: class})
+
+	for route, routeStats := range stats.routes {
+		routeStats.class.Store(stats.classify(route))
+	}
+}
+
+// classify returns the class of the route.
+// It must be called with the lock of the routes held.
+func (stats *Collector) classify(route string) string {
+	for i := len(stats.classifiers) - 1; i >= 0; i-- {
+		if stats.classifiers[i].matches(route) {
+			return stats.classifiers[i].class
+		}
+	}
+
+	if staticExtensions[strings.ToLower(path.Ext(route))] {
+		return ClassStatic
+	}
+
+	return ClassAPI
+}
+
+// matches tells you whether the route matches the prefix or pattern.
+func (classifier *classifier) matches(route string) bool {
+	if strings.ContainsAny(classifier.pattern, "*?[") {
+		matched, _ := path.Match(classifier.pattern, route)
+		return matched
+	}
+
+	return strings.HasPrefix(route, classifier.pattern)
+}
+
+// add adds a route to the totals, creating them if needed.
+func (totals *ClassTotals) add(route *Route, routeStats *RouteStatistics) *ClassTotals {
+	if totals == nil {
+		totals = &ClassTotals{}
+	}
+
+	totals.Routes++
+	totals.Requests += route.Requests
+	totals.Bytes += atomic.LoadUint64(&routeStats.responseSizes.total)
+	totals.TimeMs += route.TotalTimeMs
+	return totals
+}
+
+// Class returns the class the route was assigned by the classification rules.
+func (stats *RouteStatistics) Class() string {
+	return stats.class.Load().(string)
+}
@@ -0,0 +1,58 @@
+package stats
+
+import "time"
+
+// Clock is the source of the current time for everything time-based:
+// timestamps, history intervals, uptime and periodic work.
+// Response times are measured by the middleware with the real clock.
+type Clock interface {
+	Now() time.Time
+	Since(t time.Time) time.Duration
+	NewTicker(d time.Duration) Ticker
+}
+
+// Ticker delivers ticks at intervals like time.Ticker.
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// WithClock replaces the real clock, e.g. with testutil.FakeClock in tests.
+func WithClock(clock Clock) Option {
+	return func(stats *Collector) {
+		stats.clock = clock
+	}
+}
+
+// realClock is the system clock.
+type realClock struct{}
+
+// Now returns the current time.
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+// Since returns the time elapsed since t.
+func (realClock) Since(t time.Time) time.Duration {
+	return time.Since(t)
+}
+
+// NewTicker returns a ticker based on time.Ticker.
+func (realClock) NewTicker(d time.Duration) Ticker {
+	return realTicker{time.NewTicker(d)}
+}
+
+// realTicker wraps time.Ticker.
+type realTicker struct {
+	ticker *time.Ticker
+}
+
+// C returns the channel of the ticks.
+func (ticker realTicker) C() <-chan time.Time {
+	return ticker.ticker.C
+}
+
+// Stop turns off the ticker.
+func (ticker realTicker) Stop() {
+	ticker.ticker.Stop()
+}
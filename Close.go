@@ -0,0 +1,71 @@
+package stats
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// Close stops all background goroutines and performs a final flush
+// of every exporter within the deadline of the context.
+// Recording is disabled afterwards. Calling Close more than once is safe.
+//
+// Aero applications should call it from their shutdown hook:
+//
+//	app.OnShutdown(func() {
+//		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+//		defer cancel()
+//		statistics.Close(ctx)
+//	})
+func (stats *Collector) Close(ctx context.Context) error {
+	if !atomic.CompareAndSwapInt32(&stats.closed, 0, 1) {
+		return nil
+	}
+
+	close(stats.done)
+
+	stopped := make(chan struct{})
+
+	go func() {
+		stats.workers.Wait()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	var firstErr error
+
+	for _, flush := range stats.flushers {
+		err := flush(ctx)
+
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
+
+// isClosed tells you whether the statistics have been closed.
+func (stats *Collector) isClosed() bool {
+	return atomic.LoadInt32(&stats.closed) == 1
+}
+
+// goroutine starts a background worker that is waited for on Close.
+// The worker must return when the done channel is closed.
+func (stats *Collector) goroutine(worker func()) {
+	stats.workers.Add(1)
+
+	go func() {
+		defer stats.workers.Done()
+		worker()
+	}()
+}
+
+// onClose registers a function that performs a final flush on Close.
+func (stats *Collector) onClose(flush func(context.Context) error) {
+	stats.flushers = append(stats.flushers, flush)
+}
@@ -0,0 +1,62 @@
+package stats_test
+
+import (
+	"context"
+	"io"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/aerogo/stats"
+)
+
+func TestCloseStopsGoroutines(t *testing.T) {
+	before := runtime.NumGoroutine()
+	collector := stats.NewCollector(stats.WithRouteTTL(time.Hour))
+
+	if err := collector.PersistTo(filepath.Join(t.TempDir(), "stats.json"), time.Minute); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := collector.DailyReport("03:00", func(stats.Report) {}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := collector.EMF(io.Discard, "app", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+
+	collector.Track("/", time.Millisecond)
+
+	if err := collector.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if !waitFor(func() bool { return runtime.NumGoroutine() <= before }) {
+		t.Errorf("%d goroutines after Close, %d before NewCollector", runtime.NumGoroutine(), before)
+	}
+}
+
+func TestCloseTwice(t *testing.T) {
+	collector := stats.NewCollector()
+
+	if err := collector.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := collector.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTrackAfterClose(t *testing.T) {
+	collector := stats.NewCollector()
+	collector.Track("/", time.Millisecond)
+	collector.Close(context.Background())
+	collector.Track("/", time.Millisecond)
+
+	if requests := collector.RequestCount(); requests != 1 {
+		t.Errorf("%d requests, expected recording to stop on Close", requests)
+	}
+}
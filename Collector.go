@@ -0,0 +1,564 @@
+package stats
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Collector collects the statistics of an application.
+// It doesn't depend on any router, adapters feed it with Record and register its Endpoints.
+// All methods are safe for concurrent use, the routes are guarded by a read-write lock
+// and their counters are updated atomically or under a lock of each route.
+type Collector struct {
+	started        time.Time
+	appStart       time.Time
+	appConfig      interface{}
+	safeConfig     SafeConfig
+	routes         map[string]*RouteStatistics
+	routesMutex    sync.RWMutex
+	distribution   DistributionFactory
+	quantiles      []float64
+	history        *History
+	peakRate       PeakRate
+	routeHistory   bool
+	config         configMode
+	configRedactor ConfigRedactor
+	configFields   []string
+	apdexThreshold time.Duration
+	closed         int32
+	done           chan struct{}
+	workers        sync.WaitGroup
+	flushers       []func(context.Context) error
+
+	terminalDetection bool
+	html              *htmlTemplate
+	assetFiles        fs.FS
+	timeUnit          time.Duration
+	appGauges         []appGauge
+	sampleInterval    time.Duration
+	bandwidth         bandwidth
+	ratios            map[string]*Ratio
+	ratiosMutex       sync.RWMutex
+	errors            *errorLog
+	errorRedactor     func(*ErrorEvent)
+	slowLog           *slowLog
+	sampleHeaders     []string
+	path              string
+	epochs            snapshotEpochs
+	warmupUntil       int64
+	warmupCount       uint64
+	traceID           func(context.Context) string
+	traceIDExtractor  func(*http.Request) string
+	lastScrape        int64
+	sinks             atomic.Value
+	sinksMutex        sync.Mutex
+	clock             Clock
+	warmup            time.Duration
+	system            SystemProvider
+	onError           func(error)
+	systemErrors      map[string]string
+	systemErrorsMutex sync.Mutex
+	globalRateLimit   float64
+	clientRateLimit   float64
+	rateLimiter       *rateLimiter
+	snapshotTTL       time.Duration
+	snapshotCache     snapshotCache
+	summaryWindow     time.Duration
+	routeTTL          time.Duration
+	expiredAggregate  bool
+	slowThreshold     time.Duration
+	heatmap           heatmap
+	runtimeInfo       RuntimeInfo
+	cpuUsage          cpuUsage
+	maxGoroutines     uint64
+	maxThreads        uint64
+	classifiers       []classifier
+	ignoredMethods    map[string]bool
+	ignoredCount      uint64
+	abortedLatency    bool
+	visitors          *visitors
+	origins           *origins
+	routeKey          func(*http.Request, string) string
+	maxRoutes         int
+	disabledEndpoints map[string]bool
+	middlewares       []func(http.Handler) http.Handler
+	scope             Scope
+	slos              []*slo
+	connections       connectionCounters
+	memoryBudget      uint64
+	degradationLevel  uint32
+	footprint         uint64
+	blendedSlowRoutes bool
+	latencyBuckets    []time.Duration
+	minutes           *History
+	appTrend          appTrend
+	dashboardRefresh  time.Duration
+	liveInterval      time.Duration
+	metrics           metricRegistry
+	popularMin        uint64
+	listLimit         int
+	methodBreakdown   bool
+}
+
+// DefaultPath is the path of the statistics endpoint unless configured otherwise via WithPath.
+const DefaultPath = "/__/stats"
+
+// NewCollector creates a new collector.
+func NewCollector(options ...Option) *Collector {
+	stats := new(Collector)
+	stats.clock = realClock{}
+	stats.system = sigarProvider{}
+	stats.routes = make(map[string]*RouteStatistics)
+	stats.systemErrors = make(map[string]string)
+	stats.history = NewHistory(time.Hour, hourlyHistorySize)
+	stats.minutes = NewHistory(time.Minute, 60)
+	stats.ratios = make(map[string]*Ratio)
+	stats.errors = newErrorLog(defaultErrorLogSize)
+	stats.slowLog = newSlowLog(defaultSlowLogThreshold, defaultSlowLogSize, SlowLogRecent)
+	stats.done = make(chan struct{})
+	stats.quantiles = []float64{0.5, 0.9, 0.95, 0.99, 0.999}
+	stats.latencyBuckets = DefaultLatencyBuckets
+	stats.configRedactor = DefaultConfigRedactor
+	stats.terminalDetection = true
+	stats.html = &htmlTemplate{template: defaultHTMLTemplate}
+	stats.sampleInterval = defaultSampleInterval
+	stats.path = DefaultPath
+	stats.dashboardRefresh = defaultDashboardRefresh
+	stats.liveInterval = defaultLiveInterval
+	stats.slowThreshold = defaultSlowRouteThreshold
+	stats.popularMin = 1
+	stats.apdexThreshold = defaultApdexThreshold
+	stats.scope = ScopeFull
+	stats.heatmap.location = time.Local
+	stats.runtimeInfo = readRuntimeInfo()
+	stats.traceIDExtractor = DefaultTraceIDExtractor
+	stats.globalRateLimit = defaultGlobalRateLimit
+	stats.clientRateLimit = defaultClientRateLimit
+
+	for _, option := range options {
+		option(stats)
+	}
+
+	stats.started = stats.clock.Now()
+	stats.rateLimiter = newRateLimiter(stats.clock, stats.globalRateLimit, stats.clientRateLimit)
+
+	if stats.appStart.IsZero() {
+		stats.appStart = stats.started
+	}
+
+	stats.html.load(stats.templateFuncs())
+
+	if stats.warmup > 0 {
+		stats.warmupUntil = stats.started.Add(stats.warmup).UnixNano()
+	}
+
+	stats.startSampler()
+
+	if stats.routeTTL > 0 {
+		stats.startJanitor()
+	}
+
+	return stats
+}
+
+// Record adds a finished request to the statistics.
+func (stats *Collector) Record(record RequestRecord) {
+	if stats.isClosed() || stats.ignore(&record) {
+		return
+	}
+
+	if stats.methodBreakdown && record.Method != "" {
+		record.Route = record.Method + " " + record.Route
+	}
+
+	now := stats.clock.Now()
+	stats.history.record(now, &record)
+	stats.minutes.record(now, &record)
+	stats.peakRate.record(now)
+	stats.bandwidth.record(&record)
+	stats.slowLog.record(now, &record)
+	stats.heatmap.record(now, &record)
+	stats.recordSLOs(now, &record)
+
+	if stats.visitors != nil && record.Visitor != 0 {
+		stats.visitors.record(now, &record)
+	}
+
+	if stats.origins != nil && record.Origin != "" {
+		stats.origins.record(&record)
+	}
+
+	if record.failed() || record.Error != "" {
+		stats.errors.add(ErrorEvent{
+			Time:       now,
+			Route:      record.Route,
+			Method:     record.Method,
+			StatusCode: record.StatusCode,
+			DurationMs: float64(record.Duration) / float64(time.Millisecond),
+			Message:    errorMessage(record.Error),
+			TraceID:    record.TraceID,
+		})
+	}
+
+	warmup := stats.inWarmup(now)
+
+	if warmup {
+		atomic.AddUint64(&stats.warmupCount, 1)
+	}
+
+	route := stats.acquireRoute(record.Route)
+	route.record(now, &record, warmup, stats.abortedLatency)
+
+	if !warmup && (!record.Aborted || stats.abortedLatency) {
+		route.apdex.record(&record, stats.apdexThreshold)
+	}
+
+	atomic.AddInt32(&route.inFlight, -1)
+	stats.emit(&record)
+}
+
+// Track records a successful request of the route that took the given duration.
+// It is a shortcut for Record when there is no HTTP request, e.g. for jobs or custom routers.
+func (stats *Collector) Track(route string, duration time.Duration) {
+	stats.Record(RequestRecord{
+		Route:      route,
+		StatusCode: http.StatusOK,
+		Duration:   duration,
+	})
+}
+
+// route returns the statistics for the given route, creating them if needed.
+func (stats *Collector) route(path string) *RouteStatistics {
+	return stats.lookupRoute(path, false)
+}
+
+// acquireRoute returns the statistics for the given route like route,
+// but also marks a request as in flight so that the route isn't evicted until it is released.
+func (stats *Collector) acquireRoute(path string) *RouteStatistics {
+	return stats.lookupRoute(path, true)
+}
+
+// lookupRoute returns the statistics for the given route, creating them if needed.
+// The in-flight mark is set while holding the lock, so eviction never sees a route between lookup and mark.
+func (stats *Collector) lookupRoute(path string, acquire bool) *RouteStatistics {
+	stats.routesMutex.RLock()
+	route, exists := stats.routes[path]
+
+	if exists && acquire {
+		atomic.AddInt32(&route.inFlight, 1)
+	}
+
+	stats.routesMutex.RUnlock()
+
+	if exists {
+		return route
+	}
+
+	stats.routesMutex.Lock()
+	defer stats.routesMutex.Unlock()
+
+	route, exists = stats.routes[path]
+
+	if !exists && stats.routeLimitReached() {
+		path = OverflowRoute
+		route, exists = stats.routes[path]
+	}
+
+	if !exists {
+		route = stats.newRoute(path)
+		stats.routes[path] = route
+	}
+
+	if acquire {
+		atomic.AddInt32(&route.inFlight, 1)
+	}
+
+	return route
+}
+
+// newRoute creates empty route statistics.
+// It must be called with the write lock of the routes held.
+func (stats *Collector) newRoute(path string) *RouteStatistics {
+	route := &RouteStatistics{
+		minutes:  NewHistory(time.Minute, 60),
+		lastSeen: stats.clock.Now().UnixNano(),
+	}
+
+	route.class.Store(stats.classify(path))
+	route.series.bounds = stats.latencyBuckets
+
+	if stats.distribution != nil && stats.degradation() < DegradationHistograms {
+		route.distribution.Store(distributionValue{stats.distribution()})
+	}
+
+	if stats.degradation() >= DegradationSampling {
+		route.sampled = 1
+	}
+
+	if stats.routeHistory {
+		route.history = NewHistory(time.Hour, hourlyHistorySize)
+	}
+
+	return route
+}
+
+// percentiles returns the response time at each quantile in milliseconds, nil if no request has been measured.
+func (stats *Collector) percentiles(route *RouteStatistics, quantiles []float64) map[string]float64 {
+	if atomic.LoadUint64(&route.measuredCount) == 0 {
+		return nil
+	}
+
+	percentiles := make(map[string]float64, len(quantiles))
+
+	for _, q := range quantiles {
+		key := "p" + strconv.FormatFloat(q*100, 'f', -1, 64)
+		percentiles[key] = float64(route.Quantile(q)) / float64(time.Millisecond)
+	}
+
+	return percentiles
+}
+
+// Percentile returns the estimated response time of the route at percentile p, e.g. 95.
+// The boolean is false if the route doesn't exist or has no measured requests.
+func (stats *Collector) Percentile(route string, p float64) (time.Duration, bool) {
+	stats.routesMutex.RLock()
+	routeStats, exists := stats.routes[route]
+	stats.routesMutex.RUnlock()
+
+	if !exists || p < 0 || p > 100 {
+		return 0, false
+	}
+
+	return routeStats.quantile(p / 100)
+}
+
+// parseQuantiles parses a comma separated list of quantiles like "0.5,0.999".
+func parseQuantiles(list string) ([]float64, error) {
+	var quantiles []float64
+
+	for _, field := range strings.Split(list, ",") {
+		q, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+
+		if err != nil || q < 0 || q > 1 {
+			return nil, fmt.Errorf("Invalid quantile: %s", field)
+		}
+
+		quantiles = append(quantiles, q)
+	}
+
+	return quantiles, nil
+}
+
+// Endpoints returns the routes of the statistics endpoint under the configured path,
+// for adapters to register with their router. Route parameters use the ":name" syntax.
+// Sub-endpoints disabled via WithDisabledEndpoints are not included.
+// The error is non-nil if the HTML template file could not be parsed, the routes are usable nonetheless.
+func (stats *Collector) Endpoints() ([]Endpoint, error) {
+	path := stats.path
+
+	if path == "" {
+		return nil, nil
+	}
+
+	endpoints := []Endpoint{
+		// Route details
+		{http.MethodGet, path + "/route", stats.showRoute},
+
+		// Route summaries only
+		{http.MethodGet, path + "/routes", stats.showRoutes},
+
+		// System statistics only
+		{http.MethodGet, path + "/system", stats.showSystem},
+
+		// Hour-of-day heatmap
+		{http.MethodGet, path + "/heatmap", stats.showHeatmap},
+
+		// Self-refreshing HTML dashboard
+		{http.MethodGet, path + "/dashboard", stats.showDashboard},
+
+		// WebSocket stream of snapshots
+		{http.MethodGet, path + "/live", stats.showLive},
+
+		// Sparklines
+		{http.MethodGet, path + "/sparkline", stats.showSparkline},
+
+		// Recent errors
+		{http.MethodGet, path + "/errors", stats.showErrors},
+
+		// Slow requests
+		{http.MethodGet, path + "/slow", stats.showSlowRequests},
+		{http.MethodDelete, path + "/slow", stats.clearSlowRequests},
+
+		// Reset and pinned snapshots, only with an endpoint middleware
+		{http.MethodPost, path + "/reset", stats.requireMiddleware(stats.resetStatistics)},
+		{http.MethodPost, path + "/snapshot", stats.requireMiddleware(stats.pinSnapshot)},
+
+		// Prometheus metrics
+		{http.MethodGet, path + "/metrics", stats.showMetrics},
+
+		// Statistics route
+		{http.MethodGet, path, stats.showStatistics},
+	}
+
+	// Dashboard assets
+	if stats.assetFiles != nil && !stats.disabledEndpoints[EndpointAssets] {
+		assets, err := newAssetServer(stats.assetFiles, path+"/assets/")
+
+		if err != nil {
+			return stats.wrapEndpoints(endpoints), err
+		}
+
+		stats.html.setAssets(assets.url)
+		endpoints = append(endpoints, Endpoint{http.MethodGet, path + "/assets/:file", assets.ServeHTTP})
+	}
+
+	return stats.wrapEndpoints(endpoints), stats.html.loadError()
+}
+
+// ServeHTTP serves the statistics like the main route of the endpoint,
+// for adapters that mount a single handler.
+// The endpoint middlewares apply as well.
+func (stats *Collector) ServeHTTP(response http.ResponseWriter, request *http.Request) {
+	stats.guard(stats.showStatistics).ServeHTTP(response, request)
+}
+
+// wrapEndpoints removes the disabled endpoints and guards the handlers of the others.
+func (stats *Collector) wrapEndpoints(endpoints []Endpoint) []Endpoint {
+	enabled := endpoints[:0]
+
+	for _, endpoint := range endpoints {
+		name := stats.endpointName(endpoint.Path)
+
+		if stats.disabledEndpoints[name] {
+			continue
+		}
+
+		// Assets are loaded in bursts by the dashboard, so they are exempt from the rate limit
+		if name == EndpointAssets {
+			endpoint.Handler = stats.authorize(endpoint.Handler).ServeHTTP
+		} else {
+			endpoint.Handler = stats.guard(endpoint.Handler).ServeHTTP
+		}
+
+		enabled = append(enabled, endpoint)
+	}
+
+	return enabled
+}
+
+// showStatistics serves the statistics as JSON.
+// The "schema" query parameter selects the schema version (the previous version is still supported),
+// "sections" limits the output to the given sections and "quantiles" overrides the list of reported percentiles.
+// "window" selects the recent duration the route summaries cover, or "all" for the whole lifetime.
+// "class" limits the route summaries to the routes of a single class and "limit" the number of routes in each list.
+// "method" limits them to the routes whose requests all used the method, see WithMethodBreakdown.
+// JSON responses carry the epoch of the snapshot in the ETag header, "since" with a recent epoch
+// returns the changes since that snapshot instead.
+// With a snapshot TTL, recently rendered responses are served from the cache.
+func (stats *Collector) showStatistics(response http.ResponseWriter, request *http.Request) {
+	if stats.snapshotTTL > 0 {
+		stats.serveCached(response, request, stats.renderStatistics)
+		return
+	}
+
+	stats.renderStatistics(response, request)
+}
+
+// renderStatistics renders the statistics in the requested format.
+func (stats *Collector) renderStatistics(response http.ResponseWriter, request *http.Request) {
+	query := request.URL.Query()
+	parameters, err := stats.routeParameters(query)
+
+	if err != nil {
+		http.Error(response, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	sections := AllSections
+
+	if list := query.Get("sections"); list != "" {
+		sections, err = parseSections(list)
+
+		if err != nil {
+			http.Error(response, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	stats.renderSnapshot(response, request, stats.snapshot(parameters, newSectionSet(sections)))
+}
+
+// showSystem serves only the system and process sections in the requested format,
+// without touching the route statistics.
+func (stats *Collector) showSystem(response http.ResponseWriter, request *http.Request) {
+	sections := newSectionSet([]Section{SectionSystem, SectionProcess})
+	stats.renderSnapshot(response, request, stats.snapshot(summaryParameters{}, sections))
+}
+
+// renderSnapshot writes the snapshot in the requested format.
+func (stats *Collector) renderSnapshot(response http.ResponseWriter, request *http.Request, snapshot *Snapshot) {
+	query := request.URL.Query()
+	format := stats.responseFormat(request)
+
+	switch format {
+	case formatJSON:
+	case formatText, formatTerminal:
+		response.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		renderText(response, snapshot, format == formatTerminal)
+		return
+	case formatHTML:
+		response.Header().Set("Content-Type", "text/html; charset=utf-8")
+		stats.html.render(response, snapshot)
+		return
+	default:
+		http.Error(response, "Unsupported format: "+query.Get("format"), http.StatusBadRequest)
+		return
+	}
+
+	var output interface{} = snapshot
+	since := query.Get("since")
+
+	if since != "" {
+		before := stats.epochs.find(since)
+
+		if before == nil {
+			http.Error(response, "Unknown or expired snapshot: "+since, http.StatusBadRequest)
+			return
+		}
+
+		output = Diff(*before, *snapshot)
+	}
+
+	response.Header().Set("ETag", `"`+stats.epochs.add(snapshot)+`"`)
+
+	switch query.Get("schema") {
+	case "", strconv.Itoa(SchemaVersion):
+	case strconv.Itoa(SchemaVersion - 1):
+		if since == "" {
+			output = snapshot.v1()
+		}
+	default:
+		http.Error(response, "Unsupported schema version: "+query.Get("schema"), http.StatusBadRequest)
+		return
+	}
+
+	writeJSON(response, output)
+}
+
+// RequestCount calculates the total number of requests made to the application.
+func (stats *Collector) RequestCount() uint64 {
+	total := uint64(0)
+
+	for _, route := range stats.trackedRoutes() {
+		total += atomic.LoadUint64(&route.stats.requestCount)
+	}
+
+	return total
+}
@@ -0,0 +1,67 @@
+package stats_test
+
+import (
+	"go/build"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aerogo/stats"
+)
+
+func TestCoreWithoutAero(t *testing.T) {
+	pkg, err := build.ImportDir(".", 0)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range pkg.Imports {
+		if strings.HasPrefix(path, "github.com/aerogo/aero") {
+			t.Errorf("the core package imports %s", path)
+		}
+	}
+}
+
+func TestRecord(t *testing.T) {
+	collector := stats.NewCollector()
+	collector.Record(stats.RequestRecord{Route: "/", StatusCode: http.StatusOK, Duration: 10 * time.Millisecond})
+	collector.Record(stats.RequestRecord{Route: "/", StatusCode: http.StatusInternalServerError, Duration: 30 * time.Millisecond})
+	route := collector.Snapshot().Routes.Popular[0]
+
+	if route.Route != "/" || route.Requests != 2 || route.Errors != 1 {
+		t.Errorf("route %s with %d requests and %d errors", route.Route, route.Requests, route.Errors)
+	}
+
+	if route.ResponseTimeMs != 20 {
+		t.Errorf("average response time %v ms", route.ResponseTimeMs)
+	}
+}
+
+func TestRecorder(t *testing.T) {
+	collector := stats.NewCollector()
+	handler := collector.Recorder("/users/:id", http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		if request.URL.Path == "/users/0" {
+			http.NotFound(response, request)
+			return
+		}
+
+		response.Write([]byte("user"))
+	}))
+
+	for _, path := range []string{"/users/1", "/users/2", "/users/0"} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	routes := collector.Snapshot().Routes.Popular
+
+	if len(routes) != 1 || routes[0].Route != "/users/:id" {
+		t.Fatalf("expected one route for the pattern, routes: %v", collector.Routes())
+	}
+
+	if classes := routes[0].StatusClasses; classes["2xx"] != 2 || classes["4xx"] != 1 {
+		t.Errorf("status classes %v", classes)
+	}
+}
@@ -0,0 +1,135 @@
+package stats
+
+import (
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+// hourlyHistorySize is the number of hourly history buckets:
+// one day plus the current hour, so that the current hour can be compared to the same hour yesterday.
+const hourlyHistorySize = 25
+
+// minComparisonElapsed is the part of the current hour that must have passed before it is compared,
+// extrapolating the first seconds of an hour is too noisy.
+const minComparisonElapsed = time.Minute
+
+// comparedRoutes is the number of most popular routes that are compared.
+const comparedRoutes = 10
+
+// Comparison contains the percentage changes of the current hour
+// compared to the previous hour and to the same hour yesterday.
+// The requests of the current hour are extrapolated to a full hour.
+// Values are nil if there is not enough history to compare or the past value is zero.
+type Comparison struct {
+	RequestsVsPreviousHour     *float64
+	RequestsVsYesterday        *float64
+	ResponseTimeVsPreviousHour *float64
+	ResponseTimeVsYesterday    *float64
+}
+
+// ComparisonStats compares the current traffic of the app and its most popular routes to the past.
+// Routes are only compared when the hourly route history is enabled via WithRouteHistory.
+type ComparisonStats struct {
+	App    Comparison
+	Routes map[string]Comparison `json:",omitempty"`
+}
+
+// comparisonStats compares the current hour of the app and the most popular routes.
+func (stats *Collector) comparisonStats(now time.Time) *ComparisonStats {
+	comparison := &ComparisonStats{
+		App: stats.compare(stats.history, now),
+	}
+
+	if !stats.routeHistory {
+		return comparison
+	}
+
+	type popularRoute struct {
+		path         string
+		requestCount uint64
+		history      *History
+	}
+
+	var routes []popularRoute
+
+	for _, route := range stats.trackedRoutes() {
+		routes = append(routes, popularRoute{
+			path:         route.path,
+			requestCount: atomic.LoadUint64(&route.stats.requestCount),
+			history:      route.stats.history,
+		})
+	}
+
+	sort.Slice(routes, func(i, j int) bool {
+		return routes[i].requestCount > routes[j].requestCount
+	})
+
+	if len(routes) > comparedRoutes {
+		routes = routes[:comparedRoutes]
+	}
+
+	comparison.Routes = make(map[string]Comparison, len(routes))
+
+	for _, route := range routes {
+		comparison.Routes[route.path] = stats.compare(route.history, now)
+	}
+
+	return comparison
+}
+
+// compare compares the current hour of the history to the previous hour and to the same hour yesterday.
+func (stats *Collector) compare(history *History, now time.Time) Comparison {
+	comparison := Comparison{}
+	buckets := history.Buckets(now)
+	current := buckets[len(buckets)-1]
+	elapsed := now.Sub(current.Start)
+
+	if elapsed < minComparisonElapsed {
+		return comparison
+	}
+
+	requests := float64(current.Requests) * float64(time.Duration(history.interval)*time.Second) / float64(elapsed)
+
+	if len(buckets) >= 2 {
+		previous := buckets[len(buckets)-2]
+
+		if stats.covers(previous) {
+			comparison.RequestsVsPreviousHour = percentChange(requests, float64(previous.Requests))
+
+			if current.Requests > 0 {
+				comparison.ResponseTimeVsPreviousHour = percentChange(current.ResponseTimeMs, previous.ResponseTimeMs)
+			}
+		}
+	}
+
+	if len(buckets) >= hourlyHistorySize {
+		yesterday := buckets[len(buckets)-hourlyHistorySize]
+
+		if stats.covers(yesterday) {
+			comparison.RequestsVsYesterday = percentChange(requests, float64(yesterday.Requests))
+
+			if current.Requests > 0 {
+				comparison.ResponseTimeVsYesterday = percentChange(current.ResponseTimeMs, yesterday.ResponseTimeMs)
+			}
+		}
+	}
+
+	return comparison
+}
+
+// covers tells whether the statistics were collected during the whole interval of a bucket
+// and the bucket has requests to compare with.
+func (stats *Collector) covers(bucket HistoryBucket) bool {
+	return !bucket.Start.Before(stats.started) && bucket.Requests > 0
+}
+
+// percentChange returns the change from base to value in percent, nil if base is zero.
+func percentChange(value float64, base float64) *float64 {
+	if base == 0 {
+		return nil
+	}
+
+	change := (value - base) / base * 100
+	return &change
+}
@@ -0,0 +1,33 @@
+package stats_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/aerogo/stats"
+	"github.com/aerogo/stats/testutil"
+)
+
+func TestComparisonWithZeroResponseTime(t *testing.T) {
+	clock := testutil.NewFakeClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	collector := stats.NewCollector(stats.WithClock(clock), stats.WithStartTime(clock.Now()))
+	defer collector.Close(context.Background())
+
+	collector.Track("/", 0)
+	clock.Advance(time.Hour + 2*time.Minute)
+	collector.Track("/", time.Millisecond)
+
+	snapshot := collector.SnapshotSections(stats.SectionComparison)
+
+	if _, err := json.Marshal(snapshot); err != nil {
+		t.Fatal(err)
+	}
+
+	app := snapshot.Comparison.App
+
+	if app.RequestsVsPreviousHour == nil || app.ResponseTimeVsPreviousHour != nil {
+		t.Errorf("requests %v and response time %v compared to the previous hour, expected only the requests", app.RequestsVsPreviousHour, app.ResponseTimeVsPreviousHour)
+	}
+}
@@ -0,0 +1,145 @@
+package stats
+
+import "strings"
+
+// configMode determines how much of the application configuration is exposed.
+type configMode int
+
+const (
+	configHidden configMode = iota
+	configSafe
+	configFields
+	configFull
+)
+
+// SafeConfig is the subset of the application configuration that is safe to expose.
+type SafeConfig struct {
+	Domain string
+	Title  string
+}
+
+// WithAppConfig sets the application configuration that can be exposed via WithConfig or WithFullConfig.
+// Adapters set it from their framework's configuration.
+func WithAppConfig(full interface{}, safe SafeConfig) Option {
+	return func(stats *Collector) {
+		stats.appConfig = full
+		stats.safeConfig = safe
+	}
+}
+
+// WithConfig includes the safe subset of the application configuration in the App section.
+func WithConfig() Option {
+	return func(stats *Collector) {
+		if stats.config < configSafe {
+			stats.config = configSafe
+		}
+	}
+}
+
+// WithConfigFields includes only the listed fields of the application configuration in the App section.
+// Fields are the JSON keys of the configuration, nested fields are separated by dots, e.g. "ports.https".
+// Keys are matched case-insensitively and the redactor still masks secrets in the listed fields.
+func WithConfigFields(fields ...string) Option {
+	return func(stats *Collector) {
+		stats.configFields = append(stats.configFields, fields...)
+
+		if stats.config < configFields {
+			stats.config = configFields
+		}
+	}
+}
+
+// WithFullConfig includes the complete application configuration in the App section.
+// Be careful: the configuration usually contains secrets like API keys.
+func WithFullConfig() Option {
+	return func(stats *Collector) {
+		stats.config = configFull
+	}
+}
+
+// exposedConfig returns the part of the configuration that should be included in the snapshot.
+func (stats *Collector) exposedConfig() interface{} {
+	if stats.appConfig == nil {
+		return nil
+	}
+
+	switch stats.config {
+	case configSafe:
+		safe := stats.safeConfig
+		return redactConfig(&safe, stats.configRedactor)
+
+	case configFields:
+		return allowConfig(redactConfig(stats.appConfig, stats.configRedactor), stats.configFields)
+
+	case configFull:
+		return redactConfig(stats.appConfig, stats.configRedactor)
+
+	default:
+		return nil
+	}
+}
+
+// allowConfig returns the listed fields of the generic configuration, keeping their nesting.
+func allowConfig(config interface{}, fields []string) interface{} {
+	allowed := map[string]interface{}{}
+
+	for _, field := range fields {
+		value := config
+		var names []string
+
+		for _, key := range strings.Split(field, ".") {
+			object, ok := value.(map[string]interface{})
+
+			if !ok {
+				names = nil
+				break
+			}
+
+			name, child, found := lookupConfigKey(object, key)
+
+			if !found {
+				names = nil
+				break
+			}
+
+			names = append(names, name)
+			value = child
+		}
+
+		if names == nil {
+			continue
+		}
+
+		target := allowed
+
+		for _, name := range names[:len(names)-1] {
+			child, ok := target[name].(map[string]interface{})
+
+			if !ok {
+				child = map[string]interface{}{}
+				target[name] = child
+			}
+
+			target = child
+		}
+
+		target[names[len(names)-1]] = value
+	}
+
+	return allowed
+}
+
+// lookupConfigKey finds a key in a configuration object, ignoring case.
+func lookupConfigKey(object map[string]interface{}, key string) (string, interface{}, bool) {
+	if value, exists := object[key]; exists {
+		return key, value, true
+	}
+
+	for name, value := range object {
+		if strings.EqualFold(name, key) {
+			return name, value, true
+		}
+	}
+
+	return "", nil, false
+}
@@ -0,0 +1,91 @@
+package stats
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// ConfigRedactor is called for every key in the configuration before it is exposed.
+// If it returns true, the value is replaced by the returned value.
+type ConfigRedactor func(key string, value interface{}) (interface{}, bool)
+
+// redacted is the placeholder for masked configuration values.
+const redacted = "***"
+
+// secretKeyPatterns are the key fragments that are masked by the default redactor.
+var secretKeyPatterns = []string{
+	"password",
+	"secret",
+	"token",
+	"key",
+	"dsn",
+}
+
+// DefaultConfigRedactor masks values whose key looks like it contains a secret.
+func DefaultConfigRedactor(key string, value interface{}) (interface{}, bool) {
+	key = strings.ToLower(key)
+
+	for _, pattern := range secretKeyPatterns {
+		if strings.Contains(key, pattern) {
+			return redacted, true
+		}
+	}
+
+	return value, false
+}
+
+// WithConfigRedactor sets the function used to mask configuration values.
+// It replaces the default redactor, nil restores it.
+func WithConfigRedactor(redactor ConfigRedactor) Option {
+	return func(stats *Collector) {
+		if redactor == nil {
+			redactor = DefaultConfigRedactor
+		}
+
+		stats.configRedactor = redactor
+	}
+}
+
+// redactConfig returns a copy of the configuration with all redacted values replaced.
+func redactConfig(config interface{}, redactor ConfigRedactor) interface{} {
+	data, err := json.Marshal(config)
+
+	if err != nil {
+		return nil
+	}
+
+	var generic interface{}
+
+	if json.Unmarshal(data, &generic) != nil {
+		return nil
+	}
+
+	return redactValue("", generic, redactor)
+}
+
+// redactValue recursively applies the redactor to maps and slices.
+func redactValue(key string, value interface{}, redactor ConfigRedactor) interface{} {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for childKey, child := range value {
+			if replacement, ok := redactor(childKey, child); ok {
+				value[childKey] = replacement
+				continue
+			}
+
+			value[childKey] = redactValue(childKey, child, redactor)
+		}
+
+		return value
+
+	case []interface{}:
+		for i, child := range value {
+			value[i] = redactValue(key, child, redactor)
+		}
+
+		return value
+
+	default:
+		return value
+	}
+}
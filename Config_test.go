@@ -0,0 +1,111 @@
+package stats_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aerogo/stats"
+)
+
+// appConfig is an application configuration with secrets on several levels.
+type appConfig struct {
+	Title    string
+	APIKeys  map[string]string
+	Database struct {
+		Host     string
+		Password string
+	}
+	Mirrors []struct {
+		URL   string
+		Token string
+	}
+}
+
+// newAppConfig creates a configuration with a secret in every place the redactor has to find.
+func newAppConfig() *appConfig {
+	config := &appConfig{Title: "Blog", APIKeys: map[string]string{"maps": "abc"}}
+	config.Database.Host = "db"
+	config.Database.Password = "hunter2"
+	config.Mirrors = append(config.Mirrors, struct {
+		URL   string
+		Token string
+	}{"https://mirror", "xyz"})
+	return config
+}
+
+// redactedConfig is the full configuration with the default redactor.
+const redactedConfig = `{"APIKeys":"***","Database":{"Host":"db","Password":"***"},"Mirrors":[{"Token":"***","URL":"https://mirror"}],"Title":"Blog"}`
+
+// exposedConfig returns the configuration included in the App section as JSON.
+func exposedConfig(t *testing.T, collector *stats.Collector) string {
+	t.Helper()
+	data, err := json.Marshal(collector.SnapshotSections(stats.SectionApp).App.Config)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(data)
+}
+
+func TestConfigHiddenByDefault(t *testing.T) {
+	collector := stats.NewCollector(stats.WithAppConfig(newAppConfig(), stats.SafeConfig{Title: "Blog"}))
+
+	if config := collector.SnapshotSections(stats.SectionApp).App.Config; config != nil {
+		t.Errorf("config exposed by default: %v", config)
+	}
+}
+
+func TestConfigKeyAbsentByDefault(t *testing.T) {
+	collector := stats.NewCollector(stats.WithAppConfig(newAppConfig(), stats.SafeConfig{Title: "Blog"}))
+	request := httptest.NewRequest(http.MethodGet, stats.DefaultPath+"?format=json&sections=app", nil)
+	response := httptest.NewRecorder()
+	collector.ServeHTTP(response, request)
+
+	if strings.Contains(response.Body.String(), `"Config"`) || strings.Contains(response.Body.String(), "hunter2") {
+		t.Errorf("config key in the default output:\n%s", response.Body.String())
+	}
+}
+
+func TestConfigAbsent(t *testing.T) {
+	collector := stats.NewCollector(stats.WithFullConfig())
+
+	if config := collector.SnapshotSections(stats.SectionApp).App.Config; config != nil {
+		t.Errorf("config without an app config: %v", config)
+	}
+}
+
+func TestSafeConfig(t *testing.T) {
+	collector := stats.NewCollector(stats.WithAppConfig(newAppConfig(), stats.SafeConfig{Domain: "example.com", Title: "Blog"}), stats.WithConfig())
+
+	if config := exposedConfig(t, collector); config != `{"Domain":"example.com","Title":"Blog"}` {
+		t.Errorf("safe config %s", config)
+	}
+}
+
+func TestNestedConfigRedaction(t *testing.T) {
+	collector := stats.NewCollector(stats.WithAppConfig(newAppConfig(), stats.SafeConfig{}), stats.WithFullConfig())
+
+	if config := exposedConfig(t, collector); config != redactedConfig {
+		t.Errorf("redacted config\n%s\nexpected\n%s", config, redactedConfig)
+	}
+}
+
+func TestNilConfigRedactor(t *testing.T) {
+	collector := stats.NewCollector(stats.WithAppConfig(newAppConfig(), stats.SafeConfig{}), stats.WithFullConfig(), stats.WithConfigRedactor(nil))
+
+	if config := exposedConfig(t, collector); config != redactedConfig {
+		t.Errorf("config with a nil redactor\n%s\nexpected\n%s", config, redactedConfig)
+	}
+}
+
+func TestConfigFields(t *testing.T) {
+	collector := stats.NewCollector(stats.WithAppConfig(newAppConfig(), stats.SafeConfig{}), stats.WithConfigFields("title", "database.host", "apikeys"))
+
+	if config := exposedConfig(t, collector); config != `{"APIKeys":"***","Database":{"Host":"db"},"Title":"Blog"}` {
+		t.Errorf("allowed config %s", config)
+	}
+}
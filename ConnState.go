@@ -0,0 +1,113 @@
+package stats
+
+import (
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+)
+
+// connectionCounters track the connections reported by the ConnState hook.
+type connectionCounters struct {
+	installed uint32
+	total     uint64
+	open      int64
+	idle      int64
+	unused    uint64
+	states    sync.Map
+}
+
+// connectionState is the last state of a tracked connection and whether it has issued a request.
+type connectionState struct {
+	state     uint32
+	requested uint32
+}
+
+// ConnectionStats contains the connections accepted by the server.
+// Unused connections were closed without ever issuing a request, which often points to a misbehaving proxy.
+type ConnectionStats struct {
+	Total  uint64
+	Open   int64
+	Idle   int64
+	Unused uint64
+}
+
+// ConnStateHook returns a function for http.Server.ConnState that counts the connections of the server.
+// The user's own ConnState function can be passed as next, it is called after counting. nil is allowed.
+//
+//	server.ConnState = collector.ConnStateHook(server.ConnState)
+func (stats *Collector) ConnStateHook(next func(net.Conn, http.ConnState)) func(net.Conn, http.ConnState) {
+	atomic.StoreUint32(&stats.connections.installed, 1)
+
+	return func(conn net.Conn, state http.ConnState) {
+		stats.connections.transition(conn, state)
+
+		if next != nil {
+			next(conn, state)
+		}
+	}
+}
+
+// transition updates the counters for the new state of the connection.
+// Closing idle connections on shutdown can report a state concurrently to the connection itself.
+func (counters *connectionCounters) transition(conn net.Conn, state http.ConnState) {
+	switch state {
+	case http.StateNew:
+		atomic.AddUint64(&counters.total, 1)
+		atomic.AddInt64(&counters.open, 1)
+		counters.states.Store(conn, &connectionState{state: uint32(state)})
+
+	case http.StateActive, http.StateIdle:
+		value, exists := counters.states.Load(conn)
+
+		if !exists {
+			return
+		}
+
+		current := value.(*connectionState)
+		previous := http.ConnState(atomic.SwapUint32(&current.state, uint32(state)))
+		counters.leave(previous)
+
+		if state == http.StateIdle {
+			atomic.AddInt64(&counters.idle, 1)
+		} else {
+			atomic.StoreUint32(&current.requested, 1)
+		}
+
+	case http.StateHijacked, http.StateClosed:
+		value, exists := counters.states.LoadAndDelete(conn)
+
+		if !exists {
+			return
+		}
+
+		current := value.(*connectionState)
+		counters.leave(http.ConnState(atomic.LoadUint32(&current.state)))
+		atomic.AddInt64(&counters.open, -1)
+
+		if state == http.StateClosed && atomic.LoadUint32(&current.requested) == 0 {
+			atomic.AddUint64(&counters.unused, 1)
+		}
+	}
+}
+
+// leave updates the gauges when a connection leaves the previous state.
+func (counters *connectionCounters) leave(previous http.ConnState) {
+	if previous == http.StateIdle {
+		atomic.AddInt64(&counters.idle, -1)
+	}
+}
+
+// Stats returns the connection statistics, nil if the hook is not installed.
+func (counters *connectionCounters) Stats() *ConnectionStats {
+	if atomic.LoadUint32(&counters.installed) == 0 {
+		return nil
+	}
+
+	return &ConnectionStats{
+		Total:  atomic.LoadUint64(&counters.total),
+		Open:   atomic.LoadInt64(&counters.open),
+		Idle:   atomic.LoadInt64(&counters.idle),
+		Unused: atomic.LoadUint64(&counters.unused),
+	}
+}
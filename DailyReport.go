@@ -0,0 +1,223 @@
+package stats
+
+import (
+	"errors"
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+// reportTopRoutes is the number of routes listed in each report ranking.
+const reportTopRoutes = 10
+
+// Report is a summary of the last day.
+type Report struct {
+	Start    time.Time
+	End      time.Time
+	Requests uint64
+	Errors   uint64
+	PeakRPS  uint64
+	Popular  []*Route
+	Slow     []*Route
+	Previous *Report `json:",omitempty"`
+}
+
+// routeTotals are the cumulative counters of a route at the time of a report.
+type routeTotals struct {
+	requestCount  uint64
+	measuredCount uint64
+	errorCount    uint64
+	responseTime  uint64
+	requestBytes  uint64
+	responseBytes uint64
+	minimum       time.Duration
+	maximum       time.Duration
+	latencyCounts []uint64
+	protocol      string
+}
+
+// DailyReport calls fn with a summary of the last day every day at the given local time ("HH:MM").
+// Reports are computed from a snapshot of the counters before fn is called,
+// so a slow callback doesn't affect the statistics.
+// If the process was suspended at the scheduled time, the report is generated once after it resumes.
+func (stats *Collector) DailyReport(at string, fn func(Report)) error {
+	clock, err := time.Parse("15:04", at)
+
+	if err != nil {
+		return errors.New("Invalid report time, expected HH:MM: " + at)
+	}
+
+	// The first report covers the requests from now on, no matter when the goroutine starts
+	totals := stats.routeTotals()
+	start := stats.clock.Now()
+
+	stats.goroutine(func() {
+		ticker := stats.clock.NewTicker(time.Minute)
+		defer ticker.Stop()
+
+		var previous *Report
+		next := nextOccurrence(start, clock)
+
+		for {
+			var now time.Time
+
+			select {
+			case <-stats.done:
+				return
+			case now = <-ticker.C():
+			}
+
+			if now.Before(next) {
+				continue
+			}
+
+			current := stats.routeTotals()
+			report := stats.report(start, now, totals, current)
+			report.Previous = previous
+			totals = current
+			start = now
+			next = nextOccurrence(now, clock)
+
+			// Only keep one day of history
+			last := report
+			last.Previous = nil
+			previous = &last
+
+			fn(report)
+		}
+	})
+
+	return nil
+}
+
+// report computes the report for the given period.
+func (stats *Collector) report(start time.Time, end time.Time, previous map[string]routeTotals, current map[string]routeTotals) Report {
+	report := Report{
+		Start:   start,
+		End:     end,
+		PeakRPS: stats.peakRate.Take(),
+	}
+
+	var routes []*Route
+
+	for path, totals := range current {
+		delta := totals.since(previous[path])
+		report.Requests += delta.requestCount
+		report.Errors += delta.errorCount
+
+		if delta.requestCount == 0 {
+			continue
+		}
+
+		route := &Route{
+			Route:    path,
+			Requests: delta.requestCount,
+		}
+
+		if delta.measuredCount > 0 {
+			route.setResponseTimes(time.Duration(delta.responseTime/delta.measuredCount), 0, 0)
+		}
+
+		// The p95 of the period is estimated from the histogram buckets counted since the previous report,
+		// the lifetime minimum and maximum of the route also bound the ones of the period
+		if p95, ok := bucketQuantile(stats.latencyBuckets, delta.latencyCounts, 0.95, delta.minimum, delta.maximum); ok {
+			route.Percentiles = map[string]float64{"p95": float64(p95) / float64(time.Millisecond)}
+		}
+
+		routes = append(routes, route)
+	}
+
+	report.Popular = topRoutes(routes, func(a *Route, b *Route) bool {
+		return a.Requests > b.Requests
+	})
+
+	report.Slow = topRoutes(routes, func(a *Route, b *Route) bool {
+		if a.Percentiles != nil && b.Percentiles != nil {
+			return a.Percentiles["p95"] > b.Percentiles["p95"]
+		}
+
+		return a.ResponseTimeNs > b.ResponseTimeNs
+	})
+
+	return report
+}
+
+// since returns the increase of the counters since the previous totals of the same route.
+// A route whose requests decreased was reset or evicted in between, its counters start from zero.
+// The protocol, minimum and maximum are the current ones.
+func (totals routeTotals) since(previous routeTotals) routeTotals {
+	if totals.requestCount < previous.requestCount {
+		previous = routeTotals{}
+	}
+
+	delta := totals
+	delta.requestCount = counterDelta(totals.requestCount, previous.requestCount)
+	delta.measuredCount = counterDelta(totals.measuredCount, previous.measuredCount)
+	delta.errorCount = counterDelta(totals.errorCount, previous.errorCount)
+	delta.responseTime = counterDelta(totals.responseTime, previous.responseTime)
+	delta.requestBytes = counterDelta(totals.requestBytes, previous.requestBytes)
+	delta.responseBytes = counterDelta(totals.responseBytes, previous.responseBytes)
+	delta.latencyCounts = make([]uint64, len(totals.latencyCounts))
+
+	for i, count := range totals.latencyCounts {
+		delta.latencyCounts[i] = count
+
+		if i < len(previous.latencyCounts) {
+			delta.latencyCounts[i] = counterDelta(count, previous.latencyCounts[i])
+		}
+	}
+
+	return delta
+}
+
+// routeTotals returns a copy of the cumulative counters of all routes.
+func (stats *Collector) routeTotals() map[string]routeTotals {
+	routes := stats.trackedRoutes()
+	totals := make(map[string]routeTotals, len(routes))
+
+	for _, route := range routes {
+		routeStats := route.stats
+		maximum, _ := routeStats.maxResponseTime.load()
+		totals[route.path] = routeTotals{
+			requestCount:  atomic.LoadUint64(&routeStats.requestCount),
+			measuredCount: atomic.LoadUint64(&routeStats.measuredCount),
+			errorCount:    atomic.LoadUint64(&routeStats.errorCount),
+			protocol:      routeStats.Protocol(),
+			responseTime:  atomic.LoadUint64(&routeStats.responseTime),
+			requestBytes:  atomic.LoadUint64(&routeStats.requestBytes),
+			responseBytes: atomic.LoadUint64(&routeStats.responseSizes.total),
+			minimum:       time.Duration(atomic.LoadUint64(&routeStats.minResponseTime)),
+			maximum:       time.Duration(maximum),
+			latencyCounts: routeStats.series.bucketCounts(),
+		}
+	}
+
+	return totals
+}
+
+// topRoutes returns the first routes according to the given order.
+func topRoutes(routes []*Route, less func(a *Route, b *Route) bool) []*Route {
+	sorted := make([]*Route, len(routes))
+	copy(sorted, routes)
+
+	sort.Slice(sorted, func(i, j int) bool {
+		return less(sorted[i], sorted[j])
+	})
+
+	if len(sorted) > reportTopRoutes {
+		sorted = sorted[:reportTopRoutes]
+	}
+
+	return sorted
+}
+
+// nextOccurrence returns the next time after now with the hour and minute of clock.
+func nextOccurrence(now time.Time, clock time.Time) time.Time {
+	next := time.Date(now.Year(), now.Month(), now.Day(), clock.Hour(), clock.Minute(), 0, 0, time.Local)
+
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+
+	return next
+}
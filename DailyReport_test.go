@@ -0,0 +1,107 @@
+package stats_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aerogo/stats"
+	"github.com/aerogo/stats/testutil"
+)
+
+// nextReport advances the clock in steps of ten minutes until the next report is delivered.
+func nextReport(t *testing.T, clock *testutil.FakeClock, reports <-chan stats.Report) stats.Report {
+	t.Helper()
+
+	for step := 0; step < 2*24*6; step++ {
+		clock.Advance(10 * time.Minute)
+
+		select {
+		case report := <-reports:
+			return report
+		case <-time.After(time.Millisecond):
+		}
+	}
+
+	t.Fatal("no report after two days")
+	return stats.Report{}
+}
+
+func TestDailyReport(t *testing.T) {
+	clock := testutil.NewFakeClock(time.Date(2020, 1, 1, 2, 0, 0, 0, time.Local))
+	collector := stats.NewCollector(stats.WithClock(clock))
+	defer collector.Close(context.Background())
+
+	reports := make(chan stats.Report, 1)
+
+	if err := collector.DailyReport("01:00", func(report stats.Report) { reports <- report }); err != nil {
+		t.Fatal(err)
+	}
+
+	// First day: /a is slow, /b fails once
+	for i := 0; i < 3; i++ {
+		collector.Track("/a", 2*time.Second)
+	}
+
+	collector.Record(stats.RequestRecord{Route: "/b", StatusCode: http.StatusInternalServerError, Duration: time.Millisecond})
+	first := nextReport(t, clock, reports)
+
+	if first.Requests != 4 || first.Errors != 1 {
+		t.Errorf("first report: %d requests and %d errors, expected 4 and 1", first.Requests, first.Errors)
+	}
+
+	// Second day: /a is fast, /b is slower than /a but without errors
+	clock.Advance(time.Hour)
+
+	for i := 0; i < 10; i++ {
+		collector.Track("/a", time.Millisecond)
+		collector.Track("/b", 100*time.Millisecond)
+	}
+
+	second := nextReport(t, clock, reports)
+
+	if second.Requests != 20 || second.Errors != 0 {
+		t.Errorf("second report: %d requests and %d errors, expected 20 and 0", second.Requests, second.Errors)
+	}
+
+	if len(second.Slow) != 2 || second.Slow[0].Route != "/b" {
+		t.Fatalf("second report ranks the slow routes by the p95 of the day: %+v", second.Slow)
+	}
+
+	if p95 := second.Slow[1].Percentiles["p95"]; p95 > 5 {
+		t.Errorf("p95 of /a on the second day is %vms", p95)
+	}
+
+	if second.Previous == nil || second.Previous.Requests != first.Requests {
+		t.Errorf("second report doesn't include the first one")
+	}
+}
+
+func TestDailyReportAfterReset(t *testing.T) {
+	clock := testutil.NewFakeClock(time.Date(2020, 1, 1, 2, 0, 0, 0, time.Local))
+	collector := stats.NewCollector(stats.WithClock(clock))
+	defer collector.Close(context.Background())
+
+	reports := make(chan stats.Report, 1)
+
+	if err := collector.DailyReport("01:00", func(report stats.Report) { reports <- report }); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 3; i++ {
+		collector.Record(stats.RequestRecord{Route: "/a", StatusCode: http.StatusInternalServerError, Duration: time.Millisecond})
+	}
+
+	nextReport(t, clock, reports)
+	clock.Advance(time.Hour)
+
+	// The counters of the first report are larger than the ones after the reset
+	collector.Reset()
+	collector.Track("/a", time.Millisecond)
+	report := nextReport(t, clock, reports)
+
+	if report.Requests != 1 || report.Errors != 0 {
+		t.Errorf("report after reset: %d requests and %d errors, expected 1 and 0", report.Requests, report.Errors)
+	}
+}
@@ -0,0 +1,41 @@
+package stats
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// defaultDashboardRefresh is the interval at which the dashboard page reloads itself.
+const defaultDashboardRefresh = 10 * time.Second
+
+// WithDashboardRefresh sets the interval at which the dashboard page reloads itself, 0 disables the reload.
+func WithDashboardRefresh(interval time.Duration) Option {
+	return func(stats *Collector) {
+		stats.dashboardRefresh = interval
+	}
+}
+
+// showDashboard serves the HTML dashboard regardless of the requested format.
+// The Refresh header makes browsers reload the page without any script.
+func (stats *Collector) showDashboard(response http.ResponseWriter, request *http.Request) {
+	parameters, err := stats.routeParameters(request.URL.Query())
+
+	if err != nil {
+		http.Error(response, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if stats.dashboardRefresh > 0 {
+		seconds := int(stats.dashboardRefresh.Round(time.Second) / time.Second)
+
+		if seconds < 1 {
+			seconds = 1
+		}
+
+		response.Header().Set("Refresh", strconv.Itoa(seconds))
+	}
+
+	response.Header().Set("Content-Type", "text/html; charset=utf-8")
+	stats.html.render(response, stats.snapshot(parameters, newSectionSet(AllSections)))
+}
@@ -0,0 +1,136 @@
+package stats
+
+import (
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+// SnapshotDiff contains the changes between two snapshots, e.g. before and after a load test.
+// Reset is true if a counter decreased in between, in which case the affected deltas are zero.
+type SnapshotDiff struct {
+	Elapsed  time.Duration
+	Requests uint64
+	Routes   []*RouteDiff
+	Reset    bool
+}
+
+// RouteDiff contains the changes of a single route between two snapshots.
+// ResponseTimeMs is the average response time of the requests made in between.
+type RouteDiff struct {
+	Route          string
+	Requests       uint64
+	Errors         uint64
+	ResponseTimeMs float64
+	New            bool
+	Reset          bool
+}
+
+// routeCounters are the cumulative counters of a route that a diff is computed from.
+type routeCounters struct {
+	requests     uint64
+	errors       uint64
+	measured     uint64
+	responseTime uint64
+}
+
+// routeCounters returns the counters of every tracked route,
+// independent of the limits, windows and filters of the rendered route lists.
+func (stats *Collector) routeCounters() map[string]routeCounters {
+	routes := stats.trackedRoutes()
+	counters := make(map[string]routeCounters, len(routes))
+
+	for _, route := range routes {
+		counters[route.path] = routeCounters{
+			requests:     atomic.LoadUint64(&route.stats.requestCount),
+			errors:       atomic.LoadUint64(&route.stats.errorCount),
+			measured:     atomic.LoadUint64(&route.stats.measuredCount),
+			responseTime: atomic.LoadUint64(&route.stats.responseTime),
+		}
+	}
+
+	return counters
+}
+
+// counters returns the route counters of a snapshot taken by a collector,
+// for other snapshots they are derived from the Popular list.
+func (snapshot *Snapshot) counters() map[string]routeCounters {
+	if snapshot.routeCounters != nil {
+		return snapshot.routeCounters
+	}
+
+	counters := make(map[string]routeCounters, len(snapshot.Routes.Popular))
+
+	for _, route := range snapshot.Routes.Popular {
+		counters[route.Route] = routeCounters{
+			requests:     route.Requests,
+			errors:       route.Errors,
+			measured:     route.Requests,
+			responseTime: uint64(route.TotalTimeMs * float64(time.Millisecond)),
+		}
+	}
+
+	return counters
+}
+
+// Diff computes the changes from the before to the after snapshot.
+// Both snapshots need the routes section for the request deltas, or else the app section for the total.
+// Routes that are missing in the after snapshot, e.g. because they expired, are left out.
+func Diff(before Snapshot, after Snapshot) SnapshotDiff {
+	diff := SnapshotDiff{
+		Elapsed: after.Generated.Sub(before.Generated),
+	}
+
+	if before.Routes == nil || after.Routes == nil {
+		if before.App != nil && after.App != nil {
+			if after.App.Requests < before.App.Requests {
+				diff.Reset = true
+			} else {
+				diff.Requests = after.App.Requests - before.App.Requests
+			}
+		}
+
+		return diff
+	}
+
+	previous := before.counters()
+	current := after.counters()
+
+	for path, counters := range current {
+		old, exists := previous[path]
+
+		routeDiff := &RouteDiff{
+			Route: path,
+			New:   !exists,
+		}
+
+		if counters.requests < old.requests || counters.errors < old.errors || counters.responseTime < old.responseTime {
+			routeDiff.Reset = true
+			diff.Reset = true
+		} else {
+			routeDiff.Requests = counters.requests - old.requests
+			routeDiff.Errors = counters.errors - old.errors
+			diff.Requests += routeDiff.Requests
+
+			// The average of the interval is derived from the sums, not from the two averages
+			if counters.measured > old.measured {
+				measured := counters.measured - old.measured
+				routeDiff.ResponseTimeMs = float64(counters.responseTime-old.responseTime) / float64(measured) / float64(time.Millisecond)
+			}
+		}
+
+		if routeDiff.Requests > 0 || routeDiff.New || routeDiff.Reset {
+			diff.Routes = append(diff.Routes, routeDiff)
+		}
+	}
+
+	sort.Slice(diff.Routes, func(i, j int) bool {
+		if diff.Routes[i].Requests != diff.Routes[j].Requests {
+			return diff.Routes[i].Requests > diff.Routes[j].Requests
+		}
+
+		return diff.Routes[i].Route < diff.Routes[j].Route
+	})
+
+	return diff
+}
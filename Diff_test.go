@@ -0,0 +1,46 @@
+package stats_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aerogo/stats"
+	"github.com/aerogo/stats/testutil"
+)
+
+// TestDiffBeyondRouteListLimit checks that the diff includes the routes left out of the rendered lists.
+func TestDiffBeyondRouteListLimit(t *testing.T) {
+	clock := testutil.NewFakeClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	collector := stats.NewCollector(stats.WithClock(clock), stats.WithRouteListLimit(1))
+	defer collector.Close(context.Background())
+
+	collector.Track("/a", time.Millisecond)
+	collector.Track("/a", time.Millisecond)
+	collector.Track("/b", time.Millisecond)
+
+	request := httptest.NewRequest(http.MethodGet, stats.DefaultPath+"?format=json", nil)
+	response := httptest.NewRecorder()
+	collector.ServeHTTP(response, request)
+	etag := response.Header().Get("ETag")
+
+	clock.Advance(time.Minute)
+	collector.Track("/b", 3*time.Millisecond)
+
+	var diff stats.SnapshotDiff
+
+	if err := json.Unmarshal([]byte(served(t, collector, "&since="+etag)), &diff); err != nil {
+		t.Fatal(err)
+	}
+
+	if diff.Reset || diff.Requests != 1 || len(diff.Routes) != 1 {
+		t.Fatalf("diff %+v, expected a single request without a reset", diff)
+	}
+
+	if route := diff.Routes[0]; route.Route != "/b" || route.New || route.Requests != 1 || route.ResponseTimeMs != 3 {
+		t.Errorf("route diff %+v, expected one request of 3ms to the existing route /b", route)
+	}
+}
@@ -0,0 +1,12 @@
+package stats
+
+import "time"
+
+// Distribution records response times and answers quantile queries.
+type Distribution interface {
+	Record(duration time.Duration)
+	Quantile(q float64) time.Duration
+}
+
+// DistributionFactory creates a new distribution for a route.
+type DistributionFactory func() Distribution
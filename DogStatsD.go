@@ -0,0 +1,301 @@
+package stats
+
+import (
+	"context"
+	"math/rand"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Default DogStatsD settings
+const (
+	defaultDogStatsDPacketSize = 1432
+	dogStatsDFlushInterval     = time.Second
+	dogStatsDQueueSize         = 4096
+)
+
+// DogStatsDConfig configures the DogStatsD exporter.
+type DogStatsDConfig struct {
+	// Tags are added to every metric, e.g. "service:api" or "env:prod".
+	Tags []string
+
+	// Interval sends the changes of the route counters in this interval.
+	// If it is zero, every request is sent individually.
+	Interval time.Duration
+
+	// SampleRate is the fraction of requests that are sent individually, 0 sends all of them.
+	SampleRate float64
+
+	// MaxPacketSize is the maximum payload of a datagram, 1432 bytes by default.
+	MaxPacketSize int
+
+	// Prefix is prepended to every metric name, e.g. "myapp.".
+	Prefix string
+
+	// Plain sends plain StatsD without the tag extension,
+	// the constant tags are left out and the values of the other tags are appended to the name.
+	Plain bool
+}
+
+// dogStatsD sends metrics in batches to a DogStatsD agent.
+// Send errors are ignored, metrics are never worth blocking or failing a request.
+type dogStatsD struct {
+	conn    net.Conn
+	config  DogStatsDConfig
+	tags    string
+	packet  []byte
+	records chan RequestRecord
+}
+
+// DogStatsD sends the request metrics with route, method, status class and protocol tags
+// to the DogStatsD agent at the given UDP address.
+func (stats *Collector) DogStatsD(address string, config DogStatsDConfig) error {
+	conn, err := net.Dial("udp", address)
+
+	if err != nil {
+		return err
+	}
+
+	if config.MaxPacketSize <= 0 {
+		config.MaxPacketSize = defaultDogStatsDPacketSize
+	}
+
+	exporter := &dogStatsD{
+		conn:   conn,
+		config: config,
+		tags:   strings.Join(config.Tags, ","),
+		packet: make([]byte, 0, config.MaxPacketSize),
+	}
+
+	if config.Interval > 0 {
+		stats.dogStatsDIntervals(exporter)
+	} else {
+		stats.dogStatsDRequests(exporter)
+	}
+
+	return nil
+}
+
+// StatsD sends the request metrics to a plain StatsD server at the given UDP address,
+// with the route, method, status class and protocol in the metric names.
+func (stats *Collector) StatsD(address string, config DogStatsDConfig) error {
+	config.Plain = true
+	return stats.DogStatsD(address, config)
+}
+
+// dogStatsDRequests sends every request individually.
+// Requests are queued and dropped if the queue is full, so recording never blocks.
+func (stats *Collector) dogStatsDRequests(exporter *dogStatsD) {
+	exporter.records = make(chan RequestRecord, dogStatsDQueueSize)
+	stats.addSink(exporter, nil)
+
+	stats.goroutine(func() {
+		ticker := stats.clock.NewTicker(dogStatsDFlushInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stats.done:
+				return
+			case record := <-exporter.records:
+				exporter.addRequest(&record)
+			case <-ticker.C():
+				exporter.addRatios(stats.ratioStats())
+				exporter.flush()
+			}
+		}
+	})
+
+	stats.onClose(func(ctx context.Context) error {
+		for {
+			select {
+			case record := <-exporter.records:
+				exporter.addRequest(&record)
+			default:
+				exporter.flush()
+				return exporter.conn.Close()
+			}
+		}
+	})
+}
+
+// dogStatsDIntervals sends the changes of the route counters in every interval.
+func (stats *Collector) dogStatsDIntervals(exporter *dogStatsD) {
+	previous := stats.routeTotals()
+
+	send := func() {
+		current := stats.routeTotals()
+		exporter.addIntervals(previous, current)
+		exporter.addRatios(stats.ratioStats())
+		exporter.flush()
+		previous = current
+	}
+
+	stats.goroutine(func() {
+		ticker := stats.clock.NewTicker(exporter.config.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stats.done:
+				return
+			case <-ticker.C():
+				send()
+			}
+		}
+	})
+
+	stats.onClose(func(ctx context.Context) error {
+		send()
+		return exporter.conn.Close()
+	})
+}
+
+// Emit queues a request, dropping it if it isn't sampled or the queue is full.
+func (exporter *dogStatsD) Emit(record RequestRecord) {
+	rate := exporter.config.SampleRate
+
+	if rate > 0 && rate < 1 && rand.Float64() >= rate {
+		return
+	}
+
+	select {
+	case exporter.records <- record:
+	default:
+	}
+}
+
+// addRequest adds the metrics of a single request.
+func (exporter *dogStatsD) addRequest(record *RequestRecord) {
+	rate := ""
+
+	if exporter.config.SampleRate > 0 && exporter.config.SampleRate < 1 {
+		rate = "|@" + strconv.FormatFloat(exporter.config.SampleRate, 'f', -1, 64)
+	}
+
+	protocol := record.Protocol
+
+	if protocol == "" {
+		protocol = ProtocolHTTP
+	}
+
+	tags := routeTags(record.Route, protocol) + ",method:" + dogStatsDTag(record.Method) + ",status:" + record.statusClass()
+	duration := strconv.FormatFloat(float64(record.Duration)/float64(time.Millisecond), 'f', 3, 64)
+
+	exporter.add(exporter.metric("http.requests", "1|c"+rate, tags))
+	exporter.add(exporter.metric("http.request.duration", duration+"|ms"+rate, tags))
+	exporter.addBytes(record.RequestSize, record.ResponseSize, rate, tags)
+}
+
+// addIntervals adds the changes of the route counters, a route that was reset or evicted in between counts from zero.
+func (exporter *dogStatsD) addIntervals(previous map[string]routeTotals, current map[string]routeTotals) {
+	for path, totals := range current {
+		delta := totals.since(previous[path])
+
+		if delta.requestCount == 0 {
+			continue
+		}
+
+		tags := routeTags(path, totals.protocol)
+		exporter.add(exporter.metric("http.requests", strconv.FormatUint(delta.requestCount, 10)+"|c", tags))
+		exporter.addBytes(delta.requestBytes, delta.responseBytes, "", tags)
+
+		if delta.errorCount > 0 {
+			exporter.add(exporter.metric("http.errors", strconv.FormatUint(delta.errorCount, 10)+"|c", tags+",status:5xx"))
+		}
+
+		if delta.measuredCount > 0 {
+			average := float64(delta.responseTime) / float64(delta.measuredCount) / float64(time.Millisecond)
+			exporter.add(exporter.metric("http.request.duration.avg", strconv.FormatFloat(average, 'f', 3, 64)+"|g", tags))
+		}
+	}
+}
+
+// addBytes adds the bytes received in request bodies and sent in response bodies, leaving out zeros.
+func (exporter *dogStatsD) addBytes(received uint64, sent uint64, rate string, tags string) {
+	if received > 0 {
+		exporter.add(exporter.metric("http.request.bytes", strconv.FormatUint(received, 10)+"|c"+rate, tags))
+	}
+
+	if sent > 0 {
+		exporter.add(exporter.metric("http.response.bytes", strconv.FormatUint(sent, 10)+"|c"+rate, tags))
+	}
+}
+
+// addRatios adds the hit rates of the ratios as gauges.
+func (exporter *dogStatsD) addRatios(ratios map[string]RatioStats) {
+	for _, name := range ratioNames(ratios) {
+		exporter.add(exporter.metric("ratio.hit_rate", strconv.FormatFloat(ratios[name].HitRate, 'f', 3, 64)+"|g", "ratio:"+dogStatsDTag(name)))
+	}
+}
+
+// routeTags returns the route and protocol tags.
+func routeTags(route string, protocol string) string {
+	return "route:" + dogStatsDTag(route) + ",protocol:" + protocol
+}
+
+// metric formats a metric with the value including its type, e.g. "1|c".
+// DogStatsD receives the tags together with the constant tags,
+// plain StatsD receives the tag values as parts of the name.
+func (exporter *dogStatsD) metric(name string, value string, tags string) string {
+	if exporter.config.Plain {
+		for _, tag := range strings.Split(tags, ",") {
+			name += "." + statsDName(tag[strings.IndexByte(tag, ':')+1:])
+		}
+
+		return exporter.config.Prefix + name + ":" + value
+	}
+
+	if exporter.tags != "" {
+		tags = exporter.tags + "," + tags
+	}
+
+	return exporter.config.Prefix + name + ":" + value + "|#" + tags
+}
+
+// add appends a metric to the current packet, sending the packet first if the metric doesn't fit.
+func (exporter *dogStatsD) add(metric string) {
+	if len(exporter.packet) > 0 && len(exporter.packet)+1+len(metric) > exporter.config.MaxPacketSize {
+		exporter.flush()
+	}
+
+	if len(exporter.packet) > 0 {
+		exporter.packet = append(exporter.packet, '\n')
+	}
+
+	exporter.packet = append(exporter.packet, metric...)
+}
+
+// flush sends the current packet.
+func (exporter *dogStatsD) flush() {
+	if len(exporter.packet) == 0 {
+		return
+	}
+
+	exporter.conn.Write(exporter.packet)
+	exporter.packet = exporter.packet[:0]
+}
+
+// dogStatsDTag replaces the characters that separate metrics, values and tags.
+func dogStatsDTag(value string) string {
+	return dogStatsDTagReplacer.Replace(value)
+}
+
+// dogStatsDTagReplacer replaces the reserved characters of the DogStatsD protocol.
+var dogStatsDTagReplacer = strings.NewReplacer(",", "_", "|", "_", "#", "_", "\n", "_")
+
+// statsDName turns a tag value into a part of a plain StatsD metric name.
+func statsDName(value string) string {
+	value = statsDNameReplacer.Replace(strings.Trim(value, "/"))
+
+	if value == "" {
+		return "_"
+	}
+
+	return value
+}
+
+// statsDNameReplacer replaces the characters that separate the parts of a plain StatsD metric.
+var statsDNameReplacer = strings.NewReplacer(".", "_", "/", "_", ":", "_", "|", "_", "@", "_", " ", "_", "\n", "_")
@@ -0,0 +1,136 @@
+package stats
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+)
+
+// dumpTopRoutes is the number of routes listed in each ranking of a dump.
+const dumpTopRoutes = 10
+
+// dumpMutex serializes dumps so that concurrent signals don't interleave their output.
+var dumpMutex sync.Mutex
+
+// DumpOnSignal writes a plaintext summary of the statistics to w whenever the process receives sig.
+// Handlers for other signals are not affected. The returned function or Close removes the handler.
+// Use DumpSignal for a platform independent default (SIGUSR1).
+func (stats *Collector) DumpOnSignal(sig os.Signal, w io.Writer) (func(), error) {
+	if sig == nil {
+		return nil, errors.New("Dumping statistics on a signal is not supported on this platform")
+	}
+
+	signals := make(chan os.Signal, 1)
+	stop := make(chan struct{})
+	signal.Notify(signals, sig)
+
+	stats.goroutine(func() {
+		defer signal.Stop(signals)
+
+		for {
+			select {
+			case <-signals:
+				stats.Dump(w)
+			case <-stop:
+				return
+			case <-stats.done:
+				return
+			}
+		}
+	})
+
+	var once sync.Once
+
+	cancel := func() {
+		once.Do(func() {
+			signal.Stop(signals)
+			close(stop)
+		})
+	}
+
+	return cancel, nil
+}
+
+// Dump writes a compact plaintext summary of the statistics to w.
+func (stats *Collector) Dump(w io.Writer) error {
+	snapshot := stats.SnapshotSections(SectionApp, SectionRoutes)
+
+	dumpMutex.Lock()
+	defer dumpMutex.Unlock()
+
+	_, err := fmt.Fprintf(w, "Requests: %d\nUptime: %s\nMemory: %s allocated, %s GC threshold, %d objects\n",
+		snapshot.App.Requests,
+		snapshot.App.Uptime,
+		snapshot.App.Memory.Allocated,
+		snapshot.App.Memory.GCThreshold,
+		snapshot.App.Memory.Objects,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	err = dumpRoutes(w, "Slow", snapshot.Routes.Slow, snapshot.timeUnit)
+
+	if err != nil {
+		return err
+	}
+
+	err = dumpRoutes(w, "Popular", snapshot.Routes.Popular, snapshot.timeUnit)
+
+	if err != nil {
+		return err
+	}
+
+	return dumpExpensiveRoutes(w, snapshot.Routes.Expensive, snapshot.timeUnit)
+}
+
+// dumpRoutes writes the first routes of a ranking.
+func dumpRoutes(w io.Writer, title string, routes []*Route, unit time.Duration) error {
+	if len(routes) > dumpTopRoutes {
+		routes = routes[:dumpTopRoutes]
+	}
+
+	_, err := fmt.Fprintf(w, "%s:\n", title)
+
+	if err != nil {
+		return err
+	}
+
+	for _, route := range routes {
+		_, err = fmt.Fprintf(w, "  %-40s %8d requests %10s\n", route.Route, route.Requests, formatDuration(milliseconds(route.ResponseTimeMs), unit))
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// dumpExpensiveRoutes writes the routes that consumed the most server time with their share of the total.
+func dumpExpensiveRoutes(w io.Writer, routes []*Route, unit time.Duration) error {
+	if len(routes) > dumpTopRoutes {
+		routes = routes[:dumpTopRoutes]
+	}
+
+	_, err := fmt.Fprintln(w, "Expensive:")
+
+	if err != nil {
+		return err
+	}
+
+	for _, route := range routes {
+		_, err = fmt.Fprintf(w, "  %-40s %10s total %5.1f%%\n", route.Route, formatDuration(milliseconds(route.TotalTimeMs), unit), route.TimeShare)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
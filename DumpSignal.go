@@ -0,0 +1,8 @@
+//go:build !windows
+
+package stats
+
+import "syscall"
+
+// DumpSignal is the default signal for DumpOnSignal.
+var DumpSignal = syscall.SIGUSR1
@@ -0,0 +1,7 @@
+package stats
+
+import "os"
+
+// DumpSignal is the default signal for DumpOnSignal.
+// Windows has no SIGUSR1, so DumpOnSignal returns an error when it is used.
+var DumpSignal os.Signal
@@ -0,0 +1,87 @@
+package stats_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aerogo/stats"
+)
+
+// syncBuffer is a buffer that can be written by the dump goroutine while the test reads it.
+type syncBuffer struct {
+	buffer bytes.Buffer
+	mutex  sync.Mutex
+}
+
+func (buffer *syncBuffer) Write(data []byte) (int, error) {
+	buffer.mutex.Lock()
+	defer buffer.mutex.Unlock()
+	return buffer.buffer.Write(data)
+}
+
+func (buffer *syncBuffer) String() string {
+	buffer.mutex.Lock()
+	defer buffer.mutex.Unlock()
+	return buffer.buffer.String()
+}
+
+// waitFor polls the condition until it is true or a second has passed.
+func waitFor(condition func() bool) bool {
+	deadline := time.Now().Add(time.Second)
+
+	for !condition() {
+		if time.Now().After(deadline) {
+			return false
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+
+	return true
+}
+
+// goroutineRunning tells whether the stack of any goroutine contains the function name.
+func goroutineRunning(function string) bool {
+	buffer := make([]byte, 1<<20)
+	return strings.Contains(string(buffer[:runtime.Stack(buffer, true)]), function)
+}
+
+func TestDumpOnSignalStopsOnClose(t *testing.T) {
+	// DumpSignal is a syscall.Signal on Unix and a nil os.Signal on Windows
+	var sig os.Signal = stats.DumpSignal
+
+	if sig == nil {
+		t.Skip("no dump signal on this platform")
+	}
+
+	collector := stats.NewCollector()
+	output := &syncBuffer{}
+
+	if _, err := collector.DumpOnSignal(sig, output); err != nil {
+		t.Fatal(err)
+	}
+
+	process, _ := os.FindProcess(os.Getpid())
+
+	if err := process.Signal(sig); err != nil {
+		t.Fatal(err)
+	}
+
+	if !waitFor(func() bool { return strings.Contains(output.String(), "Requests:") }) {
+		t.Fatal("no dump after the signal")
+	}
+
+	if err := collector.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if !waitFor(func() bool { return !goroutineRunning("(*Collector).DumpOnSignal.func") }) {
+		t.Error("the signal handler is still running after Close")
+	}
+}
@@ -0,0 +1,163 @@
+package stats
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// emfMutex serializes EMF documents so that concurrent flushes to the same writer don't interleave.
+var emfMutex sync.Mutex
+
+// emfMetrics are the metric definitions of every EMF document.
+// Each document contains the metrics of a single route and status class,
+// far below the limit of 100 metrics per document.
+var emfMetrics = []emfMetric{
+	{Name: "Requests", Unit: "Count"},
+	{Name: "Latency", Unit: "Milliseconds"},
+}
+
+// emfDimensions are the dimensions of every EMF document.
+var emfDimensions = [][]string{{"Route", "StatusClass"}}
+
+// emfMetric is the definition of a metric in the EMF metadata.
+type emfMetric struct {
+	Name string
+	Unit string
+}
+
+// emfKey identifies the requests of a route with a status class.
+type emfKey struct {
+	route       string
+	statusClass string
+}
+
+// emfValues are the totals of an interval.
+type emfValues struct {
+	protocol string
+	requests uint64
+	duration time.Duration
+}
+
+// emfExporter aggregates requests for the CloudWatch embedded metric format.
+type emfExporter struct {
+	writer    io.Writer
+	namespace string
+	values    map[emfKey]*emfValues
+	mutex     sync.Mutex
+}
+
+// EMF writes the request count and average latency of every route and status class
+// in the CloudWatch embedded metric format to w, one JSON document per line.
+// The values are the changes of each interval, a final flush happens on Close.
+func (stats *Collector) EMF(w io.Writer, namespace string, interval time.Duration) error {
+	if interval <= 0 {
+		return fmt.Errorf("EMF interval must be positive: %v", interval)
+	}
+
+	exporter := &emfExporter{
+		writer:    w,
+		namespace: namespace,
+		values:    map[emfKey]*emfValues{},
+	}
+
+	stats.addSink(exporter, nil)
+
+	stats.goroutine(func() {
+		ticker := stats.clock.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stats.done:
+				return
+			case now := <-ticker.C():
+				exporter.flush(now)
+			}
+		}
+	})
+
+	stats.onClose(func(ctx context.Context) error {
+		return exporter.flush(stats.clock.Now())
+	})
+
+	return nil
+}
+
+// Emit adds a request to the totals of the current interval.
+func (exporter *emfExporter) Emit(record RequestRecord) {
+	key := emfKey{
+		route:       record.Route,
+		statusClass: record.statusClass(),
+	}
+
+	exporter.mutex.Lock()
+	values := exporter.values[key]
+
+	if values == nil {
+		values = &emfValues{protocol: record.Protocol}
+		exporter.values[key] = values
+	}
+
+	values.requests++
+	values.duration += record.Duration
+	exporter.mutex.Unlock()
+}
+
+// flush writes the totals of the interval and starts a new one.
+func (exporter *emfExporter) flush(now time.Time) error {
+	exporter.mutex.Lock()
+	values := exporter.values
+	exporter.values = make(map[emfKey]*emfValues, len(values))
+	exporter.mutex.Unlock()
+
+	if len(values) == 0 {
+		return nil
+	}
+
+	metadata := map[string]interface{}{
+		"Timestamp": now.UnixNano() / int64(time.Millisecond),
+		"CloudWatchMetrics": []interface{}{
+			map[string]interface{}{
+				"Namespace":  exporter.namespace,
+				"Dimensions": emfDimensions,
+				"Metrics":    emfMetrics,
+			},
+		},
+	}
+
+	emfMutex.Lock()
+	defer emfMutex.Unlock()
+
+	for key, value := range values {
+		protocol := value.protocol
+
+		if protocol == "" {
+			protocol = ProtocolHTTP
+		}
+
+		document, err := json.Marshal(map[string]interface{}{
+			"_aws":        metadata,
+			"Route":       key.route,
+			"StatusClass": key.statusClass,
+			"Protocol":    protocol,
+			"Requests":    value.requests,
+			"Latency":     float64(value.duration) / float64(value.requests) / float64(time.Millisecond),
+		})
+
+		if err != nil {
+			return err
+		}
+
+		_, err = exporter.writer.Write(append(document, '\n'))
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,21 @@
+package stats_test
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/aerogo/stats"
+)
+
+func TestEMFInterval(t *testing.T) {
+	collector := stats.NewCollector()
+	defer collector.Close(context.Background())
+
+	for _, interval := range []time.Duration{0, -time.Second} {
+		if err := collector.EMF(io.Discard, "app", interval); err == nil {
+			t.Errorf("interval %v was accepted", interval)
+		}
+	}
+}
@@ -0,0 +1,10 @@
+package stats
+
+import "net/http"
+
+// Endpoint is a route served by the collector.
+type Endpoint struct {
+	Method  string
+	Path    string
+	Handler http.HandlerFunc
+}
@@ -0,0 +1,131 @@
+package stats
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// defaultErrorLogSize is the default number of recent errors that are kept.
+const defaultErrorLogSize = 50
+
+// maxErrorMessageLength is the maximum length of a recorded error message.
+const maxErrorMessageLength = 256
+
+// ErrorEvent is a single failed request.
+type ErrorEvent struct {
+	Time       time.Time
+	Route      string
+	Method     string
+	StatusCode int
+	DurationMs float64
+	Message    string `json:",omitempty"`
+	TraceID    string `json:",omitempty"`
+}
+
+// errorLog is a ring buffer of the most recent errors.
+type errorLog struct {
+	events []ErrorEvent
+	next   int
+	full   bool
+	mutex  sync.Mutex
+}
+
+// WithErrorLog sets the number of recent errors that are kept.
+func WithErrorLog(size int) Option {
+	return func(stats *Collector) {
+		stats.errors = newErrorLog(size)
+	}
+}
+
+// WithErrorRedactor sets a function that can modify error events before they are served,
+// e.g. to remove user data from error messages.
+func WithErrorRedactor(redact func(*ErrorEvent)) Option {
+	return func(stats *Collector) {
+		stats.errorRedactor = redact
+	}
+}
+
+// RecordError attaches an error message to the request handled with ctx.
+// The request is then listed in the recent errors even if its status code doesn't indicate a failure.
+// Outside of a request tracked by the Recorder middleware or TrackRequest, it does nothing.
+func RecordError(ctx context.Context, err error) {
+	state, _ := ctx.Value(requestStateKey).(*requestState)
+
+	if state == nil || err == nil {
+		return
+	}
+
+	state.mutex.Lock()
+	state.err = errorMessage(err.Error())
+	state.mutex.Unlock()
+}
+
+// errorMessage shortens an error message to the maximum length.
+func errorMessage(message string) string {
+	if len(message) > maxErrorMessageLength {
+		message = message[:maxErrorMessageLength] + "..."
+	}
+
+	return message
+}
+
+// newErrorLog creates an error log with the given capacity.
+func newErrorLog(size int) *errorLog {
+	if size < 1 {
+		size = 1
+	}
+
+	return &errorLog{
+		events: make([]ErrorEvent, size),
+	}
+}
+
+// add stores an event, overwriting the oldest one if the log is full.
+func (log *errorLog) add(event ErrorEvent) {
+	log.mutex.Lock()
+	log.events[log.next] = event
+	log.next++
+
+	if log.next == len(log.events) {
+		log.next = 0
+		log.full = true
+	}
+
+	log.mutex.Unlock()
+}
+
+// Events returns a copy of the stored events, newest first.
+func (log *errorLog) Events() []ErrorEvent {
+	log.mutex.Lock()
+	defer log.mutex.Unlock()
+
+	count := log.next
+
+	if log.full {
+		count = len(log.events)
+	}
+
+	events := make([]ErrorEvent, 0, count)
+
+	for i := 1; i <= count; i++ {
+		index := (log.next - i + len(log.events)) % len(log.events)
+		events = append(events, log.events[index])
+	}
+
+	return events
+}
+
+// showErrors serves the recent errors as JSON, newest first.
+func (stats *Collector) showErrors(response http.ResponseWriter, request *http.Request) {
+	events := stats.errors.Events()
+
+	if stats.errorRedactor != nil {
+		for i := range events {
+			stats.errorRedactor(&events[i])
+		}
+	}
+
+	writeJSON(response, events)
+}
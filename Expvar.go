@@ -0,0 +1,72 @@
+package stats
+
+import (
+	"errors"
+	"expvar"
+	"sync/atomic"
+	"time"
+)
+
+// ExpvarName is the name the statistics are published under by PublishExpvar.
+const ExpvarName = "stats"
+
+// expvarStatistics contains the counters published via expvar.
+type expvarStatistics struct {
+	Requests uint64
+	Errors   uint64
+	Routes   map[string]expvarRoute
+	Metrics  *MetricStats `json:",omitempty"`
+}
+
+// expvarRoute contains the counters of a route published via expvar.
+type expvarRoute struct {
+	Requests       uint64
+	Errors         uint64
+	Aborted        uint64
+	ResponseTimeMs float64
+	RequestBytes   uint64
+	ResponseBytes  uint64
+}
+
+// PublishExpvar publishes the request counters of all routes and the application metrics
+// as the expvar variable "stats", served as JSON by expvar's /debug/vars handler.
+// expvar registers that handler on http.DefaultServeMux, other muxes need expvar.Handler.
+// The variable is computed on every read. Only one collector can be published per process.
+func (stats *Collector) PublishExpvar() error {
+	if expvar.Get(ExpvarName) != nil {
+		return errors.New("The expvar variable " + ExpvarName + " is already published")
+	}
+
+	expvar.Publish(ExpvarName, expvar.Func(func() interface{} {
+		return stats.expvarStatistics()
+	}))
+
+	return nil
+}
+
+// expvarStatistics collects the counters published via expvar.
+func (stats *Collector) expvarStatistics() *expvarStatistics {
+	published := &expvarStatistics{
+		Routes:  map[string]expvarRoute{},
+		Metrics: stats.metrics.stats(),
+	}
+
+	for _, tracked := range stats.trackedRoutes() {
+		route := tracked.stats
+		requests := atomic.LoadUint64(&route.requestCount)
+		errorCount := atomic.LoadUint64(&route.errorCount)
+
+		published.Requests += requests
+		published.Errors += errorCount
+		published.Routes[tracked.path] = expvarRoute{
+			Requests:       requests,
+			Errors:         errorCount,
+			Aborted:        atomic.LoadUint64(&route.abortedCount),
+			ResponseTimeMs: float64(route.AverageResponseTime()) / float64(time.Millisecond),
+			RequestBytes:   atomic.LoadUint64(&route.requestBytes),
+			ResponseBytes:  atomic.LoadUint64(&route.responseSizes.total),
+		}
+	}
+
+	return published
+}
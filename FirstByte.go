@@ -0,0 +1,75 @@
+package stats
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// firstByteCounters track the time until the first byte of the response was written.
+// A route is streaming once a handler has flushed a response before returning.
+type firstByteCounters struct {
+	count     uint64
+	total     uint64
+	max       uint64
+	streaming uint32
+}
+
+// record adds the time to first byte of a measured request.
+func (counters *firstByteCounters) record(record *RequestRecord) {
+	if record.Streamed && atomic.LoadUint32(&counters.streaming) == 0 {
+		atomic.StoreUint32(&counters.streaming, 1)
+	}
+
+	if record.FirstByte <= 0 {
+		return
+	}
+
+	atomic.AddUint64(&counters.count, 1)
+	atomic.AddUint64(&counters.total, uint64(record.FirstByte))
+	atomicMax(&counters.max, uint64(record.FirstByte))
+}
+
+// fold adds the totals of another route.
+func (counters *firstByteCounters) fold(other *firstByteCounters) {
+	atomic.AddUint64(&counters.count, atomic.LoadUint64(&other.count))
+	atomic.AddUint64(&counters.total, atomic.LoadUint64(&other.total))
+	atomicMax(&counters.max, atomic.LoadUint64(&other.max))
+
+	if atomic.LoadUint32(&other.streaming) == 1 {
+		atomic.StoreUint32(&counters.streaming, 1)
+	}
+}
+
+// apply sets the time to first byte fields of the exported route.
+func (counters *firstByteCounters) apply(route *Route) {
+	route.Streaming = atomic.LoadUint32(&counters.streaming) == 1
+	count := atomic.LoadUint64(&counters.count)
+
+	if count == 0 {
+		return
+	}
+
+	route.FirstByteNs = int64(atomic.LoadUint64(&counters.total) / count)
+	route.FirstByteMs = roundMilliseconds(time.Duration(route.FirstByteNs))
+	route.MaxFirstByteMs = roundMilliseconds(time.Duration(atomic.LoadUint64(&counters.max)))
+}
+
+// slowLatency returns the response time the route is ranked by in the Slow summary.
+// Streaming routes are ranked by their time to first byte, their total time is mostly transfer time.
+// Other routes are ranked by their successful responses unless WithBlendedSlowRoutes is used.
+// Both are lifetime values, so windowed summaries rank all routes by their response time in the window.
+func (stats *Collector) slowLatency(route *Route, windowed bool) time.Duration {
+	if windowed {
+		return time.Duration(route.ResponseTimeNs)
+	}
+
+	if route.Streaming && route.FirstByteNs > 0 {
+		return time.Duration(route.FirstByteNs)
+	}
+
+	if !stats.blendedSlowRoutes && route.ResponseTime2xxMs > 0 {
+		return time.Duration(route.ResponseTime2xxMs * float64(time.Millisecond))
+	}
+
+	return time.Duration(route.ResponseTimeNs)
+}
@@ -0,0 +1,59 @@
+package stats
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Output formats
+const (
+	formatJSON     = "json"
+	formatText     = "text"
+	formatTerminal = "term"
+	formatHTML     = "html"
+)
+
+// terminalClients are User-Agent prefixes of command line clients that receive plaintext by default.
+var terminalClients = []string{
+	"curl/",
+	"Wget/",
+}
+
+// WithTerminalDetection enables or disables sending plaintext to command line clients like curl by default.
+// Detection is enabled by default and the "format" query parameter always takes precedence.
+func WithTerminalDetection(enabled bool) Option {
+	return func(stats *Collector) {
+		stats.terminalDetection = enabled
+	}
+}
+
+// responseFormat determines the output format requested by the client.
+func (stats *Collector) responseFormat(request *http.Request) string {
+	format := request.URL.Query().Get("format")
+
+	if format != "" {
+		return format
+	}
+
+	accept := request.Header.Get("Accept")
+
+	if strings.Contains(accept, "text/html") {
+		return formatHTML
+	}
+
+	if strings.Contains(accept, "text/plain") {
+		return formatText
+	}
+
+	if stats.terminalDetection {
+		userAgent := request.UserAgent()
+
+		for _, prefix := range terminalClients {
+			if strings.HasPrefix(userAgent, prefix) {
+				return formatText
+			}
+		}
+	}
+
+	return formatJSON
+}
@@ -0,0 +1,63 @@
+package stats
+
+import (
+	"runtime/debug"
+	"time"
+)
+
+// GCStats contains the recent stop-the-world pauses of the garbage collector.
+// The runtime keeps the last 256 pauses in a ring buffer (runtime.MemStats.PauseNs),
+// the averages, the maximum and the frequency are computed from the pauses in it.
+type GCStats struct {
+	Cycles       int64
+	LastGC       time.Time `json:",omitempty"`
+	LastPauseMs  float64
+	AvgPauseMs   float64
+	MaxPauseMs   float64
+	TotalPauseMs float64
+	RecentPauses int
+	PerMinute    float64
+}
+
+// readGCStats reads the recent GC pauses, nil if no GC has run yet.
+func readGCStats() *GCStats {
+	var gc debug.GCStats
+	debug.ReadGCStats(&gc)
+
+	if gc.NumGC == 0 || len(gc.Pause) == 0 {
+		return nil
+	}
+
+	stats := &GCStats{
+		Cycles:       gc.NumGC,
+		LastGC:       gc.LastGC,
+		LastPauseMs:  float64(gc.Pause[0]) / float64(time.Millisecond),
+		TotalPauseMs: float64(gc.PauseTotal) / float64(time.Millisecond),
+		RecentPauses: len(gc.Pause),
+	}
+
+	var sum time.Duration
+	var max time.Duration
+
+	// Pause lists the most recent pauses first
+	for _, pause := range gc.Pause {
+		sum += pause
+
+		if pause > max {
+			max = pause
+		}
+	}
+
+	stats.AvgPauseMs = float64(sum) / float64(len(gc.Pause)) / float64(time.Millisecond)
+	stats.MaxPauseMs = float64(max) / float64(time.Millisecond)
+
+	if len(gc.PauseEnd) >= 2 {
+		span := gc.PauseEnd[0].Sub(gc.PauseEnd[len(gc.PauseEnd)-1])
+
+		if span > 0 {
+			stats.PerMinute = float64(len(gc.PauseEnd)-1) / span.Minutes()
+		}
+	}
+
+	return stats
+}
@@ -0,0 +1,180 @@
+package stats
+
+import (
+	"math"
+	"math/bits"
+	"sync/atomic"
+	"time"
+)
+
+// HDRHistogramDistribution is a high dynamic range histogram
+// with a bounded relative error determined by the number of significant figures.
+// Recording is lock-free: every value increments a single bucket atomically.
+type HDRHistogramDistribution struct {
+	lowest             int64
+	highest            int64
+	unitMagnitude      uint
+	subBucketHalfCount int64
+	subBucketHalfMag   uint
+	subBucketMask      int64
+	counts             []uint64
+	total              uint64
+	overflow           uint64
+}
+
+// HDRBucket is a single non-empty histogram bucket.
+type HDRBucket struct {
+	Value time.Duration
+	Count uint64
+}
+
+// HDRHistogram returns a factory for HDR histograms tracking values
+// between lowest and highest with the given number of significant figures (1-5).
+func HDRHistogram(lowest time.Duration, highest time.Duration, significantFigures int) DistributionFactory {
+	return func() Distribution {
+		return NewHDRHistogram(lowest, highest, significantFigures)
+	}
+}
+
+// NewHDRHistogram creates a new HDR histogram.
+func NewHDRHistogram(lowest time.Duration, highest time.Duration, significantFigures int) *HDRHistogramDistribution {
+	if lowest < 1 {
+		lowest = 1
+	}
+
+	if highest < 2*lowest {
+		highest = 2 * lowest
+	}
+
+	if significantFigures < 1 {
+		significantFigures = 1
+	}
+
+	if significantFigures > 5 {
+		significantFigures = 5
+	}
+
+	largestSingleUnitResolution := 2 * int64(math.Pow10(significantFigures))
+	subBucketCountMagnitude := uint(math.Ceil(math.Log2(float64(largestSingleUnitResolution))))
+	subBucketHalfMag := subBucketCountMagnitude - 1
+	subBucketCount := int64(1) << subBucketCountMagnitude
+	unitMagnitude := uint(math.Floor(math.Log2(float64(lowest))))
+
+	// Number of power-of-two buckets needed to cover the highest trackable value
+	smallestUntrackable := subBucketCount << unitMagnitude
+	bucketCount := 1
+
+	for smallestUntrackable <= int64(highest) {
+		if smallestUntrackable > math.MaxInt64/2 {
+			bucketCount++
+			break
+		}
+
+		smallestUntrackable <<= 1
+		bucketCount++
+	}
+
+	histogram := &HDRHistogramDistribution{
+		lowest:             int64(lowest),
+		highest:            int64(highest),
+		unitMagnitude:      unitMagnitude,
+		subBucketHalfCount: subBucketCount / 2,
+		subBucketHalfMag:   subBucketHalfMag,
+		subBucketMask:      (subBucketCount - 1) << unitMagnitude,
+	}
+
+	histogram.counts = make([]uint64, (int64(bucketCount)+1)*histogram.subBucketHalfCount)
+	return histogram
+}
+
+// Record adds a response time to the histogram.
+// Values above the highest trackable value are clamped and counted as overflow.
+func (histogram *HDRHistogramDistribution) Record(duration time.Duration) {
+	value := int64(duration)
+
+	if value < 0 {
+		value = 0
+	}
+
+	if value > histogram.highest {
+		value = histogram.highest
+		atomic.AddUint64(&histogram.overflow, 1)
+	}
+
+	atomic.AddUint64(&histogram.counts[histogram.countsIndex(value)], 1)
+	atomic.AddUint64(&histogram.total, 1)
+}
+
+// Quantile returns the response time at quantile q (0 <= q <= 1).
+func (histogram *HDRHistogramDistribution) Quantile(q float64) time.Duration {
+	total := atomic.LoadUint64(&histogram.total)
+
+	if total == 0 {
+		return 0
+	}
+
+	target := uint64(math.Ceil(q * float64(total)))
+
+	if target < 1 {
+		target = 1
+	}
+
+	cumulative := uint64(0)
+
+	for index := range histogram.counts {
+		cumulative += atomic.LoadUint64(&histogram.counts[index])
+
+		if cumulative >= target {
+			return time.Duration(histogram.highestEquivalentValue(index))
+		}
+	}
+
+	return time.Duration(histogram.highest)
+}
+
+// Buckets returns all non-empty buckets in ascending order.
+func (histogram *HDRHistogramDistribution) Buckets() []HDRBucket {
+	var buckets []HDRBucket
+
+	for index := range histogram.counts {
+		count := atomic.LoadUint64(&histogram.counts[index])
+
+		if count == 0 {
+			continue
+		}
+
+		buckets = append(buckets, HDRBucket{
+			Value: time.Duration(histogram.highestEquivalentValue(index)),
+			Count: count,
+		})
+	}
+
+	return buckets
+}
+
+// Overflow returns the number of values that exceeded the highest trackable value.
+func (histogram *HDRHistogramDistribution) Overflow() uint64 {
+	return atomic.LoadUint64(&histogram.overflow)
+}
+
+// countsIndex returns the index of the bucket the value falls into.
+func (histogram *HDRHistogramDistribution) countsIndex(value int64) int {
+	pow2Ceiling := 64 - bits.LeadingZeros64(uint64(value|histogram.subBucketMask))
+	bucketIndex := int64(pow2Ceiling) - int64(histogram.unitMagnitude) - int64(histogram.subBucketHalfMag+1)
+	subBucketIndex := value >> uint(bucketIndex+int64(histogram.unitMagnitude))
+	return int(((bucketIndex + 1) << histogram.subBucketHalfMag) + (subBucketIndex - histogram.subBucketHalfCount))
+}
+
+// highestEquivalentValue returns the largest value that maps to the given index.
+func (histogram *HDRHistogramDistribution) highestEquivalentValue(index int) int64 {
+	bucketIndex := int64(index>>histogram.subBucketHalfMag) - 1
+	subBucketIndex := int64(index)&(histogram.subBucketHalfCount-1) + histogram.subBucketHalfCount
+
+	if bucketIndex < 0 {
+		subBucketIndex -= histogram.subBucketHalfCount
+		bucketIndex = 0
+	}
+
+	shift := uint(bucketIndex) + histogram.unitMagnitude
+	return (subBucketIndex << shift) + (int64(1) << shift) - 1
+}
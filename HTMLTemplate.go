@@ -0,0 +1,240 @@
+package stats
+
+import (
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// TemplateFuncs are the functions available in HTML templates.
+// Custom templates must be parsed with them; the real implementations are bound on installation.
+//
+//	{{sparkline .Route "rps"}} renders the requests per second of a route over the last hour
+//	{{sparkline .Route "latency"}} renders the average response time of a route over the last hour
+//	{{trend "rps"}} renders a trend of the whole app, "rps" and "latency" over the last hour,
+//	"memory" and "gc" (the longest GC pause) over the last 60 sample intervals
+//	{{asset "style.css"}} returns the URL of a dashboard asset
+//	{{duration .ResponseTimeMs}} formats a response time in milliseconds using the configured time unit
+var TemplateFuncs = template.FuncMap{
+	"sparkline": func(route string, metric string) template.HTML { return "" },
+	"trend":     func(metric string) template.HTML { return "" },
+	"asset":     func(name string) string { return "" },
+	"duration":  func(ms float64) string { return "" },
+}
+
+// defaultHTMLTemplate is the built-in dashboard.
+// The template receives the *Snapshot as its data.
+var defaultHTMLTemplate = template.Must(template.New("stats").Funcs(TemplateFuncs).Parse(`<!DOCTYPE html>
+<html>
+<head>
+	<meta charset="utf-8">
+	<title>Statistics</title>
+</head>
+<body>
+	{{with .App}}
+	<h2>App</h2>
+	<table>
+		<tr><td>Go</td><td>{{.Go}}</td></tr>
+		<tr><td>Uptime</td><td>{{.Uptime}}</td></tr>
+		<tr><td>Requests</td><td>{{.Requests}}</td></tr>
+		<tr><td>Memory</td><td>{{.Memory.Allocated}} allocated, {{.Memory.GCThreshold}} GC threshold, {{.Memory.Objects}} objects</td></tr>
+		<tr><td>Bandwidth</td><td>{{.Bandwidth.Received}} received, {{.Bandwidth.Sent}} sent</td></tr>
+		<tr><td>Requests/s</td><td>{{trend "rps"}}</td></tr>
+		<tr><td>Latency</td><td>{{trend "latency"}}</td></tr>
+		<tr><td>Allocated</td><td>{{trend "memory"}}</td></tr>
+		<tr><td>GC pauses</td><td>{{trend "gc"}}</td></tr>
+		{{range $name, $value := .Gauges}}
+		<tr><td>{{$name}}</td><td>{{$value}}</td></tr>
+		{{end}}
+	</table>
+	{{end}}
+	{{with .Process}}
+	<h2>Process</h2>
+	<table>
+		<tr><td>Go</td><td>{{.Go}}</td></tr>
+		<tr><td>Uptime</td><td>{{.Uptime}}</td></tr>
+		<tr><td>Memory</td><td>{{.Memory.Allocated}} allocated, {{.Memory.GCThreshold}} GC threshold, {{.Memory.Objects}} objects</td></tr>
+	</table>
+	{{end}}
+	{{with .System}}
+	<h2>System</h2>
+	<table>
+		{{with .Uptime}}<tr><td>Uptime</td><td>{{.}}</td></tr>{{end}}
+		<tr><td>CPUs</td><td>{{.CPUs}}</td></tr>
+		{{with .LoadAverage}}<tr><td>Load</td><td>{{.One}} {{.Five}} {{.Fifteen}}</td></tr>{{end}}
+		{{with .Memory}}<tr><td>Memory</td><td>{{.Total}} total, {{.Free}} free, {{.Cache}} cache</td></tr>{{end}}
+		{{with .Swap}}<tr><td>Swap</td><td>{{.Total}} total, {{.Used}} used, {{.Free}} free</td></tr>{{end}}
+		{{range .CollectionErrors}}<tr><td>Error</td><td>{{.Metric}}: {{.Error}}</td></tr>{{end}}
+	</table>
+	{{end}}
+	{{with .Routes}}
+	<h2>Routes</h2>
+	<table>
+		<tr><th>Route</th><th>Requests</th><th>Avg</th><th>Min</th><th>Max</th><th>Errors</th><th>Requests/s</th><th>Latency</th></tr>
+		{{range .Popular}}
+		<tr><td>{{.Route}}</td><td>{{.Requests}}</td><td>{{duration .ResponseTimeMs}}</td><td>{{duration .MinResponseTimeMs}}</td><td>{{duration .MaxResponseTimeMs}}</td><td>{{.Errors}}</td><td>{{sparkline .Route "rps"}}</td><td>{{sparkline .Route "latency"}}</td></tr>
+		{{end}}
+	</table>
+	<h2>Expensive routes</h2>
+	<table>
+		<tr><th>Route</th><th>Total time</th><th>Share</th></tr>
+		{{range .Expensive}}
+		<tr><td>{{.Route}}</td><td>{{duration .TotalTimeMs}}</td><td>{{printf "%.1f" .TimeShare}}%</td></tr>
+		{{end}}
+	</table>
+	{{end}}
+</body>
+</html>
+`))
+
+// htmlTemplate renders the HTML dashboard.
+// A template loaded from a file can optionally be reloaded when the file changes.
+type htmlTemplate struct {
+	template *template.Template
+	funcs    template.FuncMap
+	path     string
+	reload   bool
+	modTime  time.Time
+	loaded   bool
+	err      error
+	assetURL func(string) string
+	mutex    sync.Mutex
+}
+
+// WithHTMLTemplate replaces the built-in HTML dashboard.
+// The template receives the *Snapshot as its data and must be parsed with TemplateFuncs.
+func WithHTMLTemplate(tmpl *template.Template) Option {
+	return func(stats *Collector) {
+		stats.html = &htmlTemplate{
+			template: tmpl,
+		}
+	}
+}
+
+// WithHTMLTemplateFile replaces the built-in HTML dashboard with a template file.
+// If reload is true, the file is parsed again whenever it changes, which is useful during development.
+// Parse errors are reported by Endpoints and the built-in dashboard is used instead.
+func WithHTMLTemplateFile(path string, reload bool) Option {
+	return func(stats *Collector) {
+		stats.html = &htmlTemplate{
+			path:   path,
+			reload: reload,
+		}
+	}
+}
+
+// templateFuncs returns the real implementations of TemplateFuncs for the collector.
+func (stats *Collector) templateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"sparkline": stats.sparkline,
+		"trend":     stats.trend,
+		"asset":     stats.html.asset,
+		"duration": func(ms float64) string {
+			return formatDuration(milliseconds(ms), stats.timeUnit)
+		},
+	}
+}
+
+// load clones the template or parses the template file and binds the template functions, once per collector.
+// Templates can't be cloned after they have been executed, so the shared templates are never executed themselves.
+// On failure, the built-in template is used and the error is kept for Endpoints.
+func (html *htmlTemplate) load(funcs template.FuncMap) {
+	html.mutex.Lock()
+	defer html.mutex.Unlock()
+
+	if html.loaded {
+		return
+	}
+
+	html.loaded = true
+	html.funcs = funcs
+
+	if html.path == "" {
+		clone, err := html.template.Clone()
+
+		if err != nil {
+			html.err = err
+			clone = template.Must(defaultHTMLTemplate.Clone())
+		}
+
+		html.template = clone.Funcs(funcs)
+		return
+	}
+
+	html.err = html.parse()
+}
+
+// loadError returns the error that occurred while loading the template.
+func (html *htmlTemplate) loadError() error {
+	html.mutex.Lock()
+	defer html.mutex.Unlock()
+	return html.err
+}
+
+// setAssets sets the function that returns the URLs of the dashboard assets.
+func (html *htmlTemplate) setAssets(url func(string) string) {
+	html.mutex.Lock()
+	html.assetURL = url
+	html.mutex.Unlock()
+}
+
+// asset returns the URL of a dashboard asset, empty if no assets are served.
+func (html *htmlTemplate) asset(name string) string {
+	html.mutex.Lock()
+	url := html.assetURL
+	html.mutex.Unlock()
+
+	if url == nil {
+		return ""
+	}
+
+	return url(name)
+}
+
+// parse parses the template file, the caller must hold the mutex.
+// On failure, the built-in template is used.
+func (html *htmlTemplate) parse() error {
+	info, err := os.Stat(html.path)
+
+	if err == nil {
+		html.modTime = info.ModTime()
+		var parsed *template.Template
+		parsed, err = template.New(filepath.Base(html.path)).Funcs(html.funcs).ParseFiles(html.path)
+
+		if err == nil {
+			html.template = parsed
+		}
+	}
+
+	if err != nil {
+		html.template = template.Must(defaultHTMLTemplate.Clone()).Funcs(html.funcs)
+	}
+
+	return err
+}
+
+// render executes the template with the snapshot as its data.
+func (html *htmlTemplate) render(w io.Writer, snapshot *Snapshot) error {
+	html.mutex.Lock()
+
+	if html.reload {
+		info, err := os.Stat(html.path)
+
+		if err == nil && !info.ModTime().Equal(html.modTime) {
+			// Keep the last working template if the new version is broken
+			previous := html.template
+
+			if html.parse() != nil {
+				html.template = previous
+			}
+		}
+	}
+
+	tmpl := html.template
+	html.mutex.Unlock()
+
+	return tmpl.Execute(w, snapshot)
+}
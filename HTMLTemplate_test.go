@@ -0,0 +1,125 @@
+package stats_test
+
+import (
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aerogo/stats"
+)
+
+// renderHTML requests the HTML dashboard from the collector's main route.
+func renderHTML(t *testing.T, collector *stats.Collector) string {
+	t.Helper()
+	request := httptest.NewRequest(http.MethodGet, stats.DefaultPath, nil)
+	request.Header.Set("Accept", "text/html")
+	response := httptest.NewRecorder()
+	collector.ServeHTTP(response, request)
+
+	if response.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", response.Code, response.Body.String())
+	}
+
+	return response.Body.String()
+}
+
+func TestEndpointsAfterRender(t *testing.T) {
+	collector := stats.NewCollector()
+	collector.Track("/", 0)
+
+	if _, err := collector.Endpoints(); err != nil {
+		t.Fatal(err)
+	}
+
+	renderHTML(t, collector)
+
+	if _, err := collector.Endpoints(); err != nil {
+		t.Fatal(err)
+	}
+
+	renderHTML(t, collector)
+}
+
+func TestDefaultTemplateSharedByCollectors(t *testing.T) {
+	first := stats.NewCollector(stats.WithTimeUnit(time.Millisecond))
+	first.Track("/", 0)
+	html := renderHTML(t, first)
+
+	if !strings.Contains(html, "ms") {
+		t.Errorf("durations are not formatted:\n%s", html)
+	}
+
+	second := stats.NewCollector()
+
+	if _, err := second.Endpoints(); err != nil {
+		t.Fatal(err)
+	}
+
+	renderHTML(t, second)
+}
+
+func TestCustomTemplate(t *testing.T) {
+	tmpl := template.Must(template.New("custom").Funcs(stats.TemplateFuncs).Parse(
+		`{{range .Routes.Popular}}{{.Route}}: {{duration .ResponseTimeMs}}{{end}}`,
+	))
+
+	collector := stats.NewCollector(stats.WithHTMLTemplate(tmpl), stats.WithTimeUnit(time.Millisecond))
+	collector.Track("/", 15*time.Millisecond)
+
+	if html := renderHTML(t, collector); html != "/: 15 ms" {
+		t.Errorf("unexpected output of the custom template: %q", html)
+	}
+}
+
+func TestTemplateFileParseError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stats.html")
+
+	if err := os.WriteFile(path, []byte("{{.Routes"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	collector := stats.NewCollector(stats.WithHTMLTemplateFile(path, false))
+	collector.Track("/", 0)
+
+	if _, err := collector.Endpoints(); err == nil {
+		t.Error("no error for a broken template file")
+	}
+
+	if html := renderHTML(t, collector); !strings.Contains(html, "<h2>Routes</h2>") {
+		t.Errorf("the built-in dashboard is not used:\n%s", html)
+	}
+}
+
+func TestTemplateFileReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stats.html")
+
+	if err := os.WriteFile(path, []byte("before"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	collector := stats.NewCollector(stats.WithHTMLTemplateFile(path, true))
+
+	if html := renderHTML(t, collector); html != "before" {
+		t.Fatalf("unexpected output: %q", html)
+	}
+
+	if err := os.WriteFile(path, []byte("after"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Make sure the change is visible on file systems with a coarse modification time
+	later := time.Now().Add(time.Minute)
+
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+
+	if html := renderHTML(t, collector); html != "after" {
+		t.Errorf("the template was not reloaded: %q", html)
+	}
+}
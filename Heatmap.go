@@ -0,0 +1,114 @@
+package stats
+
+import (
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// WithHeatmapLocation sets the time zone of the hour-of-day heatmap, the local time zone by default.
+func WithHeatmapLocation(location *time.Location) Option {
+	return func(stats *Collector) {
+		if location != nil {
+			stats.heatmap.location = location
+		}
+	}
+}
+
+// WithWeeklyHeatmap includes the day-of-week by hour-of-day matrix of the heatmap in the snapshot.
+// The heatmap endpoint always includes it.
+func WithWeeklyHeatmap() Option {
+	return func(stats *Collector) {
+		stats.heatmap.weekly = true
+	}
+}
+
+// heatmap accumulates the requests per hour of the week over the whole lifetime.
+type heatmap struct {
+	location *time.Location
+	weekly   bool
+	cells    [7][24]heatmapCounters
+}
+
+// heatmapCounters contains the counters of a single hour of the week.
+type heatmapCounters struct {
+	requestCount uint64
+	responseTime uint64
+}
+
+// Heatmap shows the traffic shape over the day, accumulated across all days.
+// Hours are indexed by the hour of the day and Weekdays by the day of the week starting with Sunday.
+type Heatmap struct {
+	Timezone string
+	Hours    []HeatmapCell
+	Weekdays [][]HeatmapCell `json:",omitempty"`
+}
+
+// HeatmapCell contains the number of requests in an hour and their average response time.
+type HeatmapCell struct {
+	Requests       uint64
+	ResponseTimeMs float64
+}
+
+// record adds a finished request to the hour of the week it was made in.
+func (heatmap *heatmap) record(now time.Time, record *RequestRecord) {
+	local := now.In(heatmap.location)
+	cell := &heatmap.cells[local.Weekday()][local.Hour()]
+	atomic.AddUint64(&cell.requestCount, 1)
+	atomic.AddUint64(&cell.responseTime, uint64(record.Duration))
+}
+
+// Heatmap returns the accumulated hours of the day, optionally with the full week.
+func (heatmap *heatmap) Heatmap(weekly bool) *Heatmap {
+	var hours [24]heatmapCounters
+	exported := &Heatmap{
+		Timezone: heatmap.location.String(),
+		Hours:    make([]HeatmapCell, 24),
+	}
+
+	if weekly {
+		exported.Weekdays = make([][]HeatmapCell, 7)
+	}
+
+	for day := range heatmap.cells {
+		if weekly {
+			exported.Weekdays[day] = make([]HeatmapCell, 24)
+		}
+
+		for hour := range heatmap.cells[day] {
+			cell := &heatmap.cells[day][hour]
+			requestCount := atomic.LoadUint64(&cell.requestCount)
+			responseTime := atomic.LoadUint64(&cell.responseTime)
+			hours[hour].requestCount += requestCount
+			hours[hour].responseTime += responseTime
+
+			if weekly {
+				exported.Weekdays[day][hour] = newHeatmapCell(requestCount, responseTime)
+			}
+		}
+	}
+
+	for hour := range hours {
+		exported.Hours[hour] = newHeatmapCell(hours[hour].requestCount, hours[hour].responseTime)
+	}
+
+	return exported
+}
+
+// newHeatmapCell calculates the average response time of a cell.
+func newHeatmapCell(requestCount uint64, responseTime uint64) HeatmapCell {
+	cell := HeatmapCell{
+		Requests: requestCount,
+	}
+
+	if requestCount > 0 {
+		cell.ResponseTimeMs = roundMilliseconds(time.Duration(responseTime / requestCount))
+	}
+
+	return cell
+}
+
+// showHeatmap serves the heatmap including the day-of-week matrix as JSON.
+func (stats *Collector) showHeatmap(response http.ResponseWriter, request *http.Request) {
+	writeJSON(response, stats.heatmap.Heatmap(true))
+}
@@ -0,0 +1,168 @@
+package stats
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// History keeps rolling request statistics in fixed time intervals,
+// e.g. 24 hourly buckets or 60 buckets of one minute.
+type History struct {
+	interval int64
+	buckets  []historyBucket
+	mutex    sync.Mutex
+}
+
+// historyBucket contains the counters for a single interval.
+type historyBucket struct {
+	index        int64
+	requestCount uint64
+	errorCount   uint64
+	responseTime uint64
+}
+
+// HistoryBucket is the exported view of a single interval.
+// The response time is the average in milliseconds.
+type HistoryBucket struct {
+	Start          time.Time
+	Requests       uint64
+	Errors         uint64
+	ResponseTimeMs float64
+	Partial        bool
+}
+
+// NewHistory creates a history of count intervals.
+func NewHistory(interval time.Duration, count int) *History {
+	return &History{
+		interval: int64(interval / time.Second),
+		buckets:  make([]historyBucket, count),
+	}
+}
+
+// record adds a finished request to the bucket of the current interval.
+func (history *History) record(now time.Time, record *RequestRecord) {
+	history.count(now, record.Duration, record.failed())
+}
+
+// count adds a request with the given duration to the bucket of the current interval.
+func (history *History) count(now time.Time, duration time.Duration, failed bool) {
+	bucket := history.bucket(now.Unix() / history.interval)
+
+	atomic.AddUint64(&bucket.requestCount, 1)
+	atomic.AddUint64(&bucket.responseTime, uint64(duration))
+
+	if failed {
+		atomic.AddUint64(&bucket.errorCount, 1)
+	}
+}
+
+// bucket returns the bucket for the given interval index, rotating it if it contains older data.
+func (history *History) bucket(index int64) *historyBucket {
+	bucket := &history.buckets[index%int64(len(history.buckets))]
+
+	if atomic.LoadInt64(&bucket.index) == index {
+		return bucket
+	}
+
+	history.mutex.Lock()
+	defer history.mutex.Unlock()
+
+	current := atomic.LoadInt64(&bucket.index)
+
+	switch {
+	case current < index:
+		atomic.StoreUint64(&bucket.requestCount, 0)
+		atomic.StoreUint64(&bucket.errorCount, 0)
+		atomic.StoreUint64(&bucket.responseTime, 0)
+		atomic.StoreInt64(&bucket.index, index)
+
+	case current > index:
+		// The clock went backwards: never overwrite newer data,
+		// count the request in the newest bucket instead.
+		return history.newest()
+	}
+
+	return bucket
+}
+
+// reset clears all intervals.
+func (history *History) reset() {
+	history.mutex.Lock()
+	defer history.mutex.Unlock()
+
+	for i := range history.buckets {
+		bucket := &history.buckets[i]
+		atomic.StoreInt64(&bucket.index, 0)
+		atomic.StoreUint64(&bucket.requestCount, 0)
+		atomic.StoreUint64(&bucket.errorCount, 0)
+		atomic.StoreUint64(&bucket.responseTime, 0)
+	}
+}
+
+// newest returns the bucket with the most recent interval.
+func (history *History) newest() *historyBucket {
+	newest := &history.buckets[0]
+
+	for i := range history.buckets {
+		if atomic.LoadInt64(&history.buckets[i].index) > atomic.LoadInt64(&newest.index) {
+			newest = &history.buckets[i]
+		}
+	}
+
+	return newest
+}
+
+// Buckets returns all intervals in chronological order, the last one being the current interval.
+func (history *History) Buckets(now time.Time) []HistoryBucket {
+	count := int64(len(history.buckets))
+	currentIndex := now.Unix() / history.interval
+	buckets := make([]HistoryBucket, 0, count)
+
+	for index := currentIndex - count + 1; index <= currentIndex; index++ {
+		bucket := &history.buckets[index%count]
+		exported := HistoryBucket{
+			Start:   time.Unix(index*history.interval, 0),
+			Partial: index == currentIndex,
+		}
+
+		if atomic.LoadInt64(&bucket.index) == index {
+			exported.Requests = atomic.LoadUint64(&bucket.requestCount)
+			exported.Errors = atomic.LoadUint64(&bucket.errorCount)
+
+			if exported.Requests > 0 {
+				exported.ResponseTimeMs = float64(atomic.LoadUint64(&bucket.responseTime)) / float64(exported.Requests) / float64(time.Millisecond)
+			}
+		}
+
+		buckets = append(buckets, exported)
+	}
+
+	return buckets
+}
+
+// window returns the number of requests, errors and the total response time
+// in the intervals covering the given duration up to now.
+func (history *History) window(now time.Time, duration time.Duration) (requests uint64, errors uint64, responseTime uint64) {
+	count := int64(len(history.buckets))
+	currentIndex := now.Unix() / history.interval
+	intervals := (int64(duration/time.Second) + history.interval - 1) / history.interval
+
+	if intervals > count {
+		intervals = count
+	}
+
+	for index := currentIndex - intervals + 1; index <= currentIndex; index++ {
+		bucket := &history.buckets[index%count]
+
+		if atomic.LoadInt64(&bucket.index) != index {
+			continue
+		}
+
+		requests += atomic.LoadUint64(&bucket.requestCount)
+		errors += atomic.LoadUint64(&bucket.errorCount)
+		responseTime += atomic.LoadUint64(&bucket.responseTime)
+	}
+
+	return requests, errors, responseTime
+}
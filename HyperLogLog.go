@@ -0,0 +1,109 @@
+package stats
+
+import (
+	"errors"
+	"math"
+	"math/bits"
+	"sync"
+)
+
+// hyperLogLogPrecision is the number of hash bits selecting a register, 2^12 registers take 4 KB.
+const hyperLogLogPrecision = 12
+
+// hyperLogLogRegisters is the number of registers of a sketch.
+const hyperLogLogRegisters = 1 << hyperLogLogPrecision
+
+// HyperLogLogError is the relative standard error of the estimates of a sketch.
+var HyperLogLogError = 1.04 / math.Sqrt(hyperLogLogRegisters)
+
+// HyperLogLog estimates the number of distinct hashes added to it in a fixed 4 KB.
+// Sketches can be merged, e.g. to combine the visitors of several instances,
+// as long as they were filled with the same hash function.
+type HyperLogLog struct {
+	mutex     sync.Mutex
+	registers [hyperLogLogRegisters]uint8
+}
+
+// NewHyperLogLog creates an empty sketch.
+func NewHyperLogLog() *HyperLogLog {
+	return &HyperLogLog{}
+}
+
+// Add adds a 64-bit hash to the sketch.
+// The hash must be uniformly distributed, adding the same hash again has no effect.
+func (sketch *HyperLogLog) Add(hash uint64) {
+	index := hash >> (64 - hyperLogLogPrecision)
+	rank := uint8(bits.LeadingZeros64(hash<<hyperLogLogPrecision|1<<(hyperLogLogPrecision-1)) + 1)
+
+	sketch.mutex.Lock()
+
+	if rank > sketch.registers[index] {
+		sketch.registers[index] = rank
+	}
+
+	sketch.mutex.Unlock()
+}
+
+// Merge adds all hashes of another sketch to this sketch.
+func (sketch *HyperLogLog) Merge(other *HyperLogLog) {
+	registers := other.snapshot()
+	sketch.mutex.Lock()
+
+	for index, rank := range registers {
+		if rank > sketch.registers[index] {
+			sketch.registers[index] = rank
+		}
+	}
+
+	sketch.mutex.Unlock()
+}
+
+// Estimate returns the estimated number of distinct hashes.
+// Small numbers are counted via linear counting, which is more accurate in that range.
+func (sketch *HyperLogLog) Estimate() uint64 {
+	registers := sketch.snapshot()
+	sum := 0.0
+	empty := 0
+
+	for _, rank := range registers {
+		sum += 1 / float64(uint64(1)<<rank)
+
+		if rank == 0 {
+			empty++
+		}
+	}
+
+	m := float64(hyperLogLogRegisters)
+	estimate := 0.7213 / (1 + 1.079/m) * m * m / sum
+
+	if estimate <= 2.5*m && empty > 0 {
+		estimate = m * math.Log(m/float64(empty))
+	}
+
+	return uint64(estimate + 0.5)
+}
+
+// MarshalBinary encodes the registers of the sketch.
+func (sketch *HyperLogLog) MarshalBinary() ([]byte, error) {
+	registers := sketch.snapshot()
+	return registers[:], nil
+}
+
+// UnmarshalBinary replaces the registers of the sketch with encoded ones.
+func (sketch *HyperLogLog) UnmarshalBinary(data []byte) error {
+	if len(data) != hyperLogLogRegisters {
+		return errors.New("Invalid HyperLogLog sketch size")
+	}
+
+	sketch.mutex.Lock()
+	copy(sketch.registers[:], data)
+	sketch.mutex.Unlock()
+	return nil
+}
+
+// snapshot returns a copy of the registers.
+func (sketch *HyperLogLog) snapshot() [hyperLogLogRegisters]uint8 {
+	sketch.mutex.Lock()
+	defer sketch.mutex.Unlock()
+	return sketch.registers
+}
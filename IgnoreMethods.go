@@ -0,0 +1,30 @@
+package stats
+
+import (
+	"strings"
+	"sync/atomic"
+)
+
+// WithIgnoreMethods excludes requests with the given methods, e.g. "OPTIONS" and "HEAD",
+// from all statistics. They are only counted in the IgnoredRequests of the app section.
+func WithIgnoreMethods(methods ...string) Option {
+	return func(stats *Collector) {
+		if stats.ignoredMethods == nil {
+			stats.ignoredMethods = make(map[string]bool, len(methods))
+		}
+
+		for _, method := range methods {
+			stats.ignoredMethods[strings.ToUpper(method)] = true
+		}
+	}
+}
+
+// ignore tells you whether the request is excluded because of its method and counts it if so.
+func (stats *Collector) ignore(record *RequestRecord) bool {
+	if !stats.ignoredMethods[record.Method] {
+		return false
+	}
+
+	atomic.AddUint64(&stats.ignoredCount, 1)
+	return true
+}
@@ -0,0 +1,151 @@
+package stats
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// Names of the sub-endpoints that can be disabled via WithDisabledEndpoints.
+const (
+	EndpointRoute     = "route"
+	EndpointRoutes    = "routes"
+	EndpointSystem    = "system"
+	EndpointHeatmap   = "heatmap"
+	EndpointSparkline = "sparkline"
+	EndpointErrors    = "errors"
+	EndpointSlow      = "slow"
+	EndpointMetrics   = "metrics"
+	EndpointAssets    = "assets"
+	EndpointDashboard = "dashboard"
+	EndpointLive      = "live"
+	EndpointReset     = "reset"
+	EndpointSnapshot  = "snapshot"
+)
+
+// installed contains the prefixes already registered on each ServeMux.
+var installed = struct {
+	sync.Mutex
+	prefixes map[*http.ServeMux]map[string]bool
+}{
+	prefixes: map[*http.ServeMux]map[string]bool{},
+}
+
+// WithDisabledEndpoints removes the named sub-endpoints from the endpoint tree, e.g. EndpointMetrics.
+// The main route under the path itself is always served.
+func WithDisabledEndpoints(names ...string) Option {
+	return func(stats *Collector) {
+		if stats.disabledEndpoints == nil {
+			stats.disabledEndpoints = make(map[string]bool, len(names))
+		}
+
+		for _, name := range names {
+			stats.disabledEndpoints[name] = true
+		}
+	}
+}
+
+// WithEndpointMiddleware wraps every route of the endpoint tree with the middleware,
+// e.g. for authentication or an IP allowlist covering the whole tree.
+// Multiple middlewares are applied in the given order, the first one being the outermost.
+func WithEndpointMiddleware(middleware func(http.Handler) http.Handler) Option {
+	return func(stats *Collector) {
+		stats.middlewares = append(stats.middlewares, middleware)
+	}
+}
+
+// Install registers all routes of the endpoint tree under the configured path on the mux
+// and returns the registered patterns.
+// Installing the same path on the same mux twice returns an error instead of registering the routes again.
+func (stats *Collector) Install(mux *http.ServeMux) ([]string, error) {
+	if stats.path == "" {
+		return nil, errors.New("No statistics path configured")
+	}
+
+	installed.Lock()
+	defer installed.Unlock()
+
+	if installed.prefixes[mux][stats.path] {
+		return nil, errors.New("Statistics already installed under " + stats.path)
+	}
+
+	endpoints, err := stats.Endpoints()
+	handlers := map[string]map[string]http.HandlerFunc{}
+	var patterns []string
+
+	for _, endpoint := range endpoints {
+		pattern := endpoint.Path
+
+		// ServeMux matches subtrees by a trailing slash instead of route parameters
+		if index := strings.Index(pattern, "/:"); index != -1 {
+			pattern = pattern[:index+1]
+		}
+
+		if handlers[pattern] == nil {
+			handlers[pattern] = map[string]http.HandlerFunc{}
+			patterns = append(patterns, pattern)
+		}
+
+		handlers[pattern][endpoint.Method] = endpoint.Handler
+	}
+
+	for _, pattern := range patterns {
+		mux.Handle(pattern, methodHandler(handlers[pattern]))
+	}
+
+	if installed.prefixes[mux] == nil {
+		installed.prefixes[mux] = map[string]bool{}
+	}
+
+	installed.prefixes[mux][stats.path] = true
+	return patterns, err
+}
+
+// methodHandler dispatches requests to the handler of their method.
+func methodHandler(handlers map[string]http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		handler, exists := handlers[request.Method]
+
+		if !exists {
+			http.Error(response, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		handler(response, request)
+	})
+}
+
+// endpointName returns the name of the sub-endpoint a path of the endpoint tree belongs to,
+// an empty string for the main route.
+func (stats *Collector) endpointName(path string) string {
+	name := strings.TrimPrefix(strings.TrimPrefix(path, stats.path), "/")
+
+	if index := strings.Index(name, "/"); index != -1 {
+		name = name[:index]
+	}
+
+	return name
+}
+
+// Guard wraps a handler outside of the endpoint tree with the same rate limit and endpoint middlewares,
+// e.g. to protect debugging routes with the same authentication.
+func (stats *Collector) Guard(handler http.HandlerFunc) http.HandlerFunc {
+	return stats.guard(handler).ServeHTTP
+}
+
+// guard applies the rate limit and the endpoint middlewares to a handler of the endpoint tree.
+func (stats *Collector) guard(handler http.HandlerFunc) http.Handler {
+	return stats.authorize(stats.limit(handler))
+}
+
+// authorize applies the endpoint middlewares to a handler.
+func (stats *Collector) authorize(handler http.Handler) http.Handler {
+	guarded := handler
+
+	for i := len(stats.middlewares) - 1; i >= 0; i-- {
+		guarded = stats.middlewares[i](guarded)
+	}
+
+	return guarded
+}
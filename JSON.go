@@ -0,0 +1,48 @@
+package stats
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+)
+
+// writeJSON encodes the value directly into the response using the pooled encoder buffers
+// instead of allocating a separate byte slice for every request.
+// Errors before the first byte result in a 500 response,
+// later ones abort the connection because the status has already been sent.
+func writeJSON(response http.ResponseWriter, value interface{}) {
+	response.Header().Set("Content-Type", "application/json")
+	writer := &countingWriter{Writer: response}
+	err := json.NewEncoder(writer).Encode(value)
+
+	if err == nil {
+		return
+	}
+
+	if writer.count == 0 {
+		http.Error(response, "Error serializing to JSON", http.StatusInternalServerError)
+		return
+	}
+
+	panic(http.ErrAbortHandler)
+}
+
+// countingWriter counts the bytes written to the underlying writer.
+type countingWriter struct {
+	io.Writer
+	count int
+}
+
+// Write writes to the underlying writer.
+func (writer *countingWriter) Write(data []byte) (int, error) {
+	n, err := writer.Writer.Write(data)
+	writer.count += n
+	return n, err
+}
+
+// MarshalJSON encodes a snapshot of all default sections.
+// The result is the document the statistics endpoint serves without query parameters,
+// which ends with an additional newline.
+func (stats *Collector) MarshalJSON() ([]byte, error) {
+	return json.Marshal(stats.Snapshot())
+}
@@ -0,0 +1,148 @@
+package stats_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aerogo/stats"
+	"github.com/aerogo/stats/testutil"
+)
+
+// newFakeCollector creates a collector with a fake clock and system and a few recorded requests.
+func newFakeCollector() *stats.Collector {
+	clock := testutil.NewFakeClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	collector := stats.NewCollector(
+		stats.WithClock(clock),
+		stats.WithStartTime(clock.Now()),
+		stats.WithSystemProvider(&testutil.FakeSystemProvider{Up: time.Hour}),
+	)
+
+	collector.Track("/", 15*time.Millisecond)
+	collector.Record(stats.RequestRecord{Route: "/users/:id", StatusCode: http.StatusInternalServerError, Duration: 2 * time.Second})
+	clock.Advance(time.Minute)
+	return collector
+}
+
+// served returns the JSON document served by the statistics endpoint.
+func served(t *testing.T, collector *stats.Collector, query string) string {
+	t.Helper()
+	request := httptest.NewRequest(http.MethodGet, stats.DefaultPath+"?format=json"+query, nil)
+	response := httptest.NewRecorder()
+	collector.ServeHTTP(response, request)
+
+	if response.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", response.Code, response.Body.String())
+	}
+
+	return response.Body.String()
+}
+
+// TestSnapshotMarshalMatchesEndpoint compares the served document with a marshaled snapshot of the same data.
+// The App section is left out because the runtime memory statistics change between both calls.
+func TestSnapshotMarshalMatchesEndpoint(t *testing.T) {
+	collector := newFakeCollector()
+	var sections []stats.Section
+	var names []string
+
+	for _, section := range stats.AllSections {
+		if section != stats.SectionApp {
+			sections = append(sections, section)
+			names = append(names, string(section))
+		}
+	}
+
+	document := served(t, collector, "&sections="+strings.Join(names, ","))
+	data, err := json.Marshal(collector.SnapshotSections(sections...))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(data)+"\n" != document {
+		t.Errorf("the marshaled snapshot differs from the endpoint:\n%s\n%s", data, document)
+	}
+}
+
+func TestCollectorMarshalJSON(t *testing.T) {
+	collector := newFakeCollector()
+	document := served(t, collector, "")
+	data, err := json.Marshal(collector)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var expected, marshaled map[string]interface{}
+
+	if err := json.Unmarshal([]byte(document), &expected); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := json.Unmarshal(data, &marshaled); err != nil {
+		t.Fatal(err)
+	}
+
+	if expected["App"] == nil || marshaled["App"] == nil {
+		t.Fatal("the App section is missing")
+	}
+
+	delete(expected, "App")
+	delete(marshaled, "App")
+
+	if !reflect.DeepEqual(expected, marshaled) {
+		t.Errorf("the marshaled collector differs from the endpoint:\n%s\n%s", data, document)
+	}
+}
+
+// newBenchmarkCollector creates a collector with hundreds of routes.
+func newBenchmarkCollector() *stats.Collector {
+	collector := stats.NewCollector()
+
+	for i := 0; i < 500; i++ {
+		collector.Track("/route/"+strconv.Itoa(i), time.Duration(i)*time.Millisecond)
+	}
+
+	return collector
+}
+
+// BenchmarkServeJSON measures the endpoint, which encodes the snapshot directly into the response.
+func BenchmarkServeJSON(b *testing.B) {
+	collector := newBenchmarkCollector()
+	request := httptest.NewRequest(http.MethodGet, stats.DefaultPath+"?format=json&sections=routes", nil)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		collector.ServeHTTP(discardResponse{}, request)
+	}
+}
+
+// BenchmarkMarshalJSON measures the previous approach of marshaling the snapshot into a byte slice first.
+func BenchmarkMarshalJSON(b *testing.B) {
+	collector := newBenchmarkCollector()
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		data, err := json.Marshal(collector.SnapshotSections(stats.SectionRoutes))
+
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		discardResponse{}.Write(data)
+	}
+}
+
+// discardResponse is a response writer that drops the body, so that only the allocations of the rendering are measured.
+type discardResponse struct{}
+
+func (discardResponse) Header() http.Header            { return http.Header{} }
+func (discardResponse) Write(data []byte) (int, error) { return len(data), nil }
+func (discardResponse) WriteHeader(int)                {}
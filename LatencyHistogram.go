@@ -0,0 +1,111 @@
+package stats
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultLatencyBuckets are the upper bounds of the latency histogram buckets,
+// matching the defaults of the Prometheus client libraries.
+var DefaultLatencyBuckets = []time.Duration{
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+	10 * time.Second,
+}
+
+// WithLatencyBuckets sets the upper bounds of the per-route latency histogram buckets in ascending order.
+// The percentiles are interpolated from these buckets unless a distribution is set via WithDistribution.
+func WithLatencyBuckets(bounds ...time.Duration) Option {
+	return func(stats *Collector) {
+		if len(bounds) > 0 {
+			stats.latencyBuckets = bounds
+		}
+	}
+}
+
+// latencyHistogram counts response times in fixed buckets.
+// It keeps the most recent exemplar of each bucket, the last bucket has no upper bound.
+type latencyHistogram struct {
+	bounds    []time.Duration
+	counts    []uint64
+	sum       uint64
+	exemplars []exemplar
+	mutex     sync.Mutex
+}
+
+// exemplar is a single request that was counted in a histogram bucket.
+type exemplar struct {
+	traceID  string
+	duration time.Duration
+	time     time.Time
+}
+
+// newLatencyHistogram creates a histogram with the given bucket bounds.
+func newLatencyHistogram(bounds []time.Duration) *latencyHistogram {
+	return &latencyHistogram{
+		bounds:    bounds,
+		counts:    make([]uint64, len(bounds)+1),
+		exemplars: make([]exemplar, len(bounds)+1),
+	}
+}
+
+// record counts a response time and keeps it as the exemplar of its bucket if it has a trace ID.
+func (histogram *latencyHistogram) record(now time.Time, duration time.Duration, traceID string) {
+	bucket := len(histogram.bounds)
+
+	for i, bound := range histogram.bounds {
+		if duration <= bound {
+			bucket = i
+			break
+		}
+	}
+
+	atomic.AddUint64(&histogram.counts[bucket], 1)
+	atomic.AddUint64(&histogram.sum, uint64(duration))
+
+	if traceID == "" {
+		return
+	}
+
+	histogram.mutex.Lock()
+	histogram.exemplars[bucket] = exemplar{
+		traceID:  traceID,
+		duration: duration,
+		time:     now,
+	}
+	histogram.mutex.Unlock()
+}
+
+// load returns the count of each bucket, the sum of all response times
+// and the exemplars recorded after the given time.
+func (histogram *latencyHistogram) load(since time.Time) ([]uint64, time.Duration, []exemplar) {
+	counts := make([]uint64, len(histogram.counts))
+
+	for i := range histogram.counts {
+		counts[i] = atomic.LoadUint64(&histogram.counts[i])
+	}
+
+	sum := time.Duration(atomic.LoadUint64(&histogram.sum))
+	exemplars := make([]exemplar, len(histogram.exemplars))
+
+	histogram.mutex.Lock()
+
+	for i, exemplar := range histogram.exemplars {
+		if exemplar.time.After(since) {
+			exemplars[i] = exemplar
+		}
+	}
+
+	histogram.mutex.Unlock()
+
+	return counts, sum, exemplars
+}
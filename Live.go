@@ -0,0 +1,252 @@
+package stats
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultLiveInterval is the interval at which the live stream pushes snapshots.
+const defaultLiveInterval = 2 * time.Second
+
+// websocketGUID is appended to the client key to compute the accept key of the handshake (RFC 6455).
+const websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// WebSocket opcodes
+const (
+	opcodeText  = 0x1
+	opcodeClose = 0x8
+	opcodePing  = 0x9
+	opcodePong  = 0xA
+)
+
+// maxControlFrame is the maximum payload of the frames read from clients, which only send control frames.
+const maxControlFrame = 125
+
+// liveWriteTimeout is the time a client has to receive a frame before the stream is closed.
+const liveWriteTimeout = 10 * time.Second
+
+// WithLiveInterval sets the interval at which the live stream pushes snapshots.
+// Non-positive intervals are ignored and keep the default of 2 seconds.
+func WithLiveInterval(interval time.Duration) Option {
+	return func(stats *Collector) {
+		if interval > 0 {
+			stats.liveInterval = interval
+		}
+	}
+}
+
+// showLive upgrades the request to a WebSocket and pushes a JSON snapshot as a text message
+// every live interval, until the client closes the connection or the collector is closed.
+// The "sections" query parameter and the route summary parameters work like on the main route.
+func (stats *Collector) showLive(response http.ResponseWriter, request *http.Request) {
+	query := request.URL.Query()
+	parameters, err := stats.routeParameters(query)
+
+	if err != nil {
+		http.Error(response, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	sections := AllSections
+
+	if list := query.Get("sections"); list != "" {
+		sections, err = parseSections(list)
+
+		if err != nil {
+			http.Error(response, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	key := request.Header.Get("Sec-WebSocket-Key")
+
+	if !headerContains(request.Header, "Connection", "upgrade") || !headerContains(request.Header, "Upgrade", "websocket") || key == "" {
+		response.Header().Set("Upgrade", "websocket")
+		http.Error(response, "WebSocket upgrade required", http.StatusUpgradeRequired)
+		return
+	}
+
+	// WebSockets are not subject to the same-origin policy, a page of another site could read the stream
+	if origin := request.Header.Get("Origin"); origin != "" && !sameOrigin(origin, request.Host) {
+		http.Error(response, "Cross-origin WebSocket connections are not allowed", http.StatusForbidden)
+		return
+	}
+
+	if request.Header.Get("Sec-WebSocket-Version") != "13" {
+		response.Header().Set("Sec-WebSocket-Version", "13")
+		http.Error(response, "Unsupported WebSocket version", http.StatusBadRequest)
+		return
+	}
+
+	hijacker, ok := response.(http.Hijacker)
+
+	if !ok {
+		http.Error(response, "WebSocket not supported by the server", http.StatusInternalServerError)
+		return
+	}
+
+	conn, buffer, err := hijacker.Hijack()
+
+	if err != nil {
+		http.Error(response, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	defer conn.Close()
+
+	accept := sha1.Sum([]byte(key + websocketGUID))
+	buffer.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Accept: ")
+	buffer.WriteString(base64.StdEncoding.EncodeToString(accept[:]))
+	buffer.WriteString("\r\n\r\n")
+
+	if buffer.Flush() != nil {
+		return
+	}
+
+	socket := &liveSocket{conn: conn}
+	closed := make(chan struct{})
+
+	go func() {
+		defer close(closed)
+		socket.readControlFrames(buffer.Reader)
+	}()
+
+	ticker := stats.clock.NewTicker(stats.liveInterval)
+	defer ticker.Stop()
+
+	for {
+		message, err := json.Marshal(stats.snapshot(parameters, newSectionSet(sections)))
+
+		if err != nil || socket.write(opcodeText, message) != nil {
+			return
+		}
+
+		select {
+		case <-closed:
+			return
+		case <-stats.done:
+			socket.write(opcodeClose, nil)
+			return
+		case <-ticker.C():
+		}
+	}
+}
+
+// liveSocket is the server side of a live stream WebSocket.
+// Snapshots and the replies to control frames are written from different goroutines.
+type liveSocket struct {
+	conn  net.Conn
+	mutex sync.Mutex
+}
+
+// write sends an unmasked, unfragmented frame.
+// A client that doesn't receive it within the write timeout fails the write and ends the stream.
+func (socket *liveSocket) write(opcode byte, payload []byte) error {
+	header := []byte{0x80 | opcode, 0}
+
+	switch {
+	case len(payload) < 126:
+		header[1] = byte(len(payload))
+	case len(payload) <= 0xFFFF:
+		header[1] = 126
+		header = binary.BigEndian.AppendUint16(header, uint16(len(payload)))
+	default:
+		header[1] = 127
+		header = binary.BigEndian.AppendUint64(header, uint64(len(payload)))
+	}
+
+	socket.mutex.Lock()
+	defer socket.mutex.Unlock()
+
+	if err := socket.conn.SetWriteDeadline(time.Now().Add(liveWriteTimeout)); err != nil {
+		return err
+	}
+
+	_, err := (&net.Buffers{header, payload}).WriteTo(socket.conn)
+	return err
+}
+
+// readControlFrames answers pings and returns when the client closes the connection.
+// Clients are not expected to send data, any frame other than a control frame ends the stream.
+func (socket *liveSocket) readControlFrames(reader *bufio.Reader) {
+	for {
+		opcode, payload, err := readClientFrame(reader)
+
+		if err != nil {
+			return
+		}
+
+		switch opcode {
+		case opcodePing:
+			socket.write(opcodePong, payload)
+		case opcodePong:
+		case opcodeClose:
+			socket.write(opcodeClose, payload)
+			return
+		default:
+			return
+		}
+	}
+}
+
+// readClientFrame reads a masked frame with a payload of at most maxControlFrame bytes.
+func readClientFrame(reader *bufio.Reader) (opcode byte, payload []byte, err error) {
+	var header [2]byte
+
+	if _, err := io.ReadFull(reader, header[:]); err != nil {
+		return 0, nil, err
+	}
+
+	length := int(header[1] & 0x7F)
+
+	if header[1]&0x80 == 0 || length > maxControlFrame {
+		return 0, nil, errors.New("Invalid WebSocket frame")
+	}
+
+	var mask [4]byte
+	payload = make([]byte, length)
+
+	if _, err := io.ReadFull(reader, mask[:]); err != nil {
+		return 0, nil, err
+	}
+
+	if _, err := io.ReadFull(reader, payload); err != nil {
+		return 0, nil, err
+	}
+
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+
+	return header[0] & 0x0F, payload, nil
+}
+
+// headerContains tells whether the comma separated header values contain the token, ignoring case.
+func headerContains(header http.Header, name string, token string) bool {
+	for _, value := range header.Values(name) {
+		for _, field := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(field), token) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// sameOrigin tells whether the Origin header of a request refers to the requested host.
+func sameOrigin(origin string, host string) bool {
+	parsed, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(parsed.Host, host)
+}
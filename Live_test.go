@@ -0,0 +1,114 @@
+package stats_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aerogo/stats"
+)
+
+func TestLiveHandshakeAndFrame(t *testing.T) {
+	collector := stats.NewCollector()
+	defer collector.Close(context.Background())
+	collector.Track("/", time.Millisecond)
+
+	server := httptest.NewServer(installMux(t, collector))
+	defer server.Close()
+
+	conn, err := net.Dial("tcp", server.Listener.Addr().String())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	host := server.Listener.Addr().String()
+
+	io.WriteString(conn, "GET "+stats.DefaultPath+"/live?sections=app HTTP/1.1\r\n"+
+		"Host: "+host+"\r\n"+
+		"Origin: http://"+host+"\r\n"+
+		"Connection: Upgrade\r\n"+
+		"Upgrade: websocket\r\n"+
+		"Sec-WebSocket-Version: 13\r\n"+
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n\r\n")
+
+	reader := bufio.NewReader(conn)
+	response, err := http.ReadResponse(reader, nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The accept key of the sample handshake in RFC 6455
+	if response.StatusCode != http.StatusSwitchingProtocols || response.Header.Get("Sec-WebSocket-Accept") != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Fatalf("handshake: status %d, accept key %q", response.StatusCode, response.Header.Get("Sec-WebSocket-Accept"))
+	}
+
+	var header [2]byte
+
+	if _, err := io.ReadFull(reader, header[:]); err != nil {
+		t.Fatal(err)
+	}
+
+	if header[0] != 0x81 || header[1]&0x80 != 0 {
+		t.Fatalf("frame header %x, expected an unmasked final text frame", header)
+	}
+
+	// The snapshot needs an extended payload length of 2 or 8 bytes
+	length := uint64(header[1])
+	extended := make([]byte, map[uint64]int{126: 2, 127: 8}[length])
+
+	if _, err := io.ReadFull(reader, extended); err != nil {
+		t.Fatal(err)
+	}
+
+	switch len(extended) {
+	case 2:
+		length = uint64(binary.BigEndian.Uint16(extended))
+	case 8:
+		length = binary.BigEndian.Uint64(extended)
+	}
+
+	payload := make([]byte, length)
+
+	if _, err := io.ReadFull(reader, payload); err != nil {
+		t.Fatal(err)
+	}
+
+	var snapshot stats.Snapshot
+
+	if err := json.Unmarshal(payload, &snapshot); err != nil {
+		t.Fatal(err)
+	}
+
+	if snapshot.App == nil || snapshot.App.Requests != 1 || snapshot.Routes != nil {
+		t.Errorf("the frame doesn't contain only the app section with one request: %s", payload)
+	}
+}
+
+func TestLiveRejectsCrossOrigin(t *testing.T) {
+	collector := stats.NewCollector()
+	defer collector.Close(context.Background())
+
+	request := httptest.NewRequest(http.MethodGet, stats.DefaultPath+"/live", nil)
+	request.Header.Set("Origin", "https://attacker.example")
+	request.Header.Set("Connection", "Upgrade")
+	request.Header.Set("Upgrade", "websocket")
+	request.Header.Set("Sec-WebSocket-Version", "13")
+	request.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+	response := httptest.NewRecorder()
+	installMux(t, collector).ServeHTTP(response, request)
+
+	if response.Code != http.StatusForbidden {
+		t.Errorf("cross-origin upgrade: status %d", response.Code)
+	}
+}
@@ -0,0 +1,249 @@
+package stats
+
+import (
+	"sort"
+	"sync/atomic"
+	"unsafe"
+)
+
+// Degradation is the step of the degradation ladder that keeps the collector within its memory budget.
+type Degradation uint32
+
+const (
+	// DegradationNone means the estimated footprint is well within the budget.
+	DegradationNone Degradation = iota
+
+	// DegradationHistograms drops the distributions of low-traffic routes and creates new routes without one.
+	DegradationHistograms
+
+	// DegradationSampling additionally feeds only every degradedSampleRate-th request into the
+	// remaining distributions and stops keeping request samples.
+	DegradationSampling
+
+	// DegradationRoutes additionally counts requests to new routes as OverflowRoute.
+	DegradationRoutes
+)
+
+// String returns the name of the degradation step.
+func (degradation Degradation) String() string {
+	switch degradation {
+	case DegradationHistograms:
+		return "histograms"
+	case DegradationSampling:
+		return "sampling"
+	case DegradationRoutes:
+		return "routes"
+	default:
+		return "none"
+	}
+}
+
+const (
+	// degradedSampleRate is the share of requests recorded in the distributions while sampling.
+	degradedSampleRate = 8
+
+	// defaultDistributionFootprint is assumed for custom distributions that don't report their size.
+	defaultDistributionFootprint = 1024
+
+	// Rough sizes of map entries including their keys and the map overhead.
+	mapEntryFootprint         = 64
+	seriesEntryFootprint      = 48
+	originCacheEntryFootprint = 112
+)
+
+// MemoryBudgetStats contains the estimated footprint of the collector and its degradation step.
+type MemoryBudgetStats struct {
+	BudgetBytes    uint64
+	FootprintBytes uint64
+	Degradation    string
+}
+
+// WithMemoryBudget limits the estimated memory footprint of the collector in bytes.
+// The footprint is estimated on every sample interval from the number of routes and their structures.
+// From 80% of the budget on, the distributions of low-traffic routes are dropped, from 90% on
+// the remaining distributions are only fed a sample of the requests, and from 100% on new routes
+// are counted as OverflowRoute. The steps are lifted again once the footprint has decreased,
+// dropped distributions are not restored.
+func WithMemoryBudget(bytes uint64) Option {
+	return func(stats *Collector) {
+		stats.memoryBudget = bytes
+	}
+}
+
+// footprinter is implemented by distributions that can estimate their own size.
+type footprinter interface {
+	footprint() uint64
+}
+
+// degradation returns the active degradation step.
+func (stats *Collector) degradation() Degradation {
+	return Degradation(atomic.LoadUint32(&stats.degradationLevel))
+}
+
+// takeSample tells you whether the request should be recorded in the distribution.
+func (stats *RouteStatistics) takeSample() bool {
+	if atomic.LoadUint32(&stats.sampled) == 0 {
+		return true
+	}
+
+	return atomic.AddUint64(&stats.sampleCounter, 1)%degradedSampleRate == 0
+}
+
+// enforceMemoryBudget estimates the footprint and moves along the degradation ladder.
+func (stats *Collector) enforceMemoryBudget() {
+	if stats.memoryBudget == 0 {
+		return
+	}
+
+	routes := stats.trackedRoutes()
+	footprint := stats.estimateFootprint(routes)
+	degradation := degradationFor(footprint, stats.memoryBudget)
+
+	atomic.StoreUint64(&stats.footprint, footprint)
+	atomic.StoreUint32(&stats.degradationLevel, uint32(degradation))
+
+	if degradation >= DegradationHistograms {
+		dropLowTrafficDistributions(routes)
+	}
+
+	sampled := uint32(0)
+
+	if degradation >= DegradationSampling {
+		sampled = 1
+	}
+
+	for _, route := range routes {
+		atomic.StoreUint32(&route.stats.sampled, sampled)
+	}
+}
+
+// degradationFor returns the degradation step for the footprint.
+func degradationFor(footprint uint64, budget uint64) Degradation {
+	switch {
+	case footprint >= budget:
+		return DegradationRoutes
+	case footprint >= budget/10*9:
+		return DegradationSampling
+	case footprint >= budget/10*8:
+		return DegradationHistograms
+	default:
+		return DegradationNone
+	}
+}
+
+// dropLowTrafficDistributions drops the distributions of the less requested half of the routes that still have one.
+func dropLowTrafficDistributions(routes []trackedRoute) {
+	var candidates []trackedRoute
+
+	for _, route := range routes {
+		if route.stats.Distribution() != nil {
+			candidates = append(candidates, route)
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		return atomic.LoadUint64(&candidates[i].stats.requestCount) < atomic.LoadUint64(&candidates[j].stats.requestCount)
+	})
+
+	for _, route := range candidates[:(len(candidates)+1)/2] {
+		route.stats.distribution.Store(distributionValue{})
+	}
+}
+
+// estimateFootprint estimates the memory used by the routes and the collector-wide structures.
+func (stats *Collector) estimateFootprint(routes []trackedRoute) uint64 {
+	footprint := uint64(unsafe.Sizeof(*stats))
+
+	for _, route := range routes {
+		footprint += uint64(len(route.path)) + mapEntryFootprint + route.stats.footprint()
+	}
+
+	if stats.visitors != nil {
+		sketches := uint64(1 + len(stats.visitors.routes))
+		footprint += 2 * sketches * uint64(unsafe.Sizeof(HyperLogLog{}))
+	}
+
+	if stats.origins != nil {
+		stats.origins.mutex.RLock()
+		footprint += uint64(len(stats.origins.counters)) * (mapEntryFootprint + uint64(unsafe.Sizeof(originCounters{})))
+		stats.origins.mutex.RUnlock()
+
+		stats.origins.cache.mutex.Lock()
+		footprint += uint64(stats.origins.cache.order.Len()) * originCacheEntryFootprint
+		stats.origins.cache.mutex.Unlock()
+	}
+
+	for _, objective := range stats.slos {
+		footprint += objective.minutes.footprint() + objective.hours.footprint()
+	}
+
+	return footprint
+}
+
+// footprint estimates the memory used by the route statistics.
+func (stats *RouteStatistics) footprint() uint64 {
+	footprint := uint64(unsafe.Sizeof(*stats)) + stats.minutes.footprint()
+
+	if stats.history != nil {
+		footprint += stats.history.footprint()
+	}
+
+	switch distribution := stats.Distribution().(type) {
+	case nil:
+	case footprinter:
+		footprint += distribution.footprint()
+	default:
+		footprint += defaultDistributionFootprint
+	}
+
+	stats.series.requests.Range(func(key interface{}, value interface{}) bool {
+		footprint += seriesEntryFootprint
+		return true
+	})
+
+	stats.series.latency.Range(func(key interface{}, value interface{}) bool {
+		footprint += seriesEntryFootprint + value.(*latencyHistogram).footprint()
+		return true
+	})
+
+	stats.segmentsMutex.Lock()
+	footprint += uint64(len(stats.segments)) * (mapEntryFootprint + uint64(unsafe.Sizeof(segmentStats{})))
+	stats.segmentsMutex.Unlock()
+
+	return footprint
+}
+
+// footprint estimates the memory used by the buckets of the history.
+func (history *History) footprint() uint64 {
+	return uint64(unsafe.Sizeof(*history)) + uint64(len(history.buckets))*uint64(unsafe.Sizeof(historyBucket{}))
+}
+
+// footprint estimates the memory used by the histogram.
+func (histogram *latencyHistogram) footprint() uint64 {
+	return uint64(unsafe.Sizeof(*histogram)) + uint64(len(histogram.counts))*(8+uint64(unsafe.Sizeof(exemplar{})))
+}
+
+// footprint estimates the memory used by the histogram.
+func (histogram *HDRHistogramDistribution) footprint() uint64 {
+	return uint64(unsafe.Sizeof(*histogram)) + uint64(len(histogram.counts))*8
+}
+
+// footprint estimates the memory used by the digest.
+func (digest *TDigestDistribution) footprint() uint64 {
+	digest.mutex.Lock()
+	defer digest.mutex.Unlock()
+	return uint64(unsafe.Sizeof(*digest)) + uint64(cap(digest.centroids))*uint64(unsafe.Sizeof(centroid{}))
+}
+
+// memoryBudgetStats returns the estimated footprint, nil if no budget is set.
+func (stats *Collector) memoryBudgetStats() *MemoryBudgetStats {
+	if stats.memoryBudget == 0 {
+		return nil
+	}
+
+	return &MemoryBudgetStats{
+		BudgetBytes:    stats.memoryBudget,
+		FootprintBytes: atomic.LoadUint64(&stats.footprint),
+		Degradation:    stats.degradation().String(),
+	}
+}
@@ -0,0 +1,123 @@
+package stats_test
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/aerogo/stats"
+	"github.com/aerogo/stats/testutil"
+)
+
+// budgetCollector records requests to synthetic routes and runs the sampler that enforces the memory budget.
+type budgetCollector struct {
+	*stats.Collector
+	clock  *testutil.FakeClock
+	routes int
+}
+
+// newBudgetCollector creates a collector with the given memory budget and a fake clock.
+func newBudgetCollector(t *testing.T, budget uint64) *budgetCollector {
+	clock := testutil.NewFakeClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	collector := stats.NewCollector(
+		stats.WithClock(clock),
+		stats.WithSampleInterval(time.Second),
+		stats.WithMemoryBudget(budget),
+		stats.WithDistribution(func() stats.Distribution { return stats.NewTDigest(100) }),
+	)
+
+	t.Cleanup(func() { collector.Close(context.Background()) })
+	return &budgetCollector{Collector: collector, clock: clock}
+}
+
+// addRoute records requests of 1ms and a single one of 4ms to a new route, later routes get more traffic.
+// The median is 1ms, the histogram buckets only estimate it between the minimum and the maximum.
+func (collector *budgetCollector) addRoute() {
+	collector.routes++
+	route := "/route/" + strconv.Itoa(collector.routes)
+
+	for i := 0; i <= collector.routes; i++ {
+		collector.Track(route, time.Millisecond)
+	}
+
+	collector.Track(route, 4*time.Millisecond)
+}
+
+// budget returns the memory budget statistics of the App section.
+func (collector *budgetCollector) budget() *stats.MemoryBudgetStats {
+	return collector.SnapshotSections(stats.SectionApp).App.MemoryBudget
+}
+
+// sample advances the clock until the sampler has estimated the changed footprint.
+func (collector *budgetCollector) sample(t *testing.T) *stats.MemoryBudgetStats {
+	t.Helper()
+	previous := collector.budget().FootprintBytes
+
+	estimated := waitFor(func() bool {
+		collector.clock.Advance(time.Second)
+		return collector.budget().FootprintBytes != previous
+	})
+
+	if !estimated {
+		t.Fatal("the footprint was not estimated")
+	}
+
+	return collector.budget()
+}
+
+func TestMemoryBudgetLadder(t *testing.T) {
+	// The footprint of 50 routes without degradation is the budget of the test
+	calibration := newBudgetCollector(t, 1<<40)
+
+	for calibration.routes < 50 {
+		calibration.addRoute()
+	}
+
+	collector := newBudgetCollector(t, calibration.sample(t).FootprintBytes)
+	first := map[string]int{}
+	var order []string
+
+	for collector.routes < 1000 && first["routes"] == 0 {
+		collector.addRoute()
+		degradation := collector.sample(t).Degradation
+
+		if _, seen := first[degradation]; !seen {
+			first[degradation] = collector.routes
+			order = append(order, degradation)
+		}
+
+		// Only the distribution reports the exact median, without one it is estimated from the histogram buckets
+		if degradation == "histograms" && first["histograms"] == collector.routes {
+			routes := collector.Snapshot().Routes.Popular
+
+			if routes[0].Percentiles["p50"] != 1 {
+				t.Errorf("the distribution of the most requested route %s was dropped", routes[0].Route)
+			}
+
+			if routes[len(routes)-1].Percentiles["p50"] == 1 {
+				t.Errorf("the distribution of the least requested route %s was kept", routes[len(routes)-1].Route)
+			}
+		}
+	}
+
+	expected := []string{"none", "histograms", "sampling", "routes"}
+
+	if len(order) != len(expected) {
+		t.Fatalf("degradation steps %v, expected %v", order, expected)
+	}
+
+	for i := range expected {
+		if order[i] != expected[i] {
+			t.Fatalf("degradation steps %v, expected %v", order, expected)
+		}
+	}
+
+	collector.Track("/beyond/the/budget", time.Millisecond)
+
+	for _, route := range collector.Routes() {
+		if route == "/beyond/the/budget" {
+			t.Errorf("new route tracked although the budget is exhausted")
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package stats
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Counter is an application metric that only increases, e.g. the number of emails sent.
+type Counter struct {
+	value uint64
+}
+
+// Gauge is an application metric that goes up and down, e.g. the length of a queue.
+type Gauge struct {
+	value int64
+}
+
+// Timer measures the durations of an application operation, e.g. rendering a report.
+type Timer struct {
+	count uint64
+	total uint64
+	min   uint64
+	max   uint64
+}
+
+// MetricStats contains the values of the application metrics by name.
+type MetricStats struct {
+	Counters map[string]uint64     `json:",omitempty"`
+	Gauges   map[string]int64      `json:",omitempty"`
+	Timers   map[string]TimerStats `json:",omitempty"`
+}
+
+// TimerStats contains the number of measured operations and their durations in milliseconds.
+type TimerStats struct {
+	Count  uint64
+	MeanMs float64
+	MinMs  float64
+	MaxMs  float64
+}
+
+// metricRegistry contains the application metrics of each kind by name.
+type metricRegistry struct {
+	counters map[string]interface{}
+	gauges   map[string]interface{}
+	timers   map[string]interface{}
+	mutex    sync.RWMutex
+}
+
+// Counter returns the counter with the given name, creating it if needed.
+// Like Ratio, it is safe to call from multiple goroutines and always returns the same counter for the same name.
+func (stats *Collector) Counter(name string) *Counter {
+	return stats.metrics.metric(&stats.metrics.counters, name, func() interface{} { return &Counter{} }).(*Counter)
+}
+
+// Gauge returns the gauge with the given name, creating it if needed.
+func (stats *Collector) Gauge(name string) *Gauge {
+	return stats.metrics.metric(&stats.metrics.gauges, name, func() interface{} { return &Gauge{} }).(*Gauge)
+}
+
+// Timer returns the timer with the given name, creating it if needed.
+func (stats *Collector) Timer(name string) *Timer {
+	return stats.metrics.metric(&stats.metrics.timers, name, func() interface{} { return &Timer{} }).(*Timer)
+}
+
+// metric returns the metric with the given name from the map, creating the map and the metric if needed.
+func (registry *metricRegistry) metric(metrics *map[string]interface{}, name string, create func() interface{}) interface{} {
+	registry.mutex.RLock()
+	metric, exists := (*metrics)[name]
+	registry.mutex.RUnlock()
+
+	if exists {
+		return metric
+	}
+
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+
+	metric, exists = (*metrics)[name]
+
+	if !exists {
+		if *metrics == nil {
+			*metrics = make(map[string]interface{})
+		}
+
+		metric = create()
+		(*metrics)[name] = metric
+	}
+
+	return metric
+}
+
+// Inc increments the counter by one.
+func (counter *Counter) Inc() {
+	atomic.AddUint64(&counter.value, 1)
+}
+
+// Add increments the counter by n.
+func (counter *Counter) Add(n uint64) {
+	atomic.AddUint64(&counter.value, n)
+}
+
+// Value returns the current count.
+func (counter *Counter) Value() uint64 {
+	return atomic.LoadUint64(&counter.value)
+}
+
+// Set sets the gauge to the value.
+func (gauge *Gauge) Set(value int64) {
+	atomic.StoreInt64(&gauge.value, value)
+}
+
+// Add changes the gauge by delta, which can be negative.
+func (gauge *Gauge) Add(delta int64) {
+	atomic.AddInt64(&gauge.value, delta)
+}
+
+// Value returns the current value.
+func (gauge *Gauge) Value() int64 {
+	return atomic.LoadInt64(&gauge.value)
+}
+
+// Record adds the duration of an operation.
+func (timer *Timer) Record(duration time.Duration) {
+	if duration < 0 {
+		duration = 0
+	}
+
+	atomic.AddUint64(&timer.count, 1)
+	atomic.AddUint64(&timer.total, uint64(duration))
+	atomicMax(&timer.max, uint64(duration))
+
+	// Zero marks the minimum as unset, so it is at least a nanosecond
+	if duration == 0 {
+		duration = 1
+	}
+
+	atomicMin(&timer.min, uint64(duration))
+}
+
+// Since records the time elapsed since start, e.g. in a deferred call.
+func (timer *Timer) Since(start time.Time) {
+	timer.Record(time.Since(start))
+}
+
+// Stats returns the number of operations and their durations.
+func (timer *Timer) Stats() TimerStats {
+	count := atomic.LoadUint64(&timer.count)
+
+	if count == 0 {
+		return TimerStats{}
+	}
+
+	return TimerStats{
+		Count:  count,
+		MeanMs: float64(atomic.LoadUint64(&timer.total)) / float64(count) / float64(time.Millisecond),
+		MinMs:  float64(atomic.LoadUint64(&timer.min)) / float64(time.Millisecond),
+		MaxMs:  float64(atomic.LoadUint64(&timer.max)) / float64(time.Millisecond),
+	}
+}
+
+// stats returns the values of all metrics, nil if there are none.
+func (registry *metricRegistry) stats() *MetricStats {
+	registry.mutex.RLock()
+	defer registry.mutex.RUnlock()
+
+	if len(registry.counters)+len(registry.gauges)+len(registry.timers) == 0 {
+		return nil
+	}
+
+	metrics := &MetricStats{}
+
+	if len(registry.counters) > 0 {
+		metrics.Counters = make(map[string]uint64, len(registry.counters))
+
+		for name, counter := range registry.counters {
+			metrics.Counters[name] = counter.(*Counter).Value()
+		}
+	}
+
+	if len(registry.gauges) > 0 {
+		metrics.Gauges = make(map[string]int64, len(registry.gauges))
+
+		for name, gauge := range registry.gauges {
+			metrics.Gauges[name] = gauge.(*Gauge).Value()
+		}
+	}
+
+	if len(registry.timers) > 0 {
+		metrics.Timers = make(map[string]TimerStats, len(registry.timers))
+
+		for name, timer := range registry.timers {
+			metrics.Timers[name] = timer.(*Timer).Stats()
+		}
+	}
+
+	return metrics
+}
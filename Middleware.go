@@ -0,0 +1,94 @@
+package stats
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// contextKey is the type of context keys used by this package.
+type contextKey struct {
+	name string
+}
+
+// ArrivalTimeKey is the context key of the time a request arrived at the outermost middleware.
+var ArrivalTimeKey = &contextKey{"arrival time"}
+
+// ArrivalTime returns the time the request arrived at the Arrival middleware,
+// or at the recording middleware of a framework integration like aerostats.
+// The boolean is false if no such middleware is installed.
+func ArrivalTime(ctx context.Context) (time.Time, bool) {
+	arrival, ok := ctx.Value(ArrivalTimeKey).(time.Time)
+	return arrival, ok
+}
+
+// Arrival stamps the arrival time into the request context.
+// Install it as the outermost middleware and use Recorder just before the handler
+// to measure the middleware overhead separately from the handler time.
+func Arrival(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		ctx := context.WithValue(request.Context(), ArrivalTimeKey, time.Now())
+		next.ServeHTTP(response, request.WithContext(ctx))
+	})
+}
+
+// Recorder records every request to the handler under the given route, or the key returned by the route key function.
+// If the Arrival middleware is installed, the total time includes all middlewares
+// in between and the handler time is recorded separately.
+func (stats *Collector) Recorder(route string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		start := time.Now()
+		key := stats.RouteKey(request, route)
+		writer := &responseWriter{ResponseWriter: response}
+		ctx, finish := TrackRequest(request.Context())
+		request = request.WithContext(ctx)
+		body := &countingReader{ReadCloser: request.Body}
+
+		if request.Body != nil {
+			request.Body = body
+		}
+
+		next.ServeHTTP(writer, request)
+
+		handlerTime := time.Since(start)
+		record := RequestRecord{
+			Route:        key,
+			Method:       request.Method,
+			StatusCode:   writer.StatusCode(),
+			Duration:     handlerTime,
+			RequestSize:  body.count,
+			ResponseSize: writer.size,
+			Conditional:  request.Header.Get("If-None-Match") != "",
+			TraceID:      stats.RequestTraceID(request),
+			Visitor:      stats.RequestVisitor(request),
+			Origin:       stats.RequestOrigin(request),
+			Streamed:     writer.flushed,
+			Aborted:      writer.writeError || Aborted(request.Context()),
+		}
+
+		if !writer.firstByte.IsZero() {
+			record.FirstByte = writer.firstByte.Sub(start)
+		}
+
+		if len(stats.sampleHeaders) > 0 {
+			record.Headers = make(map[string]string, len(stats.sampleHeaders))
+
+			for _, name := range stats.sampleHeaders {
+				record.Headers[name] = request.Header.Get(name)
+			}
+		}
+
+		finish(&record)
+
+		if arrival, ok := ArrivalTime(request.Context()); ok {
+			record.Duration = time.Since(arrival)
+			record.HandlerTime = handlerTime
+
+			if !writer.firstByte.IsZero() {
+				record.FirstByte = writer.firstByte.Sub(arrival)
+			}
+		}
+
+		stats.Record(record)
+	})
+}
@@ -0,0 +1,41 @@
+package stats
+
+import (
+	"path"
+	"strings"
+)
+
+// NormalizePath cleans the path of a request that didn't match a route and replaces IDs with a placeholder,
+// so that requests to unknown paths don't create an unbounded number of routes.
+func NormalizePath(requestPath string) string {
+	segments := strings.Split(path.Clean("/"+requestPath), "/")
+
+	for i, segment := range segments {
+		if isID(segment) {
+			segments[i] = ":id"
+		}
+	}
+
+	return strings.Join(segments, "/")
+}
+
+// isID tells whether a path segment looks like a numeric or hexadecimal ID.
+func isID(segment string) bool {
+	if segment == "" {
+		return false
+	}
+
+	digits := 0
+
+	for _, char := range segment {
+		switch {
+		case char >= '0' && char <= '9':
+			digits++
+		case char >= 'a' && char <= 'f', char >= 'A' && char <= 'F', char == '-':
+		default:
+			return false
+		}
+	}
+
+	return digits == len(segment) || (len(segment) >= 16 && digits > 0)
+}
@@ -0,0 +1,69 @@
+package stats
+
+import "time"
+
+// Option configures a statistics instance.
+type Option func(*Collector)
+
+// WithDistribution sets the backend used to record the per-route latency distribution.
+func WithDistribution(factory DistributionFactory) Option {
+	return func(stats *Collector) {
+		stats.distribution = factory
+	}
+}
+
+// WithQuantiles sets the quantiles reported as percentiles of each route, e.g. 0.95 for "p95".
+// The "quantiles" query parameter overrides them for a single request.
+func WithQuantiles(quantiles ...float64) Option {
+	return func(stats *Collector) {
+		stats.quantiles = quantiles
+	}
+}
+
+// WithRouteHistory keeps the hourly history of the last 24 hours for every route.
+func WithRouteHistory() Option {
+	return func(stats *Collector) {
+		stats.routeHistory = true
+	}
+}
+
+// WithPath sets the path of the statistics endpoint.
+// An empty path doesn't register any routes.
+func WithPath(path string) Option {
+	return func(stats *Collector) {
+		stats.path = path
+	}
+}
+
+// WithStartTime sets the time the application was started, used for its uptime.
+// It defaults to the time the collector was created.
+func WithStartTime(start time.Time) Option {
+	return func(stats *Collector) {
+		stats.appStart = start
+	}
+}
+
+// defaultSlowRouteThreshold is the average response time above which a route is listed as slow.
+const defaultSlowRouteThreshold = 10 * time.Millisecond
+
+// WithSlowRouteThreshold sets the average response time from which on a route is listed in the Slow summary.
+func WithSlowRouteThreshold(threshold time.Duration) Option {
+	return func(stats *Collector) {
+		stats.slowThreshold = threshold
+	}
+}
+
+// WithPopularMinRequests sets the number of requests from which on a route is listed in the Popular summary, 1 by default.
+func WithPopularMinRequests(min uint64) Option {
+	return func(stats *Collector) {
+		stats.popularMin = min
+	}
+}
+
+// WithRouteListLimit limits the number of routes in each list of the route summary to the first ones, 0 means no limit.
+// The "limit" query parameter overrides it for a single request.
+func WithRouteListLimit(limit int) Option {
+	return func(stats *Collector) {
+		stats.listLimit = limit
+	}
+}
@@ -0,0 +1,221 @@
+package stats
+
+import (
+	"container/list"
+	"net"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	// UnknownOrigin is the origin of requests the resolver returned no result for.
+	UnknownOrigin = "unknown"
+
+	// OtherOrigin collects the requests of all origins beyond maxOrigins.
+	OtherOrigin = "other"
+
+	// maxOrigins is the number of distinct origins that are counted individually.
+	maxOrigins = 256
+
+	// originCacheSize is the number of IPs whose resolved origin is cached.
+	originCacheSize = 4096
+
+	// topOrigins is the number of origins included in the snapshot.
+	topOrigins = 20
+)
+
+// WithGeoResolver counts requests by the origin the resolver returns for the client IP,
+// e.g. a country code or an ASN label. Results are cached per IP.
+func WithGeoResolver(resolve func(ip net.IP) string) Option {
+	return func(stats *Collector) {
+		if resolve == nil {
+			stats.origins = nil
+			return
+		}
+
+		stats.origins = &origins{
+			resolve:  resolve,
+			cache:    newOriginCache(originCacheSize),
+			counters: make(map[string]*originCounters),
+		}
+	}
+}
+
+// origins counts the requests per origin.
+type origins struct {
+	resolve  func(ip net.IP) string
+	cache    *originCache
+	mutex    sync.RWMutex
+	counters map[string]*originCounters
+}
+
+// originCounters are the counters of a single origin.
+type originCounters struct {
+	requestCount uint64
+	errorCount   uint64
+}
+
+// OriginStats contains the number of requests from an origin and the share of them that failed.
+type OriginStats struct {
+	Origin    string
+	Requests  uint64
+	Errors    uint64
+	ErrorRate float64
+}
+
+// RequestOrigin returns the resolved origin of an HTTP request, for adapters filling RequestRecord.Origin.
+// It returns an empty string if no geo resolver is configured.
+func (stats *Collector) RequestOrigin(request *http.Request) string {
+	if stats.origins == nil {
+		return ""
+	}
+
+	return stats.origins.lookup(clientAddress(request))
+}
+
+// lookup returns the origin of the client address, calling the resolver only for uncached addresses.
+func (origins *origins) lookup(address string) string {
+	if origin, ok := origins.cache.get(address); ok {
+		return origin
+	}
+
+	origin := ""
+
+	if ip := net.ParseIP(address); ip != nil {
+		origin = origins.resolve(ip)
+	}
+
+	if origin == "" {
+		origin = UnknownOrigin
+	}
+
+	origins.cache.add(address, origin)
+	return origin
+}
+
+// record counts a finished request for its origin.
+func (origins *origins) record(record *RequestRecord) {
+	counters := origins.counter(record.Origin)
+	atomic.AddUint64(&counters.requestCount, 1)
+
+	if record.failed() {
+		atomic.AddUint64(&counters.errorCount, 1)
+	}
+}
+
+// counter returns the counters of the origin, collecting it under OtherOrigin once the map is full.
+func (origins *origins) counter(origin string) *originCounters {
+	origins.mutex.RLock()
+	counters := origins.counters[origin]
+	origins.mutex.RUnlock()
+
+	if counters != nil {
+		return counters
+	}
+
+	origins.mutex.Lock()
+	defer origins.mutex.Unlock()
+
+	if len(origins.counters) >= maxOrigins && origins.counters[origin] == nil {
+		origin = OtherOrigin
+	}
+
+	counters = origins.counters[origin]
+
+	if counters == nil {
+		counters = &originCounters{}
+		origins.counters[origin] = counters
+	}
+
+	return counters
+}
+
+// Stats returns the origins with the most requests.
+func (origins *origins) Stats() []OriginStats {
+	origins.mutex.RLock()
+	exported := make([]OriginStats, 0, len(origins.counters))
+
+	for origin, counters := range origins.counters {
+		requestCount := atomic.LoadUint64(&counters.requestCount)
+		errorCount := atomic.LoadUint64(&counters.errorCount)
+
+		exported = append(exported, OriginStats{
+			Origin:    origin,
+			Requests:  requestCount,
+			Errors:    errorCount,
+			ErrorRate: float64(errorCount) / float64(requestCount),
+		})
+	}
+
+	origins.mutex.RUnlock()
+
+	sort.Slice(exported, func(i, j int) bool {
+		return exported[i].Requests > exported[j].Requests
+	})
+
+	if len(exported) > topOrigins {
+		exported = exported[:topOrigins]
+	}
+
+	return exported
+}
+
+// originCache is a least recently used cache of resolved origins by client address.
+type originCache struct {
+	mutex   sync.Mutex
+	size    int
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+// originCacheEntry is a cached origin.
+type originCacheEntry struct {
+	address string
+	origin  string
+}
+
+// newOriginCache creates a cache holding at most size addresses.
+func newOriginCache(size int) *originCache {
+	return &originCache{
+		size:    size,
+		order:   list.New(),
+		entries: make(map[string]*list.Element, size),
+	}
+}
+
+// get returns the cached origin of the address and marks it as recently used.
+func (cache *originCache) get(address string) (string, bool) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	element, ok := cache.entries[address]
+
+	if !ok {
+		return "", false
+	}
+
+	cache.order.MoveToFront(element)
+	return element.Value.(*originCacheEntry).origin, true
+}
+
+// add caches the origin of the address, evicting the least recently used address if the cache is full.
+func (cache *originCache) add(address string, origin string) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	if element, ok := cache.entries[address]; ok {
+		element.Value.(*originCacheEntry).origin = origin
+		cache.order.MoveToFront(element)
+		return
+	}
+
+	if cache.order.Len() >= cache.size {
+		oldest := cache.order.Back()
+		cache.order.Remove(oldest)
+		delete(cache.entries, oldest.Value.(*originCacheEntry).address)
+	}
+
+	cache.entries[address] = cache.order.PushFront(&originCacheEntry{address: address, origin: origin})
+}
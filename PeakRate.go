@@ -0,0 +1,31 @@
+package stats
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// PeakRate tracks the highest number of requests per second.
+type PeakRate struct {
+	second int64
+	count  uint64
+	peak   uint64
+}
+
+// record counts a request in the current second.
+func (rate *PeakRate) record(now time.Time) {
+	second := now.Unix()
+	previous := atomic.LoadInt64(&rate.second)
+
+	if previous != second && atomic.CompareAndSwapInt64(&rate.second, previous, second) {
+		atomicMax(&rate.peak, atomic.SwapUint64(&rate.count, 0))
+	}
+
+	atomic.AddUint64(&rate.count, 1)
+}
+
+// Take returns the peak requests per second and starts a new measurement.
+func (rate *PeakRate) Take() uint64 {
+	atomicMax(&rate.peak, atomic.LoadUint64(&rate.count))
+	return atomic.SwapUint64(&rate.peak, 0)
+}
@@ -0,0 +1,193 @@
+package stats
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// persistVersion is the version of the format written by Save.
+const persistVersion = 1
+
+// persistedStatistics is the format written by Save and read by Load.
+type persistedStatistics struct {
+	Version int
+	Saved   time.Time
+	Routes  map[string]persistedRoute
+}
+
+// persistedRoute contains the cumulative counters of a route.
+type persistedRoute struct {
+	Protocol          string `json:",omitempty"`
+	Requests          uint64
+	Errors            uint64
+	Warmup            uint64 `json:",omitempty"`
+	Measured          uint64
+	Aborted           uint64 `json:",omitempty"`
+	AbortedTimeNs     uint64 `json:",omitempty"`
+	ResponseTimeNs    uint64
+	MinResponseTimeNs uint64
+	MaxResponseTimeNs uint64
+	RequestBytes      uint64
+	ResponseBytes     uint64
+	StatusClasses     statusClassCounts
+}
+
+// Save writes the cumulative counters of all routes as JSON:
+// the request, error and byte counts and the total, minimum and maximum response times.
+// Distributions, histories and the other statistics are not included.
+func (stats *Collector) Save(w io.Writer) error {
+	state := persistedStatistics{
+		Version: persistVersion,
+		Saved:   stats.clock.Now(),
+		Routes:  map[string]persistedRoute{},
+	}
+
+	for _, tracked := range stats.trackedRoutes() {
+		route := tracked.stats
+		maxResponseTime, _ := route.maxResponseTime.load()
+		persisted := persistedRoute{
+			Requests:          atomic.LoadUint64(&route.requestCount),
+			Errors:            atomic.LoadUint64(&route.errorCount),
+			Warmup:            atomic.LoadUint64(&route.warmupCount),
+			Measured:          atomic.LoadUint64(&route.measuredCount),
+			Aborted:           atomic.LoadUint64(&route.abortedCount),
+			AbortedTimeNs:     atomic.LoadUint64(&route.abortedTime),
+			ResponseTimeNs:    atomic.LoadUint64(&route.responseTime),
+			MinResponseTimeNs: atomic.LoadUint64(&route.minResponseTime),
+			MaxResponseTimeNs: maxResponseTime,
+			RequestBytes:      atomic.LoadUint64(&route.requestBytes),
+			ResponseBytes:     atomic.LoadUint64(&route.responseSizes.total),
+		}
+
+		persisted.StatusClasses.fold(&route.statusCounts)
+
+		if route.Protocol() == ProtocolGRPC {
+			persisted.Protocol = ProtocolGRPC
+		}
+
+		state.Routes[tracked.path] = persisted
+	}
+
+	return json.NewEncoder(w).Encode(state)
+}
+
+// Load adds the counters written by Save to the routes, e.g. on startup after a deploy.
+// The counters are added to the current ones, so loading the same data twice counts it twice.
+func (stats *Collector) Load(r io.Reader) error {
+	var state persistedStatistics
+
+	if err := json.NewDecoder(r).Decode(&state); err != nil {
+		return err
+	}
+
+	if state.Version != persistVersion {
+		return errors.New("Unsupported statistics version: " + strconv.Itoa(state.Version))
+	}
+
+	for path, persisted := range state.Routes {
+		route := stats.route(path)
+		atomic.AddUint64(&route.requestCount, persisted.Requests)
+		atomic.AddUint64(&route.errorCount, persisted.Errors)
+		atomic.AddUint64(&route.warmupCount, persisted.Warmup)
+		atomic.AddUint64(&route.measuredCount, persisted.Measured)
+		atomic.AddUint64(&route.abortedCount, persisted.Aborted)
+		atomic.AddUint64(&route.abortedTime, persisted.AbortedTimeNs)
+		atomic.AddUint64(&route.responseTime, persisted.ResponseTimeNs)
+		atomic.AddUint64(&route.requestBytes, persisted.RequestBytes)
+		atomic.AddUint64(&route.responseSizes.total, persisted.ResponseBytes)
+		route.statusCounts.fold(&persisted.StatusClasses)
+
+		if persisted.MinResponseTimeNs > 0 {
+			atomicMin(&route.minResponseTime, persisted.MinResponseTimeNs)
+		}
+
+		route.maxResponseTime.record(state.Saved, persisted.MaxResponseTimeNs)
+
+		if persisted.Protocol == ProtocolGRPC {
+			atomic.StoreUint32(&route.grpc, 1)
+		}
+	}
+
+	return nil
+}
+
+// PersistTo loads the statistics saved in the file, if it exists,
+// and saves them to it in every interval and on Close.
+// The file is replaced atomically, failed saves are reported to the OnError handler.
+func (stats *Collector) PersistTo(path string, interval time.Duration) error {
+	if interval <= 0 {
+		return fmt.Errorf("Persist interval must be positive: %v", interval)
+	}
+
+	file, err := os.Open(path)
+
+	switch {
+	case err == nil:
+		err = stats.Load(file)
+		file.Close()
+
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+
+	case !errors.Is(err, os.ErrNotExist):
+		return err
+	}
+
+	save := func(ctx context.Context) error {
+		return stats.saveFile(path)
+	}
+
+	stats.goroutine(func() {
+		ticker := stats.clock.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stats.done:
+				return
+			case <-ticker.C():
+				if err := stats.saveFile(path); err != nil && stats.onError != nil {
+					stats.onError(fmt.Errorf("persistence: %w", err))
+				}
+			}
+		}
+	})
+
+	stats.onClose(save)
+	return nil
+}
+
+// saveFile saves the statistics to a temporary file in the same directory and renames it to path,
+// so that a crash never leaves a partially written file behind.
+func (stats *Collector) saveFile(path string) error {
+	file, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+
+	if err != nil {
+		return err
+	}
+
+	err = stats.Save(file)
+
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err == nil {
+		err = os.Rename(file.Name(), path)
+	}
+
+	if err != nil {
+		os.Remove(file.Name())
+	}
+
+	return err
+}
@@ -0,0 +1,27 @@
+package stats_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aerogo/stats"
+)
+
+func TestPersistToInterval(t *testing.T) {
+	collector := stats.NewCollector()
+	defer collector.Close(context.Background())
+	path := filepath.Join(t.TempDir(), "stats.json")
+
+	for _, interval := range []time.Duration{0, -time.Second} {
+		if err := collector.PersistTo(path, interval); err == nil {
+			t.Errorf("interval %v was accepted", interval)
+		}
+	}
+
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("the statistics were saved with an invalid interval")
+	}
+}
@@ -0,0 +1,67 @@
+package stats
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ProcessCPUStats contains the CPU time consumed by the application.
+// Cores is the average number of cores used during the last sample interval.
+type ProcessCPUStats struct {
+	UserSeconds   float64
+	SystemSeconds float64
+	Cores         float64
+}
+
+// cpuUsage keeps the previous CPU time sample to calculate the cores used in between.
+type cpuUsage struct {
+	previous     ProcessCPUTime
+	previousTime time.Time
+	cores        float64
+	mutex        sync.Mutex
+}
+
+// sampleCPU calculates the cores used since the previous sample.
+func (stats *Collector) sampleCPU(now time.Time) {
+	times, err := stats.system.ProcessCPU()
+
+	if err != nil {
+		return
+	}
+
+	usage := &stats.cpuUsage
+	usage.mutex.Lock()
+	defer usage.mutex.Unlock()
+
+	if elapsed := now.Sub(usage.previousTime); !usage.previousTime.IsZero() && elapsed > 0 {
+		used := times.User + times.System - usage.previous.User - usage.previous.System
+		usage.cores = float64(used) / float64(elapsed)
+	}
+
+	usage.previous = times
+	usage.previousTime = now
+}
+
+// processCPUStats returns the CPU time of the application, or nil if it can't be read.
+func (stats *Collector) processCPUStats() *ProcessCPUStats {
+	times, err := stats.system.ProcessCPU()
+
+	if err != nil {
+		if !errors.Is(err, ErrUnsupported) {
+			stats.reportSystemError("process cpu", err)
+		}
+
+		return nil
+	}
+
+	stats.cpuUsage.mutex.Lock()
+	cores := stats.cpuUsage.cores
+	stats.cpuUsage.mutex.Unlock()
+
+	return &ProcessCPUStats{
+		UserSeconds:   times.User.Seconds(),
+		SystemSeconds: times.System.Seconds(),
+		Cores:         cores,
+	}
+}
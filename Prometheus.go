@@ -0,0 +1,255 @@
+package stats
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Content types of the metrics endpoint
+const (
+	prometheusContentType  = "text/plain; version=0.0.4; charset=utf-8"
+	openMetricsContentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+)
+
+// PrometheusHandler returns a handler serving the metrics like the metrics route of the endpoint,
+// for mounting them at a path of your choice, e.g. "/metrics" for an existing Prometheus setup.
+// The rate limit and the endpoint middlewares apply.
+func (stats *Collector) PrometheusHandler() http.Handler {
+	return stats.guard(stats.showMetrics)
+}
+
+// showMetrics serves the route metrics in the Prometheus text exposition format,
+// or in the OpenMetrics format with exemplars if the scraper accepts it.
+func (stats *Collector) showMetrics(response http.ResponseWriter, request *http.Request) {
+	openMetrics := strings.Contains(request.Header.Get("Accept"), "application/openmetrics-text")
+
+	// Exemplars are only sent once, for the requests since the previous scrape
+	now := stats.clock.Now()
+	since := time.Unix(0, atomic.SwapInt64(&stats.lastScrape, now.UnixNano()))
+
+	if openMetrics {
+		response.Header().Set("Content-Type", openMetricsContentType)
+	} else {
+		response.Header().Set("Content-Type", prometheusContentType)
+	}
+
+	stats.renderMetrics(response, openMetrics, since)
+}
+
+// renderMetrics writes the route, memory and system metrics in the Prometheus or OpenMetrics text format.
+// The metric names follow the conventions of the official client libraries.
+func (stats *Collector) renderMetrics(w io.Writer, openMetrics bool, since time.Time) error {
+	writer := bufio.NewWriter(w)
+
+	type routeMetrics struct {
+		path      string
+		protocol  string
+		requests  []requestSeries
+		latencies []latencySeries
+	}
+
+	var routes []routeMetrics
+
+	for _, route := range stats.trackedRoutes() {
+		metrics := routeMetrics{
+			path:     escapeLabel(route.path),
+			protocol: route.stats.Protocol(),
+		}
+
+		metrics.requests, metrics.latencies = route.stats.series.load(since)
+		routes = append(routes, metrics)
+	}
+
+	writeMetadata(writer, "http_requests_total", "counter", "Total number of requests.", openMetrics)
+
+	for _, route := range routes {
+		for _, series := range route.requests {
+			fmt.Fprintf(writer, "http_requests_total{route=\"%s\",method=\"%s\",code=\"%s\",protocol=\"%s\"} %d\n", route.path, escapeLabel(series.method), series.code, route.protocol, series.requests)
+		}
+	}
+
+	writeMetadata(writer, "http_request_duration_seconds", "histogram", "Response time of requests.", openMetrics)
+
+	for _, route := range routes {
+		for _, series := range route.latencies {
+			labels := fmt.Sprintf("route=\"%s\",method=\"%s\",protocol=\"%s\"", route.path, escapeLabel(series.method), route.protocol)
+			cumulative := uint64(0)
+
+			for i, count := range series.counts {
+				cumulative += count
+				le := "+Inf"
+
+				if i < len(series.bounds) {
+					le = formatSeconds(series.bounds[i])
+				}
+
+				fmt.Fprintf(writer, "http_request_duration_seconds_bucket{%s,le=\"%s\"} %d", labels, le, cumulative)
+
+				if exemplar := series.exemplars[i]; openMetrics && exemplar.traceID != "" {
+					fmt.Fprintf(writer, " # {trace_id=\"%s\"} %s %.3f", escapeLabel(exemplar.traceID), formatSeconds(exemplar.duration), float64(exemplar.time.UnixNano())/float64(time.Second))
+				}
+
+				fmt.Fprintln(writer)
+			}
+
+			fmt.Fprintf(writer, "http_request_duration_seconds_sum{%s} %s\n", labels, formatSeconds(series.sum))
+			fmt.Fprintf(writer, "http_request_duration_seconds_count{%s} %d\n", labels, cumulative)
+		}
+	}
+
+	bandwidth := stats.bandwidth.Stats()
+	writeMetadata(writer, "http_request_size_bytes_total", "counter", "Total bytes received in request bodies.", openMetrics)
+	fmt.Fprintf(writer, "http_request_size_bytes_total %d\n", bandwidth.ReceivedBytes)
+	writeMetadata(writer, "http_response_size_bytes_total", "counter", "Total bytes sent in response bodies.", openMetrics)
+	fmt.Fprintf(writer, "http_response_size_bytes_total %d\n", bandwidth.SentBytes)
+
+	if ratios := stats.ratioStats(); len(ratios) > 0 {
+		writeMetadata(writer, "ratio_hit_rate", "gauge", "Share of hits in all events of the ratio.", openMetrics)
+
+		for _, name := range ratioNames(ratios) {
+			fmt.Fprintf(writer, "ratio_hit_rate{name=\"%s\"} %s\n", escapeLabel(name), strconv.FormatFloat(ratios[name].HitRate, 'g', -1, 64))
+		}
+	}
+
+	renderMemoryMetrics(writer, openMetrics)
+
+	if cpu, err := stats.system.ProcessCPU(); err == nil {
+		writeMetadata(writer, "process_cpu_seconds_total", "counter", "Total user and system CPU time spent in seconds.", openMetrics)
+		fmt.Fprintf(writer, "process_cpu_seconds_total{mode=\"user\"} %s\n", formatSeconds(cpu.User))
+		fmt.Fprintf(writer, "process_cpu_seconds_total{mode=\"system\"} %s\n", formatSeconds(cpu.System))
+	}
+
+	stats.renderSystemMetrics(writer, openMetrics)
+
+	info := stats.runtimeInfo
+	writeMetadata(writer, "go_runtime_info", "gauge", "Runtime settings of the application.", openMetrics)
+	fmt.Fprintf(writer, "go_runtime_info{version=\"%s\",gomaxprocs=\"%d\",gogc=\"%s\",gomemlimit=\"%s\",race=\"%t\",cgo=\"%t\"} 1\n", escapeLabel(runtime.Version()), info.GOMAXPROCS, info.GOGC, info.GOMemLimit, info.Race, info.CGO)
+
+	if openMetrics {
+		fmt.Fprintln(writer, "# EOF")
+	}
+
+	return writer.Flush()
+}
+
+// renderMemoryMetrics writes the Go runtime metrics under the names of the official client library,
+// so that existing dashboards keep working.
+func renderMemoryMetrics(writer io.Writer, openMetrics bool) {
+	memStats := runtime.MemStats{}
+	runtime.ReadMemStats(&memStats)
+
+	metrics := []struct {
+		name  string
+		kind  string
+		help  string
+		value float64
+	}{
+		{"go_goroutines", "gauge", "Number of goroutines that currently exist.", float64(runtime.NumGoroutine())},
+		{"go_cgo_calls_total", "counter", "Total number of cgo calls made by the process.", float64(runtime.NumCgoCall())},
+		{"go_gomaxprocs", "gauge", "Current GOMAXPROCS setting.", float64(runtime.GOMAXPROCS(0))},
+		{"go_memstats_alloc_bytes", "gauge", "Number of bytes allocated and still in use.", float64(memStats.Alloc)},
+		{"go_memstats_alloc_bytes_total", "counter", "Total number of bytes allocated, even if freed.", float64(memStats.TotalAlloc)},
+		{"go_memstats_sys_bytes", "gauge", "Number of bytes obtained from system.", float64(memStats.Sys)},
+		{"go_memstats_lookups_total", "counter", "Total number of pointer lookups.", float64(memStats.Lookups)},
+		{"go_memstats_mallocs_total", "counter", "Total number of mallocs.", float64(memStats.Mallocs)},
+		{"go_memstats_frees_total", "counter", "Total number of frees.", float64(memStats.Frees)},
+		{"go_memstats_heap_alloc_bytes", "gauge", "Number of heap bytes allocated and still in use.", float64(memStats.HeapAlloc)},
+		{"go_memstats_heap_sys_bytes", "gauge", "Number of heap bytes obtained from system.", float64(memStats.HeapSys)},
+		{"go_memstats_heap_idle_bytes", "gauge", "Number of heap bytes waiting to be used.", float64(memStats.HeapIdle)},
+		{"go_memstats_heap_inuse_bytes", "gauge", "Number of heap bytes that are in use.", float64(memStats.HeapInuse)},
+		{"go_memstats_heap_released_bytes", "gauge", "Number of heap bytes released to OS.", float64(memStats.HeapReleased)},
+		{"go_memstats_heap_objects", "gauge", "Number of allocated objects.", float64(memStats.HeapObjects)},
+		{"go_memstats_stack_inuse_bytes", "gauge", "Number of bytes in use by the stack allocator.", float64(memStats.StackInuse)},
+		{"go_memstats_stack_sys_bytes", "gauge", "Number of bytes obtained from system for stack allocator.", float64(memStats.StackSys)},
+		{"go_memstats_gc_sys_bytes", "gauge", "Number of bytes used for garbage collection system metadata.", float64(memStats.GCSys)},
+		{"go_memstats_next_gc_bytes", "gauge", "Number of heap bytes when next garbage collection will take place.", float64(memStats.NextGC)},
+		{"go_memstats_last_gc_time_seconds", "gauge", "Number of seconds since 1970 of last garbage collection.", float64(memStats.LastGC) / float64(time.Second)},
+	}
+
+	for _, metric := range metrics {
+		// Without the suffix the counter family would clash with the go_memstats_alloc_bytes gauge
+		if openMetrics && metric.name == "go_memstats_alloc_bytes_total" {
+			continue
+		}
+
+		writeMetadata(writer, metric.name, metric.kind, metric.help, openMetrics)
+		fmt.Fprintf(writer, "%s %s\n", metric.name, strconv.FormatFloat(metric.value, 'g', -1, 64))
+	}
+}
+
+// renderSystemMetrics writes the statistics of the host system.
+// Metrics the system provider can't read are left out, errors are reported like in the snapshot.
+func (stats *Collector) renderSystemMetrics(writer io.Writer, openMetrics bool) {
+	available := func(metric string, err error) bool {
+		if errors.Is(err, ErrUnsupported) {
+			return false
+		}
+
+		stats.reportSystemError(metric, err)
+		return err == nil
+	}
+
+	if load, err := stats.system.LoadAverage(); available("load average", err) {
+		writeMetadata(writer, "system_load_average", "gauge", "System load average over the period.", openMetrics)
+		fmt.Fprintf(writer, "system_load_average{period=\"1m\"} %s\n", strconv.FormatFloat(load.One, 'g', -1, 64))
+		fmt.Fprintf(writer, "system_load_average{period=\"5m\"} %s\n", strconv.FormatFloat(load.Five, 'g', -1, 64))
+		fmt.Fprintf(writer, "system_load_average{period=\"15m\"} %s\n", strconv.FormatFloat(load.Fifteen, 'g', -1, 64))
+	}
+
+	if memory, err := stats.system.Memory(); available("memory", err) {
+		writeMetadata(writer, "system_memory_bytes", "gauge", "System memory in bytes, excluding buffers and caches from the used memory.", openMetrics)
+		fmt.Fprintf(writer, "system_memory_bytes{state=\"total\"} %d\n", memory.Total)
+		fmt.Fprintf(writer, "system_memory_bytes{state=\"used\"} %d\n", memory.ActualUsed)
+		fmt.Fprintf(writer, "system_memory_bytes{state=\"free\"} %d\n", memory.ActualFree)
+	}
+
+	if swap, err := stats.system.Swap(); available("swap", err) {
+		writeMetadata(writer, "system_swap_bytes", "gauge", "System swap space in bytes.", openMetrics)
+		fmt.Fprintf(writer, "system_swap_bytes{state=\"total\"} %d\n", swap.Total)
+		fmt.Fprintf(writer, "system_swap_bytes{state=\"used\"} %d\n", swap.Used)
+	}
+
+	if uptime, err := stats.system.Uptime(); available("uptime", err) {
+		writeMetadata(writer, "system_uptime_seconds", "gauge", "Time since the system was booted in seconds.", openMetrics)
+		fmt.Fprintf(writer, "system_uptime_seconds %s\n", formatSeconds(uptime))
+	}
+
+	if fds, err := stats.system.FDUsage(); available("file descriptors", err) {
+		writeMetadata(writer, "process_open_fds", "gauge", "Number of open file descriptors.", openMetrics)
+		fmt.Fprintf(writer, "process_open_fds %d\n", fds.Open)
+		writeMetadata(writer, "process_max_fds", "gauge", "Maximum number of open file descriptors.", openMetrics)
+		fmt.Fprintf(writer, "process_max_fds %d\n", fds.Limit)
+	}
+}
+
+// writeMetadata writes the HELP and TYPE lines of a metric family.
+// OpenMetrics names counter families without the _total suffix of their samples.
+func writeMetadata(writer io.Writer, name string, kind string, help string, openMetrics bool) {
+	if openMetrics && kind == "counter" {
+		name = strings.TrimSuffix(name, "_total")
+	}
+
+	fmt.Fprintf(writer, "# HELP %s %s\n", name, help)
+	fmt.Fprintf(writer, "# TYPE %s %s\n", name, kind)
+}
+
+// escapeLabel escapes a label value according to the exposition format.
+func escapeLabel(value string) string {
+	return labelEscaper.Replace(value)
+}
+
+// labelEscaper escapes backslashes, quotes and line feeds.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// formatSeconds formats a duration as seconds.
+func formatSeconds(duration time.Duration) string {
+	return strconv.FormatFloat(duration.Seconds(), 'f', -1, 64)
+}
@@ -0,0 +1,391 @@
+package stats_test
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aerogo/stats"
+)
+
+var (
+	metricName = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*`)
+	labelName  = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*`)
+)
+
+// metricFamily is a metric with its metadata and samples in the text exposition format.
+type metricFamily struct {
+	help    string
+	kind    string
+	samples []metricSample
+}
+
+// metricSample is a single line of a metric family.
+type metricSample struct {
+	name   string
+	labels map[string]string
+	value  float64
+}
+
+// parseExposition parses and validates the Prometheus text format like promtool check metrics.
+func parseExposition(text string) (map[string]*metricFamily, error) {
+	families := map[string]*metricFamily{}
+	series := map[string]bool{}
+	finished := map[string]bool{}
+	current := ""
+
+	family := func(name string) *metricFamily {
+		if families[name] == nil {
+			families[name] = &metricFamily{}
+		}
+
+		return families[name]
+	}
+
+	for number, line := range strings.Split(strings.TrimSuffix(text, "\n"), "\n") {
+		fail := func(format string, args ...interface{}) error {
+			return fmt.Errorf("line %d %q: %s", number+1, line, fmt.Sprintf(format, args...))
+		}
+
+		if strings.HasPrefix(line, "# HELP ") || strings.HasPrefix(line, "# TYPE ") {
+			fields := strings.SplitN(line[len("# HELP "):], " ", 2)
+
+			if len(fields) != 2 || metricName.FindString(fields[0]) != fields[0] {
+				return nil, fail("invalid metadata")
+			}
+
+			name, metadata := fields[0], family(fields[0])
+
+			if len(metadata.samples) > 0 {
+				return nil, fail("metadata after the samples")
+			}
+
+			if strings.HasPrefix(line, "# HELP ") {
+				if metadata.help != "" {
+					return nil, fail("second HELP line")
+				}
+
+				metadata.help = fields[1]
+				continue
+			}
+
+			if metadata.kind != "" {
+				return nil, fail("second TYPE line")
+			}
+
+			switch fields[1] {
+			case "counter", "gauge", "histogram", "summary", "untyped":
+			default:
+				return nil, fail("unknown type")
+			}
+
+			if fields[1] == "counter" && !strings.HasSuffix(name, "_total") {
+				return nil, fail("counter without the _total suffix")
+			}
+
+			metadata.kind = fields[1]
+			continue
+		}
+
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		sample, err := parseSample(line)
+
+		if err != nil {
+			return nil, fail("%v", err)
+		}
+
+		name := sample.name
+
+		for _, suffix := range []string{"_bucket", "_sum", "_count"} {
+			base := strings.TrimSuffix(name, suffix)
+
+			if base != name && families[base] != nil && families[base].kind == "histogram" {
+				name = base
+			}
+		}
+
+		metadata := families[name]
+
+		if metadata == nil || metadata.kind == "" || metadata.help == "" {
+			return nil, fail("sample without HELP and TYPE")
+		}
+
+		if name != current {
+			if finished[name] {
+				return nil, fail("samples of %s are not grouped together", name)
+			}
+
+			finished[current] = true
+			current = name
+		}
+
+		key := sample.name + fmt.Sprint(sortedLabels(sample.labels))
+
+		if series[key] {
+			return nil, fail("duplicate series")
+		}
+
+		series[key] = true
+		metadata.samples = append(metadata.samples, sample)
+	}
+
+	for name, metadata := range families {
+		if metadata.kind == "histogram" {
+			if err := checkHistogram(metadata); err != nil {
+				return nil, fmt.Errorf("%s: %w", name, err)
+			}
+		}
+	}
+
+	return families, nil
+}
+
+// parseSample parses a line like name{label="value"} 1.
+func parseSample(line string) (metricSample, error) {
+	sample := metricSample{name: metricName.FindString(line), labels: map[string]string{}}
+	rest := line[len(sample.name):]
+
+	if sample.name == "" {
+		return sample, fmt.Errorf("invalid metric name")
+	}
+
+	if strings.HasPrefix(rest, "{") {
+		rest = rest[1:]
+
+		for !strings.HasPrefix(rest, "}") {
+			name := labelName.FindString(rest)
+			rest = rest[len(name):]
+
+			if name == "" || !strings.HasPrefix(rest, `="`) {
+				return sample, fmt.Errorf("invalid label")
+			}
+
+			value := strings.Builder{}
+			rest = rest[2:]
+
+			for !strings.HasPrefix(rest, `"`) {
+				if rest == "" {
+					return sample, fmt.Errorf("unterminated label value")
+				}
+
+				if rest[0] == '\\' {
+					if len(rest) < 2 {
+						return sample, fmt.Errorf("unterminated escape sequence")
+					}
+
+					switch rest[1] {
+					case '\\', '"':
+						value.WriteByte(rest[1])
+					case 'n':
+						value.WriteByte('\n')
+					default:
+						return sample, fmt.Errorf("invalid escape sequence \\%c", rest[1])
+					}
+
+					rest = rest[2:]
+					continue
+				}
+
+				value.WriteByte(rest[0])
+				rest = rest[1:]
+			}
+
+			if _, exists := sample.labels[name]; exists {
+				return sample, fmt.Errorf("duplicate label %s", name)
+			}
+
+			sample.labels[name] = value.String()
+			rest = strings.TrimPrefix(rest[1:], ",")
+		}
+
+		rest = rest[1:]
+	}
+
+	fields := strings.Fields(rest)
+
+	if !strings.HasPrefix(rest, " ") || len(fields) < 1 || len(fields) > 2 {
+		return sample, fmt.Errorf("invalid value")
+	}
+
+	value, err := strconv.ParseFloat(fields[0], 64)
+
+	if err != nil {
+		return sample, err
+	}
+
+	sample.value = value
+	return sample, nil
+}
+
+// checkHistogram checks that the buckets of every series are cumulative and end with +Inf at the count.
+func checkHistogram(family *metricFamily) error {
+	type histogram struct {
+		bounds []float64
+		counts []float64
+		count  float64
+	}
+
+	histograms := map[string]*histogram{}
+
+	for _, sample := range family.samples {
+		labels := map[string]string{}
+
+		for name, value := range sample.labels {
+			if name != "le" {
+				labels[name] = value
+			}
+		}
+
+		key := fmt.Sprint(sortedLabels(labels))
+
+		if histograms[key] == nil {
+			histograms[key] = &histogram{count: -1}
+		}
+
+		series := histograms[key]
+
+		switch {
+		case strings.HasSuffix(sample.name, "_bucket"):
+			bound, err := strconv.ParseFloat(sample.labels["le"], 64)
+
+			if err != nil {
+				return fmt.Errorf("bucket without a valid le label: %w", err)
+			}
+
+			series.bounds = append(series.bounds, bound)
+			series.counts = append(series.counts, sample.value)
+
+		case strings.HasSuffix(sample.name, "_count"):
+			series.count = sample.value
+		}
+	}
+
+	for key, series := range histograms {
+		if len(series.bounds) == 0 || series.count < 0 {
+			return fmt.Errorf("%s: missing buckets or count", key)
+		}
+
+		for i := 1; i < len(series.bounds); i++ {
+			if series.bounds[i] <= series.bounds[i-1] || series.counts[i] < series.counts[i-1] {
+				return fmt.Errorf("%s: buckets are not cumulative", key)
+			}
+		}
+
+		last := len(series.bounds) - 1
+
+		if !math.IsInf(series.bounds[last], 1) || series.counts[last] != series.count {
+			return fmt.Errorf("%s: the +Inf bucket doesn't match the count", key)
+		}
+	}
+
+	return nil
+}
+
+// sortedLabels returns the labels as a sorted list for comparisons.
+func sortedLabels(labels map[string]string) []string {
+	list := make([]string, 0, len(labels))
+
+	for name, value := range labels {
+		list = append(list, name+"="+strconv.Quote(value))
+	}
+
+	sort.Strings(list)
+	return list
+}
+
+// scrape requests the metrics from the Prometheus handler.
+func scrape(t *testing.T, collector *stats.Collector) map[string]*metricFamily {
+	t.Helper()
+	response := httptest.NewRecorder()
+	collector.PrometheusHandler().ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	families, err := parseExposition(response.Body.String())
+
+	if err != nil {
+		t.Fatalf("%v\n%s", err, response.Body.String())
+	}
+
+	return families
+}
+
+func TestPrometheusExposition(t *testing.T) {
+	collector := stats.NewCollector()
+	collector.Record(stats.RequestRecord{Route: "/users/:id", Method: http.MethodGet, StatusCode: http.StatusOK, Duration: 15 * time.Millisecond})
+	collector.Record(stats.RequestRecord{Route: "/users/:id", Method: http.MethodPost, StatusCode: http.StatusInternalServerError, Duration: 2 * time.Second, RequestSize: 100, ResponseSize: 20})
+	families := scrape(t, collector)
+
+	types := map[string]string{
+		"http_requests_total":            "counter",
+		"http_request_duration_seconds":  "histogram",
+		"http_request_size_bytes_total":  "counter",
+		"http_response_size_bytes_total": "counter",
+		"go_goroutines":                  "gauge",
+		"go_memstats_alloc_bytes":        "gauge",
+		"go_memstats_alloc_bytes_total":  "counter",
+		"go_memstats_heap_objects":       "gauge",
+	}
+
+	for name, kind := range types {
+		if families[name] == nil || families[name].kind != kind {
+			t.Errorf("%s is missing or not a %s", name, kind)
+		}
+	}
+
+	requests := map[string]float64{}
+
+	for _, sample := range families["http_requests_total"].samples {
+		requests[sample.labels["route"]+" "+sample.labels["method"]+" "+sample.labels["code"]] = sample.value
+	}
+
+	if requests["/users/:id GET 200"] != 1 || requests["/users/:id POST 500"] != 1 {
+		t.Errorf("requests by route, method and code: %v", requests)
+	}
+
+	if received := families["http_request_size_bytes_total"].samples[0].value; received != 100 {
+		t.Errorf("%v bytes received, expected 100", received)
+	}
+
+	if sent := families["http_response_size_bytes_total"].samples[0].value; sent != 20 {
+		t.Errorf("%v bytes sent, expected 20", sent)
+	}
+}
+
+func TestPrometheusLabelEscaping(t *testing.T) {
+	route := "/quote\"/backslash\\/newline\n"
+	collector := stats.NewCollector()
+	collector.Record(stats.RequestRecord{Route: route, Method: http.MethodGet, StatusCode: http.StatusOK, Duration: time.Millisecond})
+
+	for _, sample := range scrape(t, collector)["http_requests_total"].samples {
+		if sample.labels["route"] != route {
+			t.Errorf("route label %q, expected %q", sample.labels["route"], route)
+		}
+	}
+}
+
+func TestPrometheusRatios(t *testing.T) {
+	collector := stats.NewCollector()
+	ratio := collector.Ratio("cache")
+	ratio.Hit()
+	ratio.Hit()
+	ratio.Hit()
+	ratio.Miss()
+
+	family := scrape(t, collector)["ratio_hit_rate"]
+
+	if family == nil || family.kind != "gauge" || len(family.samples) != 1 {
+		t.Fatalf("ratio_hit_rate is missing or not a single gauge: %+v", family)
+	}
+
+	if sample := family.samples[0]; sample.labels["name"] != "cache" || sample.value != 0.75 {
+		t.Errorf("hit rate %v of %q, expected 0.75 of cache", sample.value, sample.labels["name"])
+	}
+}
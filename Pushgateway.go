@@ -0,0 +1,160 @@
+package stats
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// Pushgateway retry settings
+const (
+	pushgatewayAttempts = 3
+	pushgatewayBackoff  = time.Second
+	pushgatewayTimeout  = 10 * time.Second
+)
+
+// pushgatewayClient is the HTTP client used for the Pushgateway.
+var pushgatewayClient = &http.Client{Timeout: pushgatewayTimeout}
+
+// PushToGateway pushes the Prometheus metrics to the Pushgateway at the given base URL in every interval,
+// grouped by the job and the host name as the instance. The last push happens synchronously on Close.
+// Failed pushes are retried with backoff and reported to the OnError handler.
+func (stats *Collector) PushToGateway(gateway string, job string, interval time.Duration) error {
+	if interval <= 0 {
+		return fmt.Errorf("Pushgateway interval must be positive: %v", interval)
+	}
+
+	target, err := pushgatewayURL(gateway, job)
+
+	if err != nil {
+		return err
+	}
+
+	push := func(ctx context.Context) error {
+		buffer := bytes.Buffer{}
+		stats.renderMetrics(&buffer, false, stats.clock.Now())
+		return pushgatewayRequest(ctx, http.MethodPut, target, buffer.Bytes())
+	}
+
+	stats.goroutine(func() {
+		ticker := stats.clock.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stats.done:
+				return
+			case <-ticker.C():
+				stats.pushWithRetry(push)
+			}
+		}
+	})
+
+	stats.onClose(push)
+	return nil
+}
+
+// DeleteFromGateway removes the metrics pushed by PushToGateway for the job
+// and the instance of this host from the Pushgateway.
+func DeleteFromGateway(gateway string, job string) error {
+	target, err := pushgatewayURL(gateway, job)
+
+	if err != nil {
+		return err
+	}
+
+	return pushgatewayRequest(context.Background(), http.MethodDelete, target, nil)
+}
+
+// pushWithRetry pushes the metrics and retries with exponential backoff until Close.
+func (stats *Collector) pushWithRetry(push func(context.Context) error) {
+	backoff := pushgatewayBackoff
+	var err error
+
+	for attempt := 1; attempt <= pushgatewayAttempts; attempt++ {
+		err = push(context.Background())
+
+		if err == nil || attempt == pushgatewayAttempts {
+			break
+		}
+
+		// The clock has no timers, the first tick of a ticker serves as one
+		timer := stats.clock.NewTicker(backoff)
+
+		select {
+		case <-stats.done:
+			timer.Stop()
+			return
+		case <-timer.C():
+			timer.Stop()
+		}
+
+		backoff *= 2
+	}
+
+	if err != nil && stats.onError != nil {
+		stats.onError(fmt.Errorf("pushgateway: %w", err))
+	}
+}
+
+// pushgatewayRequest sends the metrics to the Pushgateway.
+func pushgatewayRequest(ctx context.Context, method string, target string, body []byte) error {
+	request, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(body))
+
+	if err != nil {
+		return err
+	}
+
+	if body != nil {
+		request.Header.Set("Content-Type", prometheusContentType)
+	}
+
+	response, err := pushgatewayClient.Do(request)
+
+	if err != nil {
+		return err
+	}
+
+	response.Body.Close()
+
+	if response.StatusCode >= 300 {
+		return fmt.Errorf("%s %s: %s", method, target, response.Status)
+	}
+
+	return nil
+}
+
+// pushgatewayURL returns the URL of the job and instance grouping key.
+func pushgatewayURL(gateway string, job string) (string, error) {
+	if job == "" {
+		return "", fmt.Errorf("Pushgateway job name must not be empty")
+	}
+
+	instance, err := os.Hostname()
+
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSuffix(gateway, "/") + "/metrics" + groupingLabel("job", job) + groupingLabel("instance", instance), nil
+}
+
+// groupingLabel returns a path segment of the grouping key.
+// Values that contain a slash or are empty use the base64 encoding of the Pushgateway API.
+func groupingLabel(name string, value string) string {
+	if value == "" {
+		return "/" + name + "@base64/="
+	}
+
+	if strings.Contains(value, "/") {
+		return "/" + name + "@base64/" + base64.RawURLEncoding.EncodeToString([]byte(value))
+	}
+
+	return "/" + name + "/" + url.PathEscape(value)
+}
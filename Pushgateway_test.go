@@ -0,0 +1,99 @@
+package stats_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aerogo/stats"
+	"github.com/aerogo/stats/testutil"
+)
+
+func TestPushToGatewayInterval(t *testing.T) {
+	collector := stats.NewCollector()
+	defer collector.Close(context.Background())
+
+	for _, interval := range []time.Duration{0, -time.Second} {
+		if err := collector.PushToGateway("http://localhost:9091", "job", interval); err == nil {
+			t.Errorf("interval %v was accepted", interval)
+		}
+	}
+}
+
+func TestPushToGatewayRetry(t *testing.T) {
+	// The gateway holds every push until the test releases it and fails the first one
+	arrived := make(chan struct{})
+	release := make(chan struct{})
+	var pushes int32
+
+	gateway := httptest.NewServer(http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		arrived <- struct{}{}
+		<-release
+
+		if atomic.AddInt32(&pushes, 1) == 1 {
+			http.Error(response, "unavailable", http.StatusServiceUnavailable)
+		}
+	}))
+
+	defer gateway.Close()
+
+	clock := testutil.NewFakeClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	var failures int32
+
+	collector := stats.NewCollector(stats.WithClock(clock), stats.OnError(func(err error) {
+		atomic.AddInt32(&failures, 1)
+	}))
+
+	if err := collector.PushToGateway(gateway.URL, "job", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+
+	// advance moves the clock in steps until a push arrives and returns the time it took
+	advance := func(step time.Duration) time.Duration {
+		var elapsed time.Duration
+
+		for elapsed < time.Hour {
+			clock.Advance(step)
+			elapsed += step
+
+			select {
+			case <-arrived:
+				return elapsed
+			case <-time.After(5 * time.Millisecond):
+			}
+		}
+
+		t.Fatal("no push within an hour")
+		return elapsed
+	}
+
+	if elapsed := advance(10 * time.Second); elapsed < time.Minute {
+		t.Fatalf("pushed after %v, expected the interval", elapsed)
+	}
+
+	release <- struct{}{}
+
+	if elapsed := advance(100 * time.Millisecond); elapsed < time.Second || elapsed >= 2*time.Second {
+		t.Fatalf("retried after %v, expected the backoff", elapsed)
+	}
+
+	release <- struct{}{}
+
+	go func() {
+		<-arrived
+		release <- struct{}{}
+	}()
+
+	collector.Close(context.Background())
+
+	if atomic.LoadInt32(&pushes) != 3 {
+		t.Errorf("%d pushes, expected a final push on Close", pushes)
+	}
+
+	if atomic.LoadInt32(&failures) != 0 {
+		t.Errorf("%d errors reported although the retry succeeded", failures)
+	}
+}
@@ -0,0 +1,6 @@
+//go:build race
+
+package stats
+
+// raceEnabled tells you whether the race detector is enabled.
+const raceEnabled = true
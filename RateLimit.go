@@ -0,0 +1,165 @@
+package stats
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Default limits of the requests per second to the statistics endpoints
+const (
+	defaultGlobalRateLimit = 10
+	defaultClientRateLimit = 5
+)
+
+// WithRateLimit limits the requests per second to the statistics endpoints,
+// in total and per client address. A limit of zero disables it,
+// by default 10 requests per second are allowed in total and 5 per client.
+// Requests over the limit receive a 429 response with a Retry-After header.
+// The dashboard assets are not limited, they are requested in bursts and cached by the browser.
+func WithRateLimit(global float64, perClient float64) Option {
+	return func(stats *Collector) {
+		stats.globalRateLimit = global
+		stats.clientRateLimit = perClient
+	}
+}
+
+// rateLimiter limits the requests globally and per client.
+type rateLimiter struct {
+	clock     Clock
+	global    *tokenBucket
+	perClient float64
+	clients   sync.Map
+	lastSweep int64
+}
+
+// newRateLimiter creates a rate limiter, or nil if both limits are disabled.
+func newRateLimiter(clock Clock, global float64, perClient float64) *rateLimiter {
+	if global <= 0 && perClient <= 0 {
+		return nil
+	}
+
+	limiter := &rateLimiter{
+		clock:     clock,
+		perClient: perClient,
+		lastSweep: clock.Now().UnixNano(),
+	}
+
+	if global > 0 {
+		limiter.global = newTokenBucket(global)
+	}
+
+	return limiter
+}
+
+// allow reports whether the client may make a request now,
+// and otherwise how long it has to wait.
+func (limiter *rateLimiter) allow(client string) (bool, time.Duration) {
+	now := limiter.clock.Now().UnixNano()
+
+	if limiter.perClient > 0 {
+		limiter.sweep(now)
+		bucket, exists := limiter.clients.Load(client)
+
+		if !exists {
+			bucket, _ = limiter.clients.LoadOrStore(client, newTokenBucket(limiter.perClient))
+		}
+
+		if allowed, wait := bucket.(*tokenBucket).take(now); !allowed {
+			return false, wait
+		}
+	}
+
+	if limiter.global != nil {
+		return limiter.global.take(now)
+	}
+
+	return true, 0
+}
+
+// sweep removes the buckets of clients that have been idle long enough to be full again.
+// It runs at most once per minute.
+func (limiter *rateLimiter) sweep(now int64) {
+	last := atomic.LoadInt64(&limiter.lastSweep)
+
+	if now-last < int64(time.Minute) || !atomic.CompareAndSwapInt64(&limiter.lastSweep, last, now) {
+		return
+	}
+
+	limiter.clients.Range(func(client, bucket interface{}) bool {
+		if atomic.LoadInt64(&bucket.(*tokenBucket).tat) < now {
+			limiter.clients.Delete(client)
+		}
+
+		return true
+	})
+}
+
+// tokenBucket is a lock-free token bucket that allows bursts of one second,
+// implemented as the generic cell rate algorithm on the theoretical arrival time.
+type tokenBucket struct {
+	tat      int64
+	interval int64
+	window   int64
+}
+
+// newTokenBucket creates a bucket for the given number of requests per second.
+func newTokenBucket(rate float64) *tokenBucket {
+	interval := int64(float64(time.Second) / rate)
+
+	return &tokenBucket{
+		interval: interval,
+		window:   interval * int64(math.Max(math.Ceil(rate), 1)),
+	}
+}
+
+// take removes a token from the bucket if there is one,
+// otherwise it returns the time until the next token is available.
+func (bucket *tokenBucket) take(now int64) (bool, time.Duration) {
+	for {
+		tat := atomic.LoadInt64(&bucket.tat)
+		next := tat
+
+		if next < now {
+			next = now
+		}
+
+		next += bucket.interval
+
+		if next-now > bucket.window {
+			return false, time.Duration(next - now - bucket.window)
+		}
+
+		if atomic.CompareAndSwapInt64(&bucket.tat, tat, next) {
+			return true, 0
+		}
+	}
+}
+
+// limit wraps a handler of the statistics endpoint with the rate limit.
+func (stats *Collector) limit(handler http.HandlerFunc) http.HandlerFunc {
+	if stats.rateLimiter == nil {
+		return handler
+	}
+
+	return func(response http.ResponseWriter, request *http.Request) {
+		allowed, wait := stats.rateLimiter.allow(clientAddress(request))
+
+		if !allowed {
+			seconds := int(math.Ceil(wait.Seconds()))
+
+			if seconds < 1 {
+				seconds = 1
+			}
+
+			response.Header().Set("Retry-After", strconv.Itoa(seconds))
+			http.Error(response, "Too many requests to the statistics", http.StatusTooManyRequests)
+			return
+		}
+
+		handler(response, request)
+	}
+}
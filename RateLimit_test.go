@@ -0,0 +1,83 @@
+package stats_test
+
+import (
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/aerogo/stats"
+)
+
+// installMux installs the endpoint tree of a collector on a new ServeMux.
+func installMux(t *testing.T, collector *stats.Collector) *http.ServeMux {
+	t.Helper()
+	mux := http.NewServeMux()
+
+	if _, err := collector.Install(mux); err != nil {
+		t.Fatal(err)
+	}
+
+	return mux
+}
+
+// get requests the path from the mux and returns the status code.
+func get(mux http.Handler, path string) int {
+	response := httptest.NewRecorder()
+	mux.ServeHTTP(response, httptest.NewRequest(http.MethodGet, path, nil))
+	return response.Code
+}
+
+func TestRateLimitDefault(t *testing.T) {
+	mux := installMux(t, stats.NewCollector())
+
+	for i := 0; i < 5; i++ {
+		if code := get(mux, stats.DefaultPath); code != http.StatusOK {
+			t.Fatalf("request %d: status %d", i+1, code)
+		}
+	}
+
+	if code := get(mux, stats.DefaultPath); code != http.StatusTooManyRequests {
+		t.Errorf("request over the default client limit: status %d", code)
+	}
+}
+
+func TestRateLimitDisabled(t *testing.T) {
+	mux := installMux(t, stats.NewCollector(stats.WithRateLimit(0, 0)))
+
+	for i := 0; i < 50; i++ {
+		if code := get(mux, stats.DefaultPath); code != http.StatusOK {
+			t.Fatalf("request %d: status %d", i+1, code)
+		}
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	mux := installMux(t, stats.NewCollector(stats.WithRateLimit(0, 5)))
+
+	for i := 0; i < 5; i++ {
+		if code := get(mux, stats.DefaultPath); code != http.StatusOK {
+			t.Fatalf("request %d: status %d", i+1, code)
+		}
+	}
+
+	response := httptest.NewRecorder()
+	mux.ServeHTTP(response, httptest.NewRequest(http.MethodGet, stats.DefaultPath, nil))
+
+	if response.Code != http.StatusTooManyRequests || response.Header().Get("Retry-After") == "" {
+		t.Errorf("request over the limit: status %d, Retry-After %q", response.Code, response.Header().Get("Retry-After"))
+	}
+}
+
+func TestRateLimitExemptsAssets(t *testing.T) {
+	dashboard := template.Must(template.New("dashboard").Funcs(stats.TemplateFuncs).Parse(`{{asset "style.css"}}`))
+	files := fstest.MapFS{"style.css": {Data: []byte("body {}")}}
+	mux := installMux(t, stats.NewCollector(stats.WithRateLimit(1, 1), stats.WithDashboard(dashboard, files)))
+
+	for i := 0; i < 20; i++ {
+		if code := get(mux, stats.DefaultPath+"/assets/missing.css"); code != http.StatusNotFound {
+			t.Fatalf("asset request %d: status %d", i+1, code)
+		}
+	}
+}
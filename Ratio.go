@@ -0,0 +1,166 @@
+package stats
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// Ratio counts hits and misses, e.g. of a cache.
+type Ratio struct {
+	hits   uint64
+	misses uint64
+
+	mutex      sync.Mutex
+	lastHits   uint64
+	lastMisses uint64
+	recentRate *float64
+}
+
+// RatioStats are the exported statistics of a ratio.
+// RecentHitRate is the hit rate during the last sample interval and nil if there were no events.
+type RatioStats struct {
+	Hits          uint64
+	Misses        uint64
+	Count         uint64
+	HitRate       float64
+	RecentHitRate *float64
+}
+
+// Ratio returns the hit/miss ratio with the given name, creating it if needed.
+// It is safe to call from multiple goroutines and always returns the same ratio for the same name.
+func (stats *Collector) Ratio(name string) *Ratio {
+	stats.ratiosMutex.RLock()
+	ratio, exists := stats.ratios[name]
+	stats.ratiosMutex.RUnlock()
+
+	if exists {
+		return ratio
+	}
+
+	stats.ratiosMutex.Lock()
+	defer stats.ratiosMutex.Unlock()
+
+	ratio, exists = stats.ratios[name]
+
+	if !exists {
+		ratio = &Ratio{}
+		stats.ratios[name] = ratio
+	}
+
+	return ratio
+}
+
+// Hit counts a hit.
+func (ratio *Ratio) Hit() {
+	atomic.AddUint64(&ratio.hits, 1)
+}
+
+// Miss counts a miss.
+func (ratio *Ratio) Miss() {
+	atomic.AddUint64(&ratio.misses, 1)
+}
+
+// HitRate returns the ratio of hits to all events since the start.
+func (ratio *Ratio) HitRate() float64 {
+	return hitRate(atomic.LoadUint64(&ratio.hits), atomic.LoadUint64(&ratio.misses))
+}
+
+// sample computes the hit rate since the last sample.
+func (ratio *Ratio) sample() {
+	hits := atomic.LoadUint64(&ratio.hits)
+	misses := atomic.LoadUint64(&ratio.misses)
+
+	ratio.mutex.Lock()
+	defer ratio.mutex.Unlock()
+
+	recentHits := counterDelta(hits, ratio.lastHits)
+	recentMisses := counterDelta(misses, ratio.lastMisses)
+	ratio.lastHits = hits
+	ratio.lastMisses = misses
+	ratio.recentRate = nil
+
+	if recentHits+recentMisses > 0 {
+		rate := hitRate(recentHits, recentMisses)
+		ratio.recentRate = &rate
+	}
+}
+
+// reset sets all counters to zero.
+func (ratio *Ratio) reset() {
+	atomic.StoreUint64(&ratio.hits, 0)
+	atomic.StoreUint64(&ratio.misses, 0)
+
+	ratio.mutex.Lock()
+	ratio.lastHits = 0
+	ratio.lastMisses = 0
+	ratio.recentRate = nil
+	ratio.mutex.Unlock()
+}
+
+// Stats returns the exported statistics of the ratio.
+func (ratio *Ratio) Stats() RatioStats {
+	hits := atomic.LoadUint64(&ratio.hits)
+	misses := atomic.LoadUint64(&ratio.misses)
+
+	ratio.mutex.Lock()
+	recentRate := ratio.recentRate
+	ratio.mutex.Unlock()
+
+	return RatioStats{
+		Hits:          hits,
+		Misses:        misses,
+		Count:         hits + misses,
+		HitRate:       hitRate(hits, misses),
+		RecentHitRate: recentRate,
+	}
+}
+
+// ratioStats returns the statistics of all ratios.
+func (stats *Collector) ratioStats() map[string]RatioStats {
+	stats.ratiosMutex.RLock()
+	defer stats.ratiosMutex.RUnlock()
+
+	if len(stats.ratios) == 0 {
+		return nil
+	}
+
+	ratios := make(map[string]RatioStats, len(stats.ratios))
+
+	for name, ratio := range stats.ratios {
+		ratios[name] = ratio.Stats()
+	}
+
+	return ratios
+}
+
+// ratioNames returns the names of all ratios in alphabetical order.
+func ratioNames(ratios map[string]RatioStats) []string {
+	names := make([]string, 0, len(ratios))
+
+	for name := range ratios {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// sampleRatios computes the recent hit rate of all ratios.
+func (stats *Collector) sampleRatios() {
+	stats.ratiosMutex.RLock()
+	defer stats.ratiosMutex.RUnlock()
+
+	for _, ratio := range stats.ratios {
+		ratio.sample()
+	}
+}
+
+// hitRate returns the ratio of hits to all events.
+func hitRate(hits uint64, misses uint64) float64 {
+	if hits+misses == 0 {
+		return 0
+	}
+
+	return float64(hits) / float64(hits+misses)
+}
@@ -0,0 +1,49 @@
+package stats
+
+import "time"
+
+// recentWindows are the windows of the recent load, like the system load average.
+var recentWindows = []struct {
+	name     string
+	duration time.Duration
+}{
+	{"1m", time.Minute},
+	{"5m", 5 * time.Minute},
+	{"15m", 15 * time.Minute},
+}
+
+// RecentLoad contains the throughput and the average response time of all routes in a recent window.
+type RecentLoad struct {
+	RequestsPerSecond float64
+	ResponseTimeMs    float64
+	Errors            uint64
+}
+
+// recentLoad returns the load of the last 1, 5 and 15 completed minutes.
+// The current minute is left out so that the values don't drop at the start of every minute,
+// windows longer than the uptime are averaged over the uptime.
+// The minute history must have more buckets than the longest window, one is taken by the current minute.
+func (stats *Collector) recentLoad(now time.Time) map[string]RecentLoad {
+	completed := now.Truncate(time.Minute)
+	recent := make(map[string]RecentLoad, len(recentWindows))
+
+	for _, window := range recentWindows {
+		requests, errors, responseTime := stats.minutes.window(completed.Add(-time.Minute), window.duration)
+		elapsed := window.duration
+
+		if start := completed.Sub(stats.started); start < elapsed {
+			elapsed = start
+		}
+
+		load := RecentLoad{Errors: errors}
+
+		if requests > 0 && elapsed > 0 {
+			load.RequestsPerSecond = float64(requests) / elapsed.Seconds()
+			load.ResponseTimeMs = roundMilliseconds(time.Duration(responseTime / requests))
+		}
+
+		recent[window.name] = load
+	}
+
+	return recent
+}
@@ -0,0 +1,79 @@
+package stats_test
+
+import (
+	"context"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aerogo/stats"
+	"github.com/aerogo/stats/testutil"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+// checkGolden compares the output with the golden file or, with -update, rewrites the file.
+func checkGolden(t *testing.T, golden string, output string) {
+	t.Helper()
+	golden = filepath.Join("testdata", golden)
+
+	if *update {
+		if err := os.WriteFile(golden, []byte(output), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		return
+	}
+
+	expected, err := os.ReadFile(golden)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if output != string(expected) {
+		t.Errorf("the output differs from %s:\n%s", golden, output)
+	}
+}
+
+// TestRenderGolden renders the same routes in every format and compares the output with the golden files,
+// so that the renderers agree on the units of the response times.
+func TestRenderGolden(t *testing.T) {
+	units := map[string]time.Duration{
+		"auto": 0,
+		"ms":   time.Millisecond,
+	}
+
+	for name, unit := range units {
+		clock := testutil.NewFakeClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+		collector := stats.NewCollector(
+			stats.WithClock(clock),
+			stats.WithStartTime(clock.Now()),
+			stats.WithSystemProvider(&testutil.FakeSystemProvider{}),
+			stats.WithTimeUnit(unit),
+		)
+
+		collector.Track("/micro", 250*time.Microsecond)
+		collector.Track("/milli", 15*time.Millisecond)
+		collector.Track("/seconds", 2*time.Second)
+		clock.Advance(time.Minute)
+
+		for _, format := range []string{"json", "text", "html"} {
+			request := httptest.NewRequest(http.MethodGet, stats.DefaultPath+"?sections=routes,history&format="+format, nil)
+			response := httptest.NewRecorder()
+			collector.ServeHTTP(response, request)
+
+			if response.Code != http.StatusOK {
+				t.Fatalf("%s: status %d: %s", format, response.Code, response.Body.String())
+			}
+
+			checkGolden(t, name+"."+format+".golden", response.Body.String())
+		}
+
+		collector.Close(context.Background())
+	}
+}
@@ -0,0 +1,61 @@
+package stats
+
+import (
+	"strconv"
+	"time"
+)
+
+// Protocols of recorded requests
+const (
+	ProtocolHTTP = "http"
+	ProtocolGRPC = "grpc"
+)
+
+// RequestRecord describes a single finished request.
+// Duration is the total time. HandlerTime is the time spent in the handler
+// and zero if it wasn't measured separately from the middlewares.
+// Segments contains the total time of each named segment of the request.
+// Conditional is true if the request contained an If-None-Match header.
+// Error is the message passed to RecordError.
+// Headers contains the request headers enabled with WithSampleHeaders.
+// Protocol is empty for HTTP requests.
+// TraceID is the ID of the distributed trace the request belongs to, if any.
+// Visitor is the hashed identifier of the client returned by RequestVisitor, 0 if unknown.
+// Origin is the client origin returned by RequestOrigin, e.g. a country code.
+// FirstByte is the time until the status code or the first byte of the response was written, 0 if unknown.
+// Streamed is true if the handler flushed the response before returning, e.g. for server-sent events.
+// Aborted is true if the client disconnected or cancelled the request before the response was complete.
+type RequestRecord struct {
+	Route        string
+	Protocol     string
+	Method       string
+	StatusCode   int
+	Duration     time.Duration
+	HandlerTime  time.Duration
+	RequestSize  uint64
+	ResponseSize uint64
+	Segments     map[string]time.Duration
+	Conditional  bool
+	Error        string
+	Headers      map[string]string
+	TraceID      string
+	Visitor      uint64
+	Origin       string
+	FirstByte    time.Duration
+	Streamed     bool
+	Aborted      bool
+}
+
+// failed tells you whether the request resulted in a server error.
+func (record *RequestRecord) failed() bool {
+	return record.StatusCode >= 500
+}
+
+// statusClass returns the class of the status code like "2xx".
+func (record *RequestRecord) statusClass() string {
+	if record.StatusCode < 100 || record.StatusCode > 599 {
+		return "other"
+	}
+
+	return strconv.Itoa(record.StatusCode/100) + "xx"
+}
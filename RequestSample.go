@@ -0,0 +1,111 @@
+package stats
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// RequestSample is a single request kept as an example for its route.
+// Headers are only included if they were enabled with WithSampleHeaders.
+type RequestSample struct {
+	Time          time.Time
+	Method        string
+	StatusCode    int
+	DurationMs    float64
+	HandlerTimeMs float64            `json:",omitempty"`
+	Segments      map[string]float64 `json:",omitempty"`
+	Headers       map[string]string  `json:",omitempty"`
+	TraceID       string             `json:",omitempty"`
+}
+
+// RouteSamples are representative requests of a route.
+type RouteSamples struct {
+	Fastest *RequestSample
+	Slowest *RequestSample
+	Recent  *RequestSample
+}
+
+// sampledRequest is a stored request record.
+type sampledRequest struct {
+	time   time.Time
+	record RequestRecord
+}
+
+// routeSamples keeps the fastest, the slowest and the most recent request of a route.
+type routeSamples struct {
+	fastest sampledRequest
+	slowest sampledRequest
+	recent  sampledRequest
+	mutex   sync.Mutex
+}
+
+// WithSampleHeaders includes the given request headers in the request samples of each route.
+// By default, no header data is kept.
+func WithSampleHeaders(names ...string) Option {
+	return func(stats *Collector) {
+		for _, name := range names {
+			stats.sampleHeaders = append(stats.sampleHeaders, http.CanonicalHeaderKey(name))
+		}
+	}
+}
+
+// record updates the samples with the given request.
+func (samples *routeSamples) record(now time.Time, record *RequestRecord) {
+	sample := sampledRequest{
+		time:   now,
+		record: *record,
+	}
+
+	samples.mutex.Lock()
+	defer samples.mutex.Unlock()
+
+	if samples.fastest.time.IsZero() || record.Duration < samples.fastest.record.Duration {
+		samples.fastest = sample
+	}
+
+	if samples.slowest.time.IsZero() || record.Duration > samples.slowest.record.Duration {
+		samples.slowest = sample
+	}
+
+	samples.recent = sample
+}
+
+// Samples returns the exported samples or nil if no request has been recorded.
+func (samples *routeSamples) Samples() *RouteSamples {
+	samples.mutex.Lock()
+	defer samples.mutex.Unlock()
+
+	if samples.recent.time.IsZero() {
+		return nil
+	}
+
+	return &RouteSamples{
+		Fastest: samples.fastest.export(),
+		Slowest: samples.slowest.export(),
+		Recent:  samples.recent.export(),
+	}
+}
+
+// export converts the stored record to a sample.
+func (sample *sampledRequest) export() *RequestSample {
+	exported := &RequestSample{
+		Time:          sample.time,
+		Method:        sample.record.Method,
+		StatusCode:    sample.record.StatusCode,
+		DurationMs:    float64(sample.record.Duration) / float64(time.Millisecond),
+		HandlerTimeMs: float64(sample.record.HandlerTime) / float64(time.Millisecond),
+		Headers:       sample.record.Headers,
+		TraceID:       sample.record.TraceID,
+	}
+
+	if len(sample.record.Segments) > 0 {
+		exported.Segments = make(map[string]float64, len(sample.record.Segments))
+
+		for name, duration := range sample.record.Segments {
+			exported.Segments[name] = float64(duration) / float64(time.Millisecond)
+		}
+	}
+
+	return exported
+}
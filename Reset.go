@@ -0,0 +1,100 @@
+package stats
+
+import (
+	"net/http"
+	"sync/atomic"
+)
+
+// Reset sets the request statistics to zero, e.g. to measure a benchmark in isolation:
+// all routes, the request histories, the ratios, the slow request log, the application counters and timers,
+// the bandwidth, the origins and the counts of warm-up and ignored requests. A warm-up period starts again.
+// The system and memory statistics, gauges, the heatmap, the error log, the unique visitors
+// and the SLO counters, which cover their own windows, are kept.
+// Requests in flight during the reset may be left out.
+func (stats *Collector) Reset() {
+	now := stats.clock.Now()
+
+	stats.routesMutex.Lock()
+	stats.routes = make(map[string]*RouteStatistics)
+	stats.routesMutex.Unlock()
+
+	if stats.warmup > 0 {
+		atomic.StoreInt64(&stats.warmupUntil, now.Add(stats.warmup).UnixNano())
+	}
+
+	atomic.StoreUint64(&stats.warmupCount, 0)
+	atomic.StoreUint64(&stats.ignoredCount, 0)
+	atomic.StoreUint64(&stats.bandwidth.received, 0)
+	atomic.StoreUint64(&stats.bandwidth.sent, 0)
+
+	if stats.origins != nil {
+		stats.origins.reset()
+	}
+
+	stats.history.reset()
+	stats.minutes.reset()
+	stats.peakRate.Take()
+	stats.slowLog.Clear()
+	stats.metrics.reset()
+	stats.epochs.clear()
+
+	stats.ratiosMutex.RLock()
+
+	for _, ratio := range stats.ratios {
+		ratio.reset()
+	}
+
+	stats.ratiosMutex.RUnlock()
+}
+
+// reset sets the counters and timers to zero, gauges keep their current values.
+func (registry *metricRegistry) reset() {
+	registry.mutex.RLock()
+	defer registry.mutex.RUnlock()
+
+	for _, counter := range registry.counters {
+		atomic.StoreUint64(&counter.(*Counter).value, 0)
+	}
+
+	for _, timer := range registry.timers {
+		timer := timer.(*Timer)
+		atomic.StoreUint64(&timer.count, 0)
+		atomic.StoreUint64(&timer.total, 0)
+		atomic.StoreUint64(&timer.min, 0)
+		atomic.StoreUint64(&timer.max, 0)
+	}
+}
+
+// reset removes the counters of all origins.
+func (origins *origins) reset() {
+	origins.mutex.Lock()
+	origins.counters = make(map[string]*originCounters)
+	origins.mutex.Unlock()
+}
+
+// requireMiddleware refuses requests to routes that change or pin the statistics
+// unless an endpoint middleware is configured to authenticate them.
+func (stats *Collector) requireMiddleware(handler http.HandlerFunc) http.HandlerFunc {
+	return func(response http.ResponseWriter, request *http.Request) {
+		if len(stats.middlewares) == 0 {
+			http.Error(response, "Forbidden without an endpoint middleware, see WithEndpointMiddleware", http.StatusForbidden)
+			return
+		}
+
+		handler(response, request)
+	}
+}
+
+// resetStatistics resets the statistics.
+func (stats *Collector) resetStatistics(response http.ResponseWriter, request *http.Request) {
+	stats.Reset()
+	response.WriteHeader(http.StatusNoContent)
+}
+
+// pinSnapshot serves a snapshot of all sections as JSON and keeps it as the base for diffs,
+// so that "since" with its ETag returns the changes since then, no matter how many snapshots are served in between.
+func (stats *Collector) pinSnapshot(response http.ResponseWriter, request *http.Request) {
+	snapshot := stats.Snapshot()
+	response.Header().Set("ETag", `"`+stats.epochs.pin(snapshot)+`"`)
+	writeJSON(response, snapshot)
+}
@@ -0,0 +1,38 @@
+package stats_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aerogo/stats"
+	"github.com/aerogo/stats/testutil"
+)
+
+func TestResetRestartsWarmup(t *testing.T) {
+	clock := testutil.NewFakeClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	collector := stats.NewCollector(stats.WithClock(clock), stats.WithWarmup(time.Minute))
+	defer collector.Close(context.Background())
+
+	collector.Track("/", time.Millisecond)
+	clock.Advance(2 * time.Minute)
+	collector.Track("/", time.Millisecond)
+	collector.Reset()
+
+	if app := collector.SnapshotSections(stats.SectionApp).App; app.WarmupRequests != 0 || app.Requests != 0 {
+		t.Fatalf("%d requests and %d warm-up requests after the reset", app.Requests, app.WarmupRequests)
+	}
+
+	collector.Track("/", time.Millisecond)
+
+	if warmup := collector.SnapshotSections(stats.SectionApp).App.WarmupRequests; warmup != 1 {
+		t.Errorf("%d warm-up requests after the reset, expected 1", warmup)
+	}
+
+	clock.Advance(2 * time.Minute)
+	collector.Track("/", time.Millisecond)
+
+	if warmup := collector.SnapshotSections(stats.SectionApp).App.WarmupRequests; warmup != 1 {
+		t.Errorf("%d warm-up requests after the warm-up period, expected 1", warmup)
+	}
+}
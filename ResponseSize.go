@@ -0,0 +1,82 @@
+package stats
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// ResponseSizeBuckets are the upper bounds (exclusive) of the response size histogram in bytes.
+// Responses at or above the last bound are counted in an additional overflow bucket.
+// Exporters can map them directly to histogram buckets.
+var ResponseSizeBuckets = [...]uint64{
+	1 << 10,
+	10 << 10,
+	100 << 10,
+	1 << 20,
+	10 << 20,
+}
+
+// SizeBucket is a single bucket of the response size histogram.
+// The upper bound is 0 for the overflow bucket.
+type SizeBucket struct {
+	UpperBound uint64
+	Count      uint64
+}
+
+// LargestResponse is the largest response observed on a route.
+type LargestResponse struct {
+	Size uint64
+	Time time.Time
+}
+
+// responseSizes is a fixed-bucket histogram of response sizes.
+type responseSizes struct {
+	counts  [len(ResponseSizeBuckets) + 1]uint64
+	total   uint64
+	largest timedMax
+}
+
+// record adds a response size to the histogram.
+func (sizes *responseSizes) record(now time.Time, size uint64) {
+	bucket := len(ResponseSizeBuckets)
+
+	for i, bound := range ResponseSizeBuckets {
+		if size < bound {
+			bucket = i
+			break
+		}
+	}
+
+	atomic.AddUint64(&sizes.counts[bucket], 1)
+	atomic.AddUint64(&sizes.total, size)
+	sizes.largest.record(now, size)
+}
+
+// Buckets returns the histogram.
+func (sizes *responseSizes) Buckets() []SizeBucket {
+	buckets := make([]SizeBucket, len(sizes.counts))
+
+	for i := range sizes.counts {
+		buckets[i].Count = atomic.LoadUint64(&sizes.counts[i])
+
+		if i < len(ResponseSizeBuckets) {
+			buckets[i].UpperBound = ResponseSizeBuckets[i]
+		}
+	}
+
+	return buckets
+}
+
+// Largest returns the largest response or nil if no response has been recorded.
+func (sizes *responseSizes) Largest() *LargestResponse {
+	size, observed := sizes.largest.load()
+
+	if size == 0 {
+		return nil
+	}
+
+	return &LargestResponse{
+		Size: size,
+		Time: observed,
+	}
+}
@@ -0,0 +1,86 @@
+package stats
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+)
+
+// responseWriter wraps a response writer to capture the status code, the response size,
+// the time the response started and whether writing failed, which usually means the client has disconnected.
+type responseWriter struct {
+	http.ResponseWriter
+	statusCode int
+	size       uint64
+	writeError bool
+	firstByte  time.Time
+	flushed    bool
+}
+
+// WriteHeader captures the status code.
+// Informational responses like 103 Early Hints are followed by the final one and not captured,
+// only 101 Switching Protocols ends the response.
+func (writer *responseWriter) WriteHeader(statusCode int) {
+	informational := statusCode >= 100 && statusCode < 200 && statusCode != http.StatusSwitchingProtocols
+
+	if writer.statusCode == 0 && !informational {
+		writer.statusCode = statusCode
+		writer.firstByte = time.Now()
+	}
+
+	writer.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Write implies a 200 status code if no header has been written.
+func (writer *responseWriter) Write(data []byte) (int, error) {
+	if writer.statusCode == 0 {
+		writer.statusCode = http.StatusOK
+		writer.firstByte = time.Now()
+	}
+
+	n, err := writer.ResponseWriter.Write(data)
+	writer.size += uint64(n)
+
+	if err != nil {
+		writer.writeError = true
+	}
+
+	return n, err
+}
+
+// Flush passes flushes through to streaming responses.
+func (writer *responseWriter) Flush() {
+	flusher, ok := writer.ResponseWriter.(http.Flusher)
+
+	if ok {
+		writer.flushed = true
+		flusher.Flush()
+	}
+}
+
+// Hijack passes connection takeovers (e.g. WebSockets) through.
+func (writer *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := writer.ResponseWriter.(http.Hijacker)
+
+	if !ok {
+		return nil, nil, errors.New("ResponseWriter does not implement http.Hijacker")
+	}
+
+	return hijacker.Hijack()
+}
+
+// Unwrap returns the wrapped response writer, e.g. for http.ResponseController.
+func (writer *responseWriter) Unwrap() http.ResponseWriter {
+	return writer.ResponseWriter
+}
+
+// StatusCode returns the captured status code, defaulting to 200.
+func (writer *responseWriter) StatusCode() int {
+	if writer.statusCode == 0 {
+		return http.StatusOK
+	}
+
+	return writer.statusCode
+}
@@ -0,0 +1,43 @@
+package stats_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aerogo/stats"
+)
+
+func TestResponseWriterSkipsInformationalStatus(t *testing.T) {
+	collector := stats.NewCollector()
+	defer collector.Close(context.Background())
+
+	handler := collector.Recorder("/", http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		response.WriteHeader(http.StatusEarlyHints)
+		response.WriteHeader(http.StatusNotFound)
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	classes := collector.Snapshot().Routes.Popular[0].StatusClasses
+
+	if classes["4xx"] != 1 || classes["1xx"] != 0 {
+		t.Errorf("status classes %v, expected the final 404", classes)
+	}
+}
+
+func TestResponseWriterUnwrap(t *testing.T) {
+	collector := stats.NewCollector()
+	defer collector.Close(context.Background())
+	recorder := httptest.NewRecorder()
+
+	handler := collector.Recorder("/", http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		unwrapper, ok := response.(interface{ Unwrap() http.ResponseWriter })
+
+		if !ok || unwrapper.Unwrap() != recorder {
+			t.Errorf("the response writer doesn't unwrap to the original one")
+		}
+	}))
+
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+}
@@ -0,0 +1,52 @@
+package stats
+
+import "net/http"
+
+// RouteDetail contains the detailed statistics for a single route.
+type RouteDetail struct {
+	Route
+	Histogram []HDRBucket             `json:",omitempty"`
+	Overflow  uint64                  `json:",omitempty"`
+	History   []HistoryBucket         `json:",omitempty"`
+	Segments  map[string]SegmentStats `json:",omitempty"`
+
+	ResponseSizes   []SizeBucket
+	LargestResponse *LargestResponse `json:",omitempty"`
+	Caching         CachingStats
+	Samples         *RouteSamples `json:",omitempty"`
+}
+
+// showRoute serves the detailed statistics of the route given in the "route" query parameter.
+func (stats *Collector) showRoute(response http.ResponseWriter, request *http.Request) {
+	path := request.URL.Query().Get("route")
+
+	stats.routesMutex.RLock()
+	routeStats, exists := stats.routes[path]
+	stats.routesMutex.RUnlock()
+
+	if !exists {
+		http.Error(response, "Unknown route: "+path, http.StatusNotFound)
+		return
+	}
+
+	detail := RouteDetail{
+		Route:           *stats.routeInfo(path, routeStats, stats.quantiles),
+		ResponseSizes:   routeStats.responseSizes.Buckets(),
+		LargestResponse: routeStats.responseSizes.Largest(),
+		Caching:         routeStats.caching.Stats(),
+		Samples:         routeStats.samples.Samples(),
+	}
+
+	if histogram, ok := routeStats.Distribution().(*HDRHistogramDistribution); ok {
+		detail.Histogram = histogram.Buckets()
+		detail.Overflow = histogram.Overflow()
+	}
+
+	detail.Segments = routeStats.Segments()
+
+	if routeStats.history != nil {
+		detail.History = routeStats.history.Buckets(stats.clock.Now())
+	}
+
+	writeJSON(response, detail)
+}
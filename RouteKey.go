@@ -0,0 +1,58 @@
+package stats
+
+import "net/http"
+
+// OverflowRoute accumulates the requests of all new routes once the limit set via WithMaxRoutes is reached.
+const OverflowRoute = "(other)"
+
+// WithRouteKeyFunc sets the function that derives the route key from the request and the pattern
+// the router matched, e.g. to prefix the host for multi-tenant virtual hosts or to strip a version prefix.
+// It is called once per request by the recording middlewares and its result is the route
+// everywhere, in the snapshot, the exporters and the route detail endpoint.
+// The key should only depend on stable parts of the request, keys containing IDs or
+// query parameters fragment the statistics into countless routes.
+func WithRouteKeyFunc(key func(request *http.Request, pattern string) string) Option {
+	return func(stats *Collector) {
+		stats.routeKey = key
+	}
+}
+
+// WithMaxRoutes limits the number of tracked routes, requests to further routes are counted as OverflowRoute.
+// 0 means no limit.
+func WithMaxRoutes(max int) Option {
+	return func(stats *Collector) {
+		stats.maxRoutes = max
+	}
+}
+
+// WithMethodBreakdown records requests under their method and route, e.g. "GET /users",
+// so that the statistics of GET and POST requests to the same path aren't merged.
+// Unlike MethodRouteKey it also applies to records passed to Record and Track directly,
+// don't combine both or the method is prepended twice.
+func WithMethodBreakdown() Option {
+	return func(stats *Collector) {
+		stats.methodBreakdown = true
+	}
+}
+
+// MethodRouteKey is a route key function that keys the statistics by method and pattern, e.g. "GET /users".
+func MethodRouteKey(request *http.Request, pattern string) string {
+	return request.Method + " " + pattern
+}
+
+// RouteKey returns the route a request matching the pattern is recorded under, for adapters filling RequestRecord.Route.
+// It returns the pattern unless a key function is configured.
+func (stats *Collector) RouteKey(request *http.Request, pattern string) string {
+	if stats.routeKey == nil {
+		return pattern
+	}
+
+	return stats.routeKey(request, pattern)
+}
+
+// routeLimitReached tells you whether no more routes can be created,
+// because of the route limit or because the memory budget is exhausted.
+// It must be called with the lock of the routes held.
+func (stats *Collector) routeLimitReached() bool {
+	return (stats.maxRoutes > 0 && len(stats.routes) >= stats.maxRoutes) || stats.degradation() >= DegradationRoutes
+}
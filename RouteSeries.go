@@ -0,0 +1,183 @@
+package stats
+
+import (
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// routeSeries splits the requests of a route by method and status code
+// and its response times by method, for the labeled metric exports.
+type routeSeries struct {
+	requests sync.Map
+	latency  sync.Map
+	bounds   []time.Duration
+}
+
+// seriesKey identifies a request counter of a route.
+type seriesKey struct {
+	method string
+	code   int
+}
+
+// requestSeries is a request counter of a route.
+type requestSeries struct {
+	method   string
+	code     string
+	requests uint64
+}
+
+// latencySeries is the response time histogram of a route for a single method.
+type latencySeries struct {
+	method    string
+	bounds    []time.Duration
+	counts    []uint64
+	sum       time.Duration
+	exemplars []exemplar
+}
+
+// count adds a finished request to the counter of its method and status code.
+func (series *routeSeries) count(record *RequestRecord) {
+	key := seriesKey{method: record.Method, code: record.StatusCode}
+	counter, exists := series.requests.Load(key)
+
+	if !exists {
+		counter, _ = series.requests.LoadOrStore(key, new(uint64))
+	}
+
+	atomic.AddUint64(counter.(*uint64), 1)
+}
+
+// histogram returns the response time histogram of the given method.
+func (series *routeSeries) histogram(method string) *latencyHistogram {
+	histogram, exists := series.latency.Load(method)
+
+	if !exists {
+		histogram, _ = series.latency.LoadOrStore(method, newLatencyHistogram(series.bounds))
+	}
+
+	return histogram.(*latencyHistogram)
+}
+
+// load returns the request counters and histograms sorted by method and status code.
+// Only exemplars recorded after the given time are included.
+func (series *routeSeries) load(since time.Time) ([]requestSeries, []latencySeries) {
+	var requests []requestSeries
+	var latencies []latencySeries
+
+	series.requests.Range(func(key, counter interface{}) bool {
+		requests = append(requests, requestSeries{
+			method:   key.(seriesKey).method,
+			code:     strconv.Itoa(key.(seriesKey).code),
+			requests: atomic.LoadUint64(counter.(*uint64)),
+		})
+
+		return true
+	})
+
+	series.latency.Range(func(method, histogram interface{}) bool {
+		latency := latencySeries{method: method.(string), bounds: series.bounds}
+		latency.counts, latency.sum, latency.exemplars = histogram.(*latencyHistogram).load(since)
+		latencies = append(latencies, latency)
+		return true
+	})
+
+	sort.Slice(requests, func(i, j int) bool {
+		if requests[i].method != requests[j].method {
+			return requests[i].method < requests[j].method
+		}
+
+		return requests[i].code < requests[j].code
+	})
+
+	sort.Slice(latencies, func(i, j int) bool {
+		return latencies[i].method < latencies[j].method
+	})
+
+	return requests, latencies
+}
+
+// quantile estimates the response time at quantile q from the histograms of all methods,
+// interpolating linearly within the bucket and limited to the observed minimum and maximum.
+// It returns false if no request has been recorded.
+func (series *routeSeries) quantile(q float64, min time.Duration, max time.Duration) (time.Duration, bool) {
+	return bucketQuantile(series.bounds, series.bucketCounts(), q, min, max)
+}
+
+// bucketCounts returns the bucket counts of the histograms of all methods added up.
+func (series *routeSeries) bucketCounts() []uint64 {
+	counts := make([]uint64, len(series.bounds)+1)
+
+	series.latency.Range(func(method, histogram interface{}) bool {
+		for i := range counts {
+			counts[i] += atomic.LoadUint64(&histogram.(*latencyHistogram).counts[i])
+		}
+
+		return true
+	})
+
+	return counts
+}
+
+// bucketQuantile estimates the response time at quantile q from histogram bucket counts,
+// interpolating linearly within the bucket. It returns false if the counts are all zero.
+// The estimate is clamped to the observed minimum and maximum, a zero maximum means unknown,
+// so that a bucket with a single distinct response time doesn't report a value that never occurred.
+func bucketQuantile(bounds []time.Duration, counts []uint64, q float64, min time.Duration, max time.Duration) (time.Duration, bool) {
+	estimate, ok := bucketEstimate(bounds, counts, q)
+
+	if !ok || max == 0 {
+		return estimate, ok
+	}
+
+	if estimate < min {
+		return min, true
+	}
+
+	if estimate > max {
+		return max, true
+	}
+
+	return estimate, true
+}
+
+// bucketEstimate interpolates the response time at quantile q within its histogram bucket.
+func bucketEstimate(bounds []time.Duration, counts []uint64, q float64) (time.Duration, bool) {
+	total := uint64(0)
+
+	for _, count := range counts {
+		total += count
+	}
+
+	if total == 0 {
+		return 0, false
+	}
+
+	rank := q * float64(total)
+	cumulative := uint64(0)
+
+	for i, count := range counts {
+		if count == 0 || float64(cumulative+count) < rank {
+			cumulative += count
+			continue
+		}
+
+		// The last bucket has no upper bound
+		if i == len(bounds) {
+			return bounds[i-1], true
+		}
+
+		lower := time.Duration(0)
+
+		if i > 0 {
+			lower = bounds[i-1]
+		}
+
+		fraction := (rank - float64(cumulative)) / float64(count)
+		return lower + time.Duration(fraction*float64(bounds[i]-lower)), true
+	}
+
+	return bounds[len(bounds)-1], true
+}
@@ -0,0 +1,25 @@
+package stats_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aerogo/stats"
+)
+
+func TestBucketQuantileWithinObservedRange(t *testing.T) {
+	collector := stats.NewCollector()
+	defer collector.Close(context.Background())
+
+	for i := 0; i < 10; i++ {
+		collector.Track("/fast", 250*time.Microsecond)
+		collector.Track("/slow", 15*time.Millisecond)
+	}
+
+	for route, expected := range map[string]time.Duration{"/fast": 250 * time.Microsecond, "/slow": 15 * time.Millisecond} {
+		if median, _ := collector.Percentile(route, 50); median != expected {
+			t.Errorf("median of %s is %v, expected %v", route, median, expected)
+		}
+	}
+}
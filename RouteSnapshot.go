@@ -0,0 +1,62 @@
+package stats
+
+import "sort"
+
+// RouteSnapshot is a copy of the statistics of a single route at the time it was taken.
+// Modifying it has no effect on the collector.
+type RouteSnapshot struct {
+	Route
+	Caching CachingStats
+}
+
+// trackedRoute is a route in a copy of the route map.
+type trackedRoute struct {
+	path  string
+	stats *RouteStatistics
+}
+
+// trackedRoutes returns a consistent copy of the tracked routes sorted by path.
+// The statistics of the routes keep changing, only the set of routes is fixed.
+func (stats *Collector) trackedRoutes() []trackedRoute {
+	stats.routesMutex.RLock()
+	routes := make([]trackedRoute, 0, len(stats.routes))
+
+	for path, routeStats := range stats.routes {
+		routes = append(routes, trackedRoute{path: path, stats: routeStats})
+	}
+
+	stats.routesMutex.RUnlock()
+
+	sort.Slice(routes, func(i, j int) bool {
+		return routes[i].path < routes[j].path
+	})
+
+	return routes
+}
+
+// Routes returns the paths of all tracked routes in alphabetical order.
+func (stats *Collector) Routes() []string {
+	routes := stats.trackedRoutes()
+	paths := make([]string, len(routes))
+
+	for index, route := range routes {
+		paths[index] = route.path
+	}
+
+	return paths
+}
+
+// ForEachRoute calls fn with a snapshot of every tracked route in alphabetical order
+// until fn returns false. Routes added during the iteration are not included.
+func (stats *Collector) ForEachRoute(fn func(path string, route RouteSnapshot) bool) {
+	for _, route := range stats.trackedRoutes() {
+		snapshot := RouteSnapshot{
+			Route:   *stats.routeInfo(route.path, route.stats, stats.quantiles),
+			Caching: route.stats.caching.Stats(),
+		}
+
+		if !fn(route.path, snapshot) {
+			return
+		}
+	}
+}
@@ -1,21 +1,206 @@
 package stats
 
-import "sync/atomic"
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
 
 // RouteStatistics includes performance statistics for a specific route.
 type RouteStatistics struct {
-	requestCount uint64
-	responseTime uint64
+	requestCount    uint64
+	responseTime    uint64
+	minResponseTime uint64
+	maxResponseTime timedMax
+	errorCount      uint64
+	warmupCount     uint64
+	measuredCount   uint64
+	abortedCount    uint64
+	abortedTime     uint64
+	firstByte       firstByteCounters
+	statusClasses   statusClassLatency
+	statusCounts    statusClassCounts
+	grpc            uint32
+	splitCount      uint64
+	splitHandler    uint64
+	splitTotal      uint64
+	distribution    atomic.Value
+	sampled         uint32
+	sampleCounter   uint64
+	series          routeSeries
+	history         *History
+	minutes         *History
+	responseSizes   responseSizes
+	requestBytes    uint64
+	caching         cachingCounters
+	samples         routeSamples
+	segments        map[string]*segmentStats
+	segmentsMutex   sync.Mutex
+	lastSeen        int64
+	inFlight        int32
+	class           atomic.Value
+	method          atomic.Value
+	methodMixed     uint32
+	apdex           apdexCounters
+}
+
+// record adds a finished request to the route statistics.
+// Requests made during the warm-up period are counted but excluded from the latency statistics,
+// like requests aborted by the client unless abortedLatency is true.
+func (stats *RouteStatistics) record(now time.Time, record *RequestRecord, warmup bool, abortedLatency bool) {
+	atomic.AddUint64(&stats.requestCount, 1)
+	atomic.StoreInt64(&stats.lastSeen, now.UnixNano())
+	stats.series.count(record)
+
+	if record.Protocol == ProtocolGRPC && atomic.LoadUint32(&stats.grpc) == 0 {
+		atomic.StoreUint32(&stats.grpc, 1)
+	}
+
+	stats.recordMethod(record.Method)
+
+	if record.failed() {
+		atomic.AddUint64(&stats.errorCount, 1)
+	}
+
+	stats.statusCounts.record(record)
+
+	if record.Aborted {
+		atomic.AddUint64(&stats.abortedCount, 1)
+		atomic.AddUint64(&stats.abortedTime, uint64(record.Duration))
+	}
+
+	switch {
+	case warmup:
+		atomic.AddUint64(&stats.warmupCount, 1)
+	case record.Aborted && !abortedLatency:
+	default:
+		stats.recordLatency(now, record)
+	}
+
+	stats.responseSizes.record(now, record.ResponseSize)
+	atomic.AddUint64(&stats.requestBytes, record.RequestSize)
+	stats.caching.record(record)
+
+	if atomic.LoadUint32(&stats.sampled) == 0 {
+		stats.samples.record(now, record)
+	}
+
+	if record.Segments != nil {
+		stats.recordSegments(record.Segments)
+	}
+
+	if stats.history != nil {
+		stats.history.record(now, record)
+	}
+}
+
+// recordLatency adds the response time of a finished request to the latency statistics.
+func (stats *RouteStatistics) recordLatency(now time.Time, record *RequestRecord) {
+	atomic.AddUint64(&stats.measuredCount, 1)
+	atomic.AddUint64(&stats.responseTime, uint64(record.Duration))
+	atomicMin(&stats.minResponseTime, uint64(record.Duration))
+	stats.maxResponseTime.record(now, uint64(record.Duration))
+	stats.firstByte.record(record)
+	stats.statusClasses.record(record)
+
+	if record.HandlerTime > 0 {
+		atomic.AddUint64(&stats.splitCount, 1)
+		atomic.AddUint64(&stats.splitHandler, uint64(record.HandlerTime))
+		atomic.AddUint64(&stats.splitTotal, uint64(record.Duration))
+	}
+
+	if distribution := stats.Distribution(); distribution != nil && stats.takeSample() {
+		distribution.Record(record.Duration)
+	}
+
+	stats.series.histogram(record.Method).record(now, record.Duration, record.TraceID)
+	stats.minutes.record(now, record)
 }
 
 // AverageResponseTime returns the average response time of the route.
-func (stats *RouteStatistics) AverageResponseTime() float64 {
-	requestCount := atomic.LoadUint64(&stats.requestCount)
+// Requests made during the warm-up period and aborted requests are not included.
+func (stats *RouteStatistics) AverageResponseTime() time.Duration {
+	requestCount := atomic.LoadUint64(&stats.measuredCount)
 	responseTime := atomic.LoadUint64(&stats.responseTime)
 
 	if requestCount == 0 {
 		return 0
 	}
 
-	return float64(responseTime) / float64(requestCount)
+	return time.Duration(responseTime / requestCount)
+}
+
+// AverageResponseTimeMs returns the average response time of the route in milliseconds.
+//
+// Deprecated: AverageResponseTime returned milliseconds before it returned a time.Duration,
+// this wrapper will be removed in the next release.
+func (stats *RouteStatistics) AverageResponseTimeMs() float64 {
+	return float64(stats.AverageResponseTime()) / float64(time.Millisecond)
+}
+
+// Quantile returns the estimated response time at quantile q.
+// Without a distribution backend, it is interpolated from the latency histogram buckets.
+func (stats *RouteStatistics) Quantile(q float64) time.Duration {
+	duration, _ := stats.quantile(q)
+	return duration
+}
+
+// quantile returns the estimated response time at quantile q, false if no request has been measured.
+func (stats *RouteStatistics) quantile(q float64) (time.Duration, bool) {
+	if distribution := stats.Distribution(); distribution != nil {
+		return distribution.Quantile(q), atomic.LoadUint64(&stats.measuredCount) > 0
+	}
+
+	max, _ := stats.maxResponseTime.load()
+	return stats.series.quantile(q, time.Duration(atomic.LoadUint64(&stats.minResponseTime)), time.Duration(max))
+}
+
+// distributionValue wraps the distribution so that it can be replaced atomically, also by nil.
+type distributionValue struct {
+	Distribution
+}
+
+// Distribution returns the response time distribution of the route,
+// nil if none is configured or it was dropped to stay within the memory budget.
+func (stats *RouteStatistics) Distribution() Distribution {
+	value, _ := stats.distribution.Load().(distributionValue)
+	return value.Distribution
+}
+
+// recordMethod remembers the method of the first request and whether later requests used another one.
+func (stats *RouteStatistics) recordMethod(method string) {
+	if atomic.LoadUint32(&stats.methodMixed) == 1 {
+		return
+	}
+
+	first, _ := stats.method.Load().(string)
+
+	if first == "" {
+		stats.method.CompareAndSwap(nil, method)
+		first, _ = stats.method.Load().(string)
+	}
+
+	if method != first {
+		atomic.StoreUint32(&stats.methodMixed, 1)
+	}
+}
+
+// Method returns the method of all requests to the route,
+// empty if requests with different methods were recorded under the route.
+func (stats *RouteStatistics) Method() string {
+	if atomic.LoadUint32(&stats.methodMixed) == 1 {
+		return ""
+	}
+
+	method, _ := stats.method.Load().(string)
+	return method
+}
+
+// Protocol returns the protocol of the requests to the route.
+func (stats *RouteStatistics) Protocol() string {
+	if atomic.LoadUint32(&stats.grpc) == 1 {
+		return ProtocolGRPC
+	}
+
+	return ProtocolHTTP
 }
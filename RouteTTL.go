@@ -0,0 +1,92 @@
+package stats
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// ExpiredRoute is the route that accumulates the totals of evicted routes
+// when enabled via WithExpiredRouteAggregate.
+const ExpiredRoute = "(expired)"
+
+// WithRouteTTL removes routes that haven't been requested for the given duration.
+// A background janitor checks the routes every half TTL and stops on Close.
+func WithRouteTTL(ttl time.Duration) Option {
+	return func(stats *Collector) {
+		stats.routeTTL = ttl
+	}
+}
+
+// WithExpiredRouteAggregate folds the request, error and response time totals of evicted routes
+// into the ExpiredRoute entry, so that RequestCount never decreases.
+func WithExpiredRouteAggregate() Option {
+	return func(stats *Collector) {
+		stats.expiredAggregate = true
+	}
+}
+
+// startJanitor periodically evicts idle routes.
+func (stats *Collector) startJanitor() {
+	interval := stats.routeTTL / 2
+
+	if interval < time.Second {
+		interval = time.Second
+	}
+
+	stats.goroutine(func() {
+		ticker := stats.clock.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stats.done:
+				return
+			case now := <-ticker.C():
+				stats.evictRoutes(now)
+			}
+		}
+	})
+}
+
+// evictRoutes removes the routes that have been idle for longer than the TTL.
+// It holds the same lock as route creation and skips routes with requests in flight.
+func (stats *Collector) evictRoutes(now time.Time) {
+	deadline := now.Add(-stats.routeTTL).UnixNano()
+
+	stats.routesMutex.Lock()
+	defer stats.routesMutex.Unlock()
+
+	for path, route := range stats.routes {
+		if path == ExpiredRoute || atomic.LoadInt64(&route.lastSeen) >= deadline || atomic.LoadInt32(&route.inFlight) > 0 {
+			continue
+		}
+
+		delete(stats.routes, path)
+
+		if stats.expiredAggregate {
+			expired, exists := stats.routes[ExpiredRoute]
+
+			if !exists {
+				expired = stats.newRoute(ExpiredRoute)
+				stats.routes[ExpiredRoute] = expired
+			}
+
+			expired.fold(route)
+		}
+	}
+}
+
+// fold adds the lifetime totals of another route.
+func (stats *RouteStatistics) fold(route *RouteStatistics) {
+	atomic.AddUint64(&stats.requestCount, atomic.LoadUint64(&route.requestCount))
+	atomic.AddUint64(&stats.errorCount, atomic.LoadUint64(&route.errorCount))
+	atomic.AddUint64(&stats.warmupCount, atomic.LoadUint64(&route.warmupCount))
+	atomic.AddUint64(&stats.measuredCount, atomic.LoadUint64(&route.measuredCount))
+	atomic.AddUint64(&stats.abortedCount, atomic.LoadUint64(&route.abortedCount))
+	atomic.AddUint64(&stats.abortedTime, atomic.LoadUint64(&route.abortedTime))
+	atomic.AddUint64(&stats.responseTime, atomic.LoadUint64(&route.responseTime))
+	stats.firstByte.fold(&route.firstByte)
+	stats.statusClasses.fold(&route.statusClasses)
+	stats.statusCounts.fold(&route.statusCounts)
+	stats.apdex.fold(&route.apdex)
+}
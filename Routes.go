@@ -0,0 +1,79 @@
+package stats
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// summaryParameters control which routes the route summaries include and how they are measured.
+type summaryParameters struct {
+	quantiles []float64
+	window    time.Duration
+	class     string
+	method    string
+	limit     int
+}
+
+// showRoutes serves only the route summaries as JSON.
+// It doesn't read any runtime or system statistics, which makes it cheap enough for frequent polling.
+// The "quantiles", "window", "class", "method" and "limit" query parameters work like on the main route.
+func (stats *Collector) showRoutes(response http.ResponseWriter, request *http.Request) {
+	parameters, err := stats.routeParameters(request.URL.Query())
+
+	if err != nil {
+		http.Error(response, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	writeJSON(response, stats.routeSummary(parameters, stats.clock.Now()))
+}
+
+// defaultSummaryParameters returns the configured defaults of the route summaries.
+func (stats *Collector) defaultSummaryParameters() summaryParameters {
+	return summaryParameters{
+		quantiles: stats.quantiles,
+		window:    stats.summaryWindow,
+		limit:     stats.listLimit,
+	}
+}
+
+// routeParameters parses the query parameters that control the route summaries.
+func (stats *Collector) routeParameters(query url.Values) (summaryParameters, error) {
+	parameters := stats.defaultSummaryParameters()
+	parameters.class = query.Get("class")
+	parameters.method = strings.ToUpper(query.Get("method"))
+
+	if list := query.Get("quantiles"); list != "" {
+		var err error
+		parameters.quantiles, err = parseQuantiles(list)
+
+		if err != nil {
+			return parameters, err
+		}
+	}
+
+	if value := query.Get("window"); value != "" {
+		var err error
+		parameters.window, err = parseWindow(value)
+
+		if err != nil {
+			return parameters, err
+		}
+	}
+
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+
+		if err != nil || limit < 0 {
+			return parameters, errors.New("Invalid limit: " + value)
+		}
+
+		parameters.limit = limit
+	}
+
+	return parameters, nil
+}
@@ -0,0 +1,44 @@
+package stats
+
+import (
+	"math"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+)
+
+// RuntimeInfo contains the runtime settings that explain performance differences between environments.
+// GOMemLimit is empty if there is no memory limit and GOGC is "off" if the collector is disabled.
+type RuntimeInfo struct {
+	GOMAXPROCS int
+	GOGC       string
+	GOMemLimit string `json:",omitempty"`
+	Race       bool
+	CGO        bool
+}
+
+// readRuntimeInfo reads the runtime settings.
+// Apart from GOMAXPROCS they don't change at runtime unless the application changes them itself, so this is done once on startup.
+func readRuntimeInfo() RuntimeInfo {
+	info := RuntimeInfo{
+		GOMAXPROCS: runtime.GOMAXPROCS(0),
+		GOGC:       "off",
+		Race:       raceEnabled,
+		CGO:        cgoEnabled,
+	}
+
+	// SetGCPercent is the only way to read the current value, so it is restored immediately
+	percent := debug.SetGCPercent(-1)
+	debug.SetGCPercent(percent)
+
+	if percent >= 0 {
+		info.GOGC = strconv.Itoa(percent)
+	}
+
+	// A negative limit only reads the current value
+	if limit := debug.SetMemoryLimit(-1); limit != math.MaxInt64 {
+		info.GOMemLimit = strconv.FormatInt(limit, 10)
+	}
+
+	return info
+}
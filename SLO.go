@@ -0,0 +1,152 @@
+package stats
+
+import (
+	"strings"
+	"time"
+)
+
+// sloBurnWindows are the windows burn rates are reported for.
+// They pair up for multiwindow alerting: 1h with 5m, 6h with 30m and 3d with 6h.
+var sloBurnWindows = []struct {
+	name     string
+	duration time.Duration
+}{
+	{"5m", 5 * time.Minute},
+	{"30m", 30 * time.Minute},
+	{"1h", time.Hour},
+	{"6h", 6 * time.Hour},
+	{"3d", 72 * time.Hour},
+}
+
+// WithSLO tracks a service level objective: the share of requests to route that succeed within threshold
+// should be at least objective (e.g. 0.995) over window (e.g. 30 days).
+// The route is a prefix of the routes it covers or "*" for all of them. Multiple objectives can be set.
+// SLOs with an objective outside of (0, 1) or without a window are ignored.
+//
+// The counters are kept separately from the route statistics, evicting routes doesn't affect them.
+func WithSLO(name string, route string, threshold time.Duration, objective float64, window time.Duration) Option {
+	return func(stats *Collector) {
+		if objective <= 0 || objective >= 1 || window <= 0 {
+			return
+		}
+
+		hours := int((window + time.Hour - 1) / time.Hour)
+
+		if hours < 72 {
+			hours = 72
+		}
+
+		stats.slos = append(stats.slos, &slo{
+			name:      name,
+			route:     route,
+			threshold: threshold,
+			objective: objective,
+			window:    window,
+			minutes:   NewHistory(time.Minute, 60),
+			hours:     NewHistory(time.Hour, hours),
+		})
+	}
+}
+
+// slo counts the good and bad requests of a service level objective.
+// Bad requests are counted as errors of the histories.
+type slo struct {
+	name      string
+	route     string
+	threshold time.Duration
+	objective float64
+	window    time.Duration
+	minutes   *History
+	hours     *History
+}
+
+// SLOStats contains the compliance with a service level objective over its window.
+// ErrorBudgetRemaining is the share of the allowed bad requests that is left, negative once exceeded.
+// BurnRates are the rates at which the error budget is consumed in recent windows,
+// 1 means the budget lasts exactly the SLO window.
+type SLOStats struct {
+	Name                 string
+	Route                string
+	ThresholdMs          float64
+	Objective            float64
+	Window               string
+	Requests             uint64
+	BadRequests          uint64
+	Compliance           float64
+	ErrorBudgetRemaining float64
+	BurnRates            map[string]float64
+}
+
+// recordSLOs counts a finished request for all objectives covering its route.
+func (stats *Collector) recordSLOs(now time.Time, record *RequestRecord) {
+	for _, objective := range stats.slos {
+		if objective.covers(record.Route) {
+			bad := record.failed() || (!record.Aborted && record.Duration > objective.threshold)
+			objective.minutes.count(now, record.Duration, bad)
+			objective.hours.count(now, record.Duration, bad)
+		}
+	}
+}
+
+// covers tells you whether the route counts towards the objective.
+func (objective *slo) covers(route string) bool {
+	return objective.route == "*" || strings.HasPrefix(route, objective.route)
+}
+
+// Stats returns the compliance with the objective.
+func (objective *slo) Stats(now time.Time) SLOStats {
+	requests, bad, _ := objective.hours.window(now, objective.window)
+	budget := 1 - objective.objective
+
+	exported := SLOStats{
+		Name:                 objective.name,
+		Route:                objective.route,
+		ThresholdMs:          roundMilliseconds(objective.threshold),
+		Objective:            objective.objective,
+		Window:               objective.window.String(),
+		Requests:             requests,
+		BadRequests:          bad,
+		Compliance:           1,
+		ErrorBudgetRemaining: 1,
+		BurnRates:            make(map[string]float64, len(sloBurnWindows)),
+	}
+
+	if requests > 0 {
+		badRatio := float64(bad) / float64(requests)
+		exported.Compliance = 1 - badRatio
+		exported.ErrorBudgetRemaining = 1 - badRatio/budget
+	}
+
+	for _, window := range sloBurnWindows {
+		history := objective.hours
+
+		if window.duration <= time.Hour {
+			history = objective.minutes
+		}
+
+		requests, bad, _ := history.window(now, window.duration)
+
+		if requests > 0 {
+			exported.BurnRates[window.name] = float64(bad) / float64(requests) / budget
+		} else {
+			exported.BurnRates[window.name] = 0
+		}
+	}
+
+	return exported
+}
+
+// sloStats returns the compliance with all objectives, nil if none are configured.
+func (stats *Collector) sloStats(now time.Time) []SLOStats {
+	if len(stats.slos) == 0 {
+		return nil
+	}
+
+	exported := make([]SLOStats, len(stats.slos))
+
+	for index, objective := range stats.slos {
+		exported[index] = objective.Stats(now)
+	}
+
+	return exported
+}
@@ -0,0 +1,38 @@
+package stats
+
+import "time"
+
+// defaultSampleInterval is the interval of the periodic sampler.
+const defaultSampleInterval = 10 * time.Second
+
+// WithSampleInterval sets the interval at which periodic measurements like throughput are taken.
+// Non-positive intervals are ignored and keep the default of 10 seconds.
+func WithSampleInterval(interval time.Duration) Option {
+	return func(stats *Collector) {
+		if interval > 0 {
+			stats.sampleInterval = interval
+		}
+	}
+}
+
+// startSampler runs all periodic measurements in a single goroutine.
+func (stats *Collector) startSampler() {
+	stats.goroutine(func() {
+		ticker := stats.clock.NewTicker(stats.sampleInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stats.done:
+				return
+			case now := <-ticker.C():
+				stats.bandwidth.sample(now)
+				stats.sampleRatios()
+				stats.sampleCPU(now)
+				stats.sampleThreads()
+				stats.appTrend.sample()
+				stats.enforceMemoryBudget()
+			}
+		}
+	})
+}
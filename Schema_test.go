@@ -0,0 +1,100 @@
+package stats_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aerogo/stats"
+	"github.com/aerogo/stats/testutil"
+)
+
+// fieldPaths collects the paths of all JSON object keys, array elements share the path of the array.
+// Map keys that are data instead of field names are replaced by "*".
+func fieldPaths(prefix string, value interface{}, paths map[string]bool) {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for key, child := range value {
+			if mapFields[prefix] {
+				key = "*"
+			}
+
+			path := strings.TrimPrefix(prefix+"."+key, ".")
+			paths[path] = true
+			fieldPaths(path, child, paths)
+		}
+
+	case []interface{}:
+		for _, child := range value {
+			fieldPaths(prefix+"[]", child, paths)
+		}
+	}
+}
+
+// fieldList returns the sorted field paths of the value, one per line.
+func fieldList(prefix string, value interface{}) string {
+	paths := map[string]bool{}
+	fieldPaths(prefix, value, paths)
+	list := make([]string, 0, len(paths))
+
+	for path := range paths {
+		list = append(list, path)
+	}
+
+	sort.Strings(list)
+	return strings.Join(list, "\n") + "\n"
+}
+
+// mapFields are the paths of maps whose keys depend on the recorded data.
+var mapFields = map[string]bool{
+	"App.Memory.Classes":               true,
+	"Routes.Classes":                   true,
+	"Routes.Expensive[].StatusClasses": true,
+	"Routes.Popular[].StatusClasses":   true,
+	"Routes.Slow[].StatusClasses":      true,
+}
+
+// TestSchemaFields locks the JSON field set of every supported schema version.
+func TestSchemaFields(t *testing.T) {
+	clock := testutil.NewFakeClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	collector := stats.NewCollector(
+		stats.WithClock(clock),
+		stats.WithStartTime(clock.Now()),
+		stats.WithSystemProvider(&testutil.FakeSystemProvider{}),
+	)
+
+	defer collector.Close(context.Background())
+
+	collector.Track("/", 15*time.Millisecond)
+	collector.Track("/slow", 2*time.Second)
+	clock.Advance(time.Minute)
+
+	// The GC statistics are only included once a GC has run
+	runtime.GC()
+
+	for _, version := range []int{stats.SchemaVersion - 1, stats.SchemaVersion} {
+		request := httptest.NewRequest(http.MethodGet, stats.DefaultPath+"?format=json&schema="+strconv.Itoa(version), nil)
+		response := httptest.NewRecorder()
+		collector.ServeHTTP(response, request)
+
+		if response.Code != http.StatusOK {
+			t.Fatalf("schema %d: status %d: %s", version, response.Code, response.Body.String())
+		}
+
+		var output interface{}
+
+		if err := json.Unmarshal(response.Body.Bytes(), &output); err != nil {
+			t.Fatal(err)
+		}
+
+		// A change of the field set is a breaking change that requires a new SchemaVersion
+		checkGolden(t, "schema-v"+strconv.Itoa(version)+".golden", fieldList("", output))
+	}
+}
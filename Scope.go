@@ -0,0 +1,30 @@
+package stats
+
+// Scope is the part of the request processing the response times cover.
+type Scope string
+
+const (
+	// ScopeFull measures the whole middleware stack and the handler.
+	ScopeFull Scope = "full"
+
+	// ScopeHandler measures only the handler.
+	ScopeHandler Scope = "handler"
+
+	// ScopeBoth measures the whole stack and records the handler time separately.
+	ScopeBoth Scope = "both"
+)
+
+// WithScope declares which part of the request processing the recording middlewares measure,
+// ScopeFull by default. Adapters that can control the position of their middleware follow it,
+// the snapshot states it so that response times of differently configured services aren't compared.
+// With net/http, the Arrival middleware together with Recorder corresponds to ScopeBoth.
+func WithScope(scope Scope) Option {
+	return func(stats *Collector) {
+		stats.scope = scope
+	}
+}
+
+// Scope returns the part of the request processing the response times cover.
+func (stats *Collector) Scope() Scope {
+	return stats.scope
+}
@@ -0,0 +1,94 @@
+package stats
+
+import (
+	"errors"
+	"strings"
+)
+
+// Section is a part of the snapshot that can be requested individually.
+type Section string
+
+// Snapshot sections
+const (
+	SectionSystem     Section = "system"
+	SectionApp        Section = "app"
+	SectionRoutes     Section = "routes"
+	SectionHistory    Section = "history"
+	SectionRatios     Section = "ratios"
+	SectionCaching    Section = "caching"
+	SectionComparison Section = "comparison"
+	SectionProcess    Section = "process"
+	SectionHeatmap    Section = "heatmap"
+	SectionOrigins    Section = "origins"
+	SectionSLO        Section = "slo"
+	SectionMetrics    Section = "metrics"
+)
+
+// AllSections contains every snapshot section.
+var AllSections = []Section{
+	SectionSystem,
+	SectionApp,
+	SectionRoutes,
+	SectionHistory,
+	SectionRatios,
+	SectionCaching,
+	SectionComparison,
+	SectionHeatmap,
+	SectionOrigins,
+	SectionSLO,
+	SectionMetrics,
+}
+
+// optionalSections are only included when requested explicitly,
+// because their data is already part of the other sections.
+var optionalSections = []Section{
+	SectionProcess,
+}
+
+// sectionSet is a set of requested sections.
+type sectionSet map[Section]bool
+
+// newSectionSet creates a set of the given sections.
+func newSectionSet(sections []Section) sectionSet {
+	set := sectionSet{}
+
+	for _, section := range sections {
+		set[section] = true
+	}
+
+	return set
+}
+
+// parseSections parses a comma separated list of section names like "routes,app".
+func parseSections(list string) ([]Section, error) {
+	var sections []Section
+
+	for _, field := range strings.Split(list, ",") {
+		section := Section(strings.TrimSpace(field))
+
+		if !isSection(section) {
+			var valid []string
+
+			for _, known := range append(AllSections, optionalSections...) {
+				valid = append(valid, string(known))
+			}
+
+			return nil, errors.New("Unknown section: " + string(section) + " (valid sections: " + strings.Join(valid, ", ") + ")")
+		}
+
+		sections = append(sections, section)
+	}
+
+	return sections, nil
+}
+
+// isSection tells you whether the section exists.
+func isSection(section Section) bool {
+	for _, known := range append(AllSections, optionalSections...) {
+		if section == known {
+			return true
+		}
+	}
+
+	return false
+}
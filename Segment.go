@@ -0,0 +1,167 @@
+package stats
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// maxSegmentNames is the maximum number of distinct segment names tracked per route.
+// Further names are counted as "other".
+const maxSegmentNames = 32
+
+// otherSegment is the name that collects segments beyond the limit.
+const otherSegment = "other"
+
+// requestStateKey is the context key of the state of a request tracked by the Recorder.
+var requestStateKey = &contextKey{"request state"}
+
+// requestState collects the data of a request while it is being handled.
+type requestState struct {
+	segments map[string]time.Duration
+	err      string
+	mutex    sync.Mutex
+}
+
+// TrackRequest returns a context in which StartSegment and RecordError collect the segments and the error of a request,
+// and a function that adds them to the record of the request. The Recorder middleware does this for net/http handlers,
+// integrations with other frameworks call it before the handler.
+func TrackRequest(ctx context.Context) (context.Context, func(*RequestRecord)) {
+	state := &requestState{}
+	return context.WithValue(ctx, requestStateKey, state), state.apply
+}
+
+// apply adds the segments and the error message of the request to its record.
+func (state *requestState) apply(record *RequestRecord) {
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+
+	record.Segments = state.segments
+
+	if state.err != "" {
+		record.Error = state.err
+	}
+}
+
+// Segment measures a part of a request, e.g. database queries.
+type Segment struct {
+	name  string
+	start time.Time
+	state *requestState
+}
+
+// SegmentStats are the aggregated timings of a segment name on a route.
+type SegmentStats struct {
+	Count     uint64
+	TotalMs   float64
+	AverageMs float64
+}
+
+// segmentStats are the counters of a segment name on a route.
+type segmentStats struct {
+	count uint64
+	total uint64
+}
+
+// StartSegment starts measuring a named part of the request handled with ctx:
+//
+//	ctx, segment := stats.StartSegment(ctx, "db")
+//	defer segment.End()
+//
+// The time is attributed to the route of the request. Overlapping segments with the same name are summed.
+// Outside of a request tracked by the Recorder middleware or TrackRequest, the segment does nothing.
+func StartSegment(ctx context.Context, name string) (context.Context, *Segment) {
+	state, _ := ctx.Value(requestStateKey).(*requestState)
+
+	return ctx, &Segment{
+		name:  name,
+		start: time.Now(),
+		state: state,
+	}
+}
+
+// End finishes the segment.
+func (segment *Segment) End() {
+	if segment.state == nil {
+		return
+	}
+
+	duration := time.Since(segment.start)
+
+	segment.state.mutex.Lock()
+
+	if segment.state.segments == nil {
+		segment.state.segments = map[string]time.Duration{}
+	}
+
+	segment.state.segments[segment.name] += duration
+	segment.state.mutex.Unlock()
+}
+
+// recordSegments adds the segments of a request to the route statistics.
+func (stats *RouteStatistics) recordSegments(segments map[string]time.Duration) {
+	for name, duration := range segments {
+		counters := stats.segment(name)
+		atomic.AddUint64(&counters.count, 1)
+		atomic.AddUint64(&counters.total, uint64(duration))
+	}
+}
+
+// segment returns the counters of a segment name, creating them if needed.
+func (stats *RouteStatistics) segment(name string) *segmentStats {
+	stats.segmentsMutex.Lock()
+	defer stats.segmentsMutex.Unlock()
+
+	if stats.segments == nil {
+		stats.segments = map[string]*segmentStats{}
+	}
+
+	counters, exists := stats.segments[name]
+
+	if exists {
+		return counters
+	}
+
+	if len(stats.segments) >= maxSegmentNames {
+		name = otherSegment
+		counters, exists = stats.segments[name]
+
+		if exists {
+			return counters
+		}
+	}
+
+	counters = &segmentStats{}
+	stats.segments[name] = counters
+	return counters
+}
+
+// Segments returns the aggregated timings of all segments of the route.
+func (stats *RouteStatistics) Segments() map[string]SegmentStats {
+	stats.segmentsMutex.Lock()
+	defer stats.segmentsMutex.Unlock()
+
+	if len(stats.segments) == 0 {
+		return nil
+	}
+
+	segments := make(map[string]SegmentStats, len(stats.segments))
+
+	for name, counters := range stats.segments {
+		count := atomic.LoadUint64(&counters.count)
+		total := float64(atomic.LoadUint64(&counters.total)) / float64(time.Millisecond)
+		exported := SegmentStats{
+			Count:   count,
+			TotalMs: total,
+		}
+
+		if count > 0 {
+			exported.AverageMs = total / float64(count)
+		}
+
+		segments[name] = exported
+	}
+
+	return segments
+}
@@ -0,0 +1,23 @@
+package stats_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aerogo/stats"
+)
+
+func TestTrackRequest(t *testing.T) {
+	ctx, finish := stats.TrackRequest(context.Background())
+	_, segment := stats.StartSegment(ctx, "db")
+	segment.End()
+	stats.RecordError(ctx, errors.New("query failed"))
+
+	record := stats.RequestRecord{Route: "/"}
+	finish(&record)
+
+	if _, exists := record.Segments["db"]; !exists || record.Error != "query failed" {
+		t.Errorf("segments %v and error %q, expected the db segment and the recorded error", record.Segments, record.Error)
+	}
+}
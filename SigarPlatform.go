@@ -0,0 +1,60 @@
+//go:build !windows
+
+package stats
+
+import (
+	"os"
+	"syscall"
+	"time"
+
+	sigar "github.com/cloudfoundry/gosigar"
+)
+
+// LoadAverage returns the system load.
+func (sigarProvider) LoadAverage() (LoadAverage, error) {
+	avg := sigar.LoadAverage{}
+	err := avg.Get()
+	return LoadAverage{One: avg.One, Five: avg.Five, Fifteen: avg.Fifteen}, err
+}
+
+// Memory returns the memory usage.
+func (sigarProvider) Memory() (MemoryInfo, error) {
+	mem := sigar.Mem{}
+	err := mem.Get()
+
+	return MemoryInfo{
+		Total:      mem.Total,
+		Used:       mem.Used,
+		Free:       mem.Free,
+		ActualUsed: mem.ActualUsed,
+		ActualFree: mem.ActualFree,
+	}, err
+}
+
+// Uptime returns the time since the system was booted.
+func (sigarProvider) Uptime() (time.Duration, error) {
+	uptime := sigar.Uptime{}
+	err := uptime.Get()
+	return time.Duration(uptime.Length * float64(time.Second)), err
+}
+
+// FDUsage returns the file descriptor usage of the current process.
+func (sigarProvider) FDUsage() (FDUsage, error) {
+	usage := sigar.ProcFDUsage{}
+	err := usage.Get(os.Getpid())
+	return FDUsage{Open: usage.Open, Limit: usage.SoftLimit}, err
+}
+
+// rusageCPU returns the CPU time of the current process via getrusage.
+func rusageCPU() (ProcessCPUTime, error) {
+	usage := syscall.Rusage{}
+
+	if err := syscall.Getrusage(syscall.RUSAGE_SELF, &usage); err != nil {
+		return ProcessCPUTime{}, err
+	}
+
+	return ProcessCPUTime{
+		User:   time.Duration(usage.Utime.Nano()),
+		System: time.Duration(usage.Stime.Nano()),
+	}, nil
+}
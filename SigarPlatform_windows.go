@@ -0,0 +1,76 @@
+package stats
+
+import (
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+// gosigar has no load average and file descriptor usage on Windows
+// and its memory and uptime implementations are incomplete,
+// so those are read directly from kernel32 instead.
+var (
+	kernel32                 = syscall.NewLazyDLL("kernel32.dll")
+	procGlobalMemoryStatusEx = kernel32.NewProc("GlobalMemoryStatusEx")
+	procGetTickCount64       = kernel32.NewProc("GetTickCount64")
+)
+
+// memoryStatusEx is the MEMORYSTATUSEX structure.
+type memoryStatusEx struct {
+	Length               uint32
+	MemoryLoad           uint32
+	TotalPhys            uint64
+	AvailPhys            uint64
+	TotalPageFile        uint64
+	AvailPageFile        uint64
+	TotalVirtual         uint64
+	AvailVirtual         uint64
+	AvailExtendedVirtual uint64
+}
+
+// LoadAverage is not available on Windows.
+func (sigarProvider) LoadAverage() (LoadAverage, error) {
+	return LoadAverage{}, ErrUnsupported
+}
+
+// Memory returns the memory usage via GlobalMemoryStatusEx.
+// Windows doesn't report caches separately, so the actual values equal the raw ones.
+func (sigarProvider) Memory() (MemoryInfo, error) {
+	status := memoryStatusEx{}
+	status.Length = uint32(unsafe.Sizeof(status))
+	ok, _, err := procGlobalMemoryStatusEx.Call(uintptr(unsafe.Pointer(&status)))
+
+	if ok == 0 {
+		return MemoryInfo{}, err
+	}
+
+	used := status.TotalPhys - status.AvailPhys
+
+	return MemoryInfo{
+		Total:      status.TotalPhys,
+		Used:       used,
+		Free:       status.AvailPhys,
+		ActualUsed: used,
+		ActualFree: status.AvailPhys,
+	}, nil
+}
+
+// Uptime returns the time since the system was booted via GetTickCount64.
+func (sigarProvider) Uptime() (time.Duration, error) {
+	if err := procGetTickCount64.Find(); err != nil {
+		return 0, err
+	}
+
+	ticks, _, _ := procGetTickCount64.Call()
+	return time.Duration(ticks) * time.Millisecond, nil
+}
+
+// FDUsage is not available on Windows.
+func (sigarProvider) FDUsage() (FDUsage, error) {
+	return FDUsage{}, ErrUnsupported
+}
+
+// rusageCPU is not available on Windows.
+func rusageCPU() (ProcessCPUTime, error) {
+	return ProcessCPUTime{}, ErrUnsupported
+}
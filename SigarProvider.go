@@ -0,0 +1,87 @@
+package stats
+
+import (
+	"errors"
+	"os"
+	"time"
+
+	sigar "github.com/cloudfoundry/gosigar"
+)
+
+// ErrUnsupported is returned by a SystemProvider for metrics
+// that are not available on the current platform.
+// Unsupported metrics are omitted from the system statistics.
+var ErrUnsupported = errors.New("not supported on this platform")
+
+// sigarProvider reads the system statistics with gosigar.
+type sigarProvider struct{}
+
+// Swap returns the swap usage.
+func (sigarProvider) Swap() (SwapInfo, error) {
+	swap := sigar.Swap{}
+	err := swap.Get()
+	return SwapInfo{Total: swap.Total, Used: swap.Used, Free: swap.Free}, err
+}
+
+// CPU returns the cumulative CPU times.
+func (sigarProvider) CPU() (CPUTimes, error) {
+	cpu := sigar.Cpu{}
+	err := cpu.Get()
+
+	return CPUTimes{
+		User:    cpu.User,
+		Nice:    cpu.Nice,
+		Sys:     cpu.Sys,
+		Idle:    cpu.Idle,
+		Wait:    cpu.Wait,
+		Irq:     cpu.Irq,
+		SoftIrq: cpu.SoftIrq,
+		Stolen:  cpu.Stolen,
+	}, err
+}
+
+// ProcessCPU returns the CPU time of the current process,
+// falling back to getrusage if gosigar can't read it.
+func (sigarProvider) ProcessCPU() (ProcessCPUTime, error) {
+	procTime := sigar.ProcTime{}
+
+	if err := procTime.Get(os.Getpid()); err != nil {
+		return rusageCPU()
+	}
+
+	return ProcessCPUTime{
+		User:   time.Duration(procTime.User) * time.Millisecond,
+		System: time.Duration(procTime.Sys) * time.Millisecond,
+	}, nil
+}
+
+// processStates maps the gosigar run states to their names.
+var processStates = map[sigar.RunState]string{
+	sigar.RunStateSleep:   "sleeping",
+	sigar.RunStateRun:     "running",
+	sigar.RunStateStop:    "stopped",
+	sigar.RunStateZombie:  "zombie",
+	sigar.RunStateIdle:    "idle",
+	sigar.RunStateUnknown: "unknown",
+}
+
+// ProcessState returns the state of the current process.
+func (sigarProvider) ProcessState() (ProcessState, error) {
+	state := sigar.ProcState{}
+
+	if err := state.Get(os.Getpid()); err != nil {
+		return ProcessState{}, err
+	}
+
+	name, exists := processStates[state.State]
+
+	if !exists {
+		name = string(rune(state.State))
+	}
+
+	return ProcessState{
+		State:    name,
+		Priority: state.Priority,
+		Nice:     state.Nice,
+	}, nil
+}
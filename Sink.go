@@ -0,0 +1,68 @@
+package stats
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Sink receives recorded requests, e.g. to log them as structured events.
+// Emit is called synchronously on the recording path and must be fast.
+type Sink interface {
+	Emit(RequestRecord)
+}
+
+// SinkFilter selects the requests that are passed to a sink.
+type SinkFilter func(*RequestRecord) bool
+
+// sink is a sink together with its filter.
+type sink struct {
+	sink   Sink
+	filter SinkFilter
+}
+
+// WithSink passes recorded requests to the sink after the counters were updated.
+// Emitting every request is expensive at high request rates,
+// the filter selects the requests to emit, nil emits all of them.
+func WithSink(s Sink, filter SinkFilter) Option {
+	return func(stats *Collector) {
+		stats.addSink(s, filter)
+	}
+}
+
+// SampleEvery selects one in n requests.
+func SampleEvery(n uint64) SinkFilter {
+	count := uint64(0)
+
+	return func(record *RequestRecord) bool {
+		return n <= 1 || atomic.AddUint64(&count, 1)%n == 0
+	}
+}
+
+// SlowOrFailed selects requests that took at least threshold, resulted in a server error or recorded an error.
+func SlowOrFailed(threshold time.Duration) SinkFilter {
+	return func(record *RequestRecord) bool {
+		return record.Duration >= threshold || record.failed() || record.Error != ""
+	}
+}
+
+// addSink adds a sink. The list of sinks is copied so that recording never needs a lock.
+func (stats *Collector) addSink(s Sink, filter SinkFilter) {
+	stats.sinksMutex.Lock()
+	defer stats.sinksMutex.Unlock()
+
+	sinks, _ := stats.sinks.Load().([]sink)
+	updated := make([]sink, len(sinks), len(sinks)+1)
+	copy(updated, sinks)
+	stats.sinks.Store(append(updated, sink{sink: s, filter: filter}))
+}
+
+// emit passes a recorded request to all sinks whose filter selects it.
+func (stats *Collector) emit(record *RequestRecord) {
+	sinks, _ := stats.sinks.Load().([]sink)
+
+	for _, s := range sinks {
+		if s.filter == nil || s.filter(record) {
+			s.sink.Emit(*record)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package stats
+
+import (
+	"container/heap"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// SlowLogMode determines which slow requests are kept.
+type SlowLogMode int
+
+const (
+	// SlowLogRecent keeps the most recent requests over the threshold.
+	SlowLogRecent SlowLogMode = iota
+
+	// SlowLogTop keeps the slowest requests over the threshold.
+	SlowLogTop
+)
+
+// Default slow request log settings
+const (
+	defaultSlowLogThreshold = 100 * time.Millisecond
+	defaultSlowLogSize      = 100
+)
+
+// SlowRequest is a single request that exceeded the slow request threshold.
+type SlowRequest struct {
+	Time         time.Time
+	Route        string
+	Method       string
+	StatusCode   int
+	DurationMs   float64
+	ResponseSize uint64
+	TraceID      string `json:",omitempty"`
+
+	duration time.Duration
+}
+
+// slowLog keeps individual slow requests.
+type slowLog struct {
+	threshold time.Duration
+	size      int
+	mode      SlowLogMode
+	requests  slowRequestHeap
+	next      int
+	mutex     sync.Mutex
+}
+
+// slowRequestHeap is a min-heap of slow requests ordered by duration.
+type slowRequestHeap []SlowRequest
+
+func (h slowRequestHeap) Len() int            { return len(h) }
+func (h slowRequestHeap) Less(i, j int) bool  { return h[i].duration < h[j].duration }
+func (h slowRequestHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *slowRequestHeap) Push(x interface{}) { *h = append(*h, x.(SlowRequest)) }
+func (h *slowRequestHeap) Pop() interface{} {
+	old := *h
+	last := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return last
+}
+
+// WithSlowLog configures the log of individual slow requests.
+// Requests faster than the threshold are never logged.
+func WithSlowLog(threshold time.Duration, size int, mode SlowLogMode) Option {
+	return func(stats *Collector) {
+		stats.slowLog = newSlowLog(threshold, size, mode)
+	}
+}
+
+// newSlowLog creates a new slow request log.
+func newSlowLog(threshold time.Duration, size int, mode SlowLogMode) *slowLog {
+	if size < 1 {
+		size = 1
+	}
+
+	return &slowLog{
+		threshold: threshold,
+		size:      size,
+		mode:      mode,
+		requests:  make(slowRequestHeap, 0, size),
+	}
+}
+
+// record adds the request to the log if it exceeded the threshold.
+func (log *slowLog) record(now time.Time, record *RequestRecord) {
+	if record.Duration < log.threshold {
+		return
+	}
+
+	request := SlowRequest{
+		Time:         now,
+		Route:        record.Route,
+		Method:       record.Method,
+		StatusCode:   record.StatusCode,
+		DurationMs:   float64(record.Duration) / float64(time.Millisecond),
+		ResponseSize: record.ResponseSize,
+		TraceID:      record.TraceID,
+		duration:     record.Duration,
+	}
+
+	log.mutex.Lock()
+	defer log.mutex.Unlock()
+
+	if len(log.requests) < log.size {
+		if log.mode == SlowLogTop {
+			heap.Push(&log.requests, request)
+		} else {
+			log.requests = append(log.requests, request)
+		}
+
+		return
+	}
+
+	switch log.mode {
+	case SlowLogTop:
+		if request.duration <= log.requests[0].duration {
+			return
+		}
+
+		log.requests[0] = request
+		heap.Fix(&log.requests, 0)
+
+	default:
+		log.requests[log.next] = request
+		log.next = (log.next + 1) % log.size
+	}
+}
+
+// Requests returns the logged requests, newest first or slowest first depending on the mode.
+func (log *slowLog) Requests() []SlowRequest {
+	log.mutex.Lock()
+	requests := make([]SlowRequest, len(log.requests))
+	copy(requests, log.requests)
+	log.mutex.Unlock()
+
+	if log.mode == SlowLogTop {
+		sort.Slice(requests, func(i, j int) bool {
+			return requests[i].duration > requests[j].duration
+		})
+	} else {
+		sort.Slice(requests, func(i, j int) bool {
+			return requests[i].Time.After(requests[j].Time)
+		})
+	}
+
+	return requests
+}
+
+// Clear removes all logged requests.
+func (log *slowLog) Clear() {
+	log.mutex.Lock()
+	log.requests = log.requests[:0]
+	log.next = 0
+	log.mutex.Unlock()
+}
+
+// ClearSlowRequests removes all entries from the slow request log without affecting any other statistics.
+func (stats *Collector) ClearSlowRequests() {
+	stats.slowLog.Clear()
+}
+
+// showSlowRequests serves the slow request log as JSON.
+func (stats *Collector) showSlowRequests(response http.ResponseWriter, request *http.Request) {
+	writeJSON(response, stats.slowLog.Requests())
+}
+
+// clearSlowRequests clears the slow request log.
+func (stats *Collector) clearSlowRequests(response http.ResponseWriter, request *http.Request) {
+	stats.ClearSlowRequests()
+	response.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,77 @@
+package stats_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aerogo/stats"
+)
+
+// routeNames returns the names of the routes in their order.
+func routeNames(routes []*stats.Route) []string {
+	names := make([]string, len(routes))
+
+	for i, route := range routes {
+		names[i] = route.Route
+	}
+
+	return names
+}
+
+// equalNames tells whether both lists contain the same names in the same order.
+func equalNames(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func TestSlowAndPopularRoutes(t *testing.T) {
+	collector := stats.NewCollector()
+
+	for i := 0; i < 3; i++ {
+		collector.Track("/5ms", 5*time.Millisecond)
+	}
+
+	collector.Track("/15ms", 15*time.Millisecond)
+	collector.Track("/15ms", 15*time.Millisecond)
+	collector.Track("/2s", 2*time.Second)
+	collector.Track("/10ms", 10*time.Millisecond)
+	routes := collector.Snapshot().Routes
+
+	// The default threshold of 10ms is inclusive and the slowest route comes first
+	if slow := routeNames(routes.Slow); !equalNames(slow, []string{"/2s", "/15ms", "/10ms"}) {
+		t.Errorf("slow routes %v", slow)
+	}
+
+	// Every route with a request is popular, the most requested one first
+	popular := routeNames(routes.Popular)
+
+	if len(popular) != 4 || popular[0] != "/5ms" || popular[1] != "/15ms" {
+		t.Errorf("popular routes %v", popular)
+	}
+}
+
+func TestSlowRouteThreshold(t *testing.T) {
+	collector := stats.NewCollector(stats.WithSlowRouteThreshold(time.Second), stats.WithPopularMinRequests(2))
+	collector.Track("/5ms", 5*time.Millisecond)
+	collector.Track("/5ms", 5*time.Millisecond)
+	collector.Track("/15ms", 15*time.Millisecond)
+	collector.Track("/2s", 2*time.Second)
+	routes := collector.Snapshot().Routes
+
+	if slow := routeNames(routes.Slow); !equalNames(slow, []string{"/2s"}) {
+		t.Errorf("slow routes %v", slow)
+	}
+
+	if popular := routeNames(routes.Popular); !equalNames(popular, []string{"/5ms"}) {
+		t.Errorf("popular routes %v", popular)
+	}
+}
@@ -0,0 +1,481 @@
+package stats
+
+import (
+	"runtime"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	humanize "github.com/dustin/go-humanize"
+)
+
+// SchemaVersion is the version of the JSON structure served by the statistics endpoint.
+// It is incremented on every breaking change to the structure.
+const SchemaVersion = 2
+
+// Snapshot contains the statistics at a given point in time.
+// Sections that were not requested are nil.
+// Scope states which part of the request processing the response times cover.
+// The JSON keys are the field names of the snapshot types, changing them requires a new SchemaVersion.
+// It is also the data passed to custom HTML templates.
+type Snapshot struct {
+	SchemaVersion int
+	Generated     time.Time
+	Scope         Scope
+	System        *SystemStats          `json:",omitempty"`
+	App           *AppStats             `json:",omitempty"`
+	Routes        *RouteSummary         `json:",omitempty"`
+	History       []HistoryBucket       `json:",omitempty"`
+	Ratios        map[string]RatioStats `json:",omitempty"`
+	Caching       *CachingStats         `json:",omitempty"`
+	Comparison    *ComparisonStats      `json:",omitempty"`
+	Process       *ProcessStats         `json:",omitempty"`
+	Heatmap       *Heatmap              `json:",omitempty"`
+	Origins       []OriginStats         `json:",omitempty"`
+	SLO           []SLOStats            `json:",omitempty"`
+	Metrics       *MetricStats          `json:",omitempty"`
+
+	timeUnit      time.Duration
+	routeCounters map[string]routeCounters
+}
+
+// AppStats contains statistics about the application.
+type AppStats struct {
+	Go        string
+	Runtime   RuntimeInfo
+	Uptime    string
+	Requests  uint64
+	Recent    map[string]RecentLoad
+	Memory    AppMemoryStats
+	GC        *GCStats         `json:",omitempty"`
+	CPU       *ProcessCPUStats `json:",omitempty"`
+	State     *ProcessState    `json:",omitempty"`
+	Bandwidth BandwidthStats
+	Visitors  *VisitorStats     `json:",omitempty"`
+	Gauges    map[string]uint64 `json:",omitempty"`
+
+	// Current numbers and high-water marks, the thread count includes idle OS threads
+	Goroutines    uint64
+	MaxGoroutines uint64
+	Threads       uint64
+	MaxThreads    uint64
+
+	// Calls into C made by the process since its start
+	CgoCalls uint64
+
+	// Connections accepted by the server, only available with the ConnStateHook
+	Connections *ConnectionStats `json:",omitempty"`
+
+	// Estimated footprint of the statistics, only available with WithMemoryBudget
+	MemoryBudget *MemoryBudgetStats `json:",omitempty"`
+
+	// Requests excluded from the latency statistics because they were made during the warm-up period
+	WarmupRequests uint64 `json:",omitempty"`
+
+	// Requests excluded from all statistics because of their method, see WithIgnoreMethods
+	IgnoredRequests uint64 `json:",omitempty"`
+
+	// Config is only included when enabled via WithConfig or WithFullConfig.
+	// Earlier releases always included the full configuration.
+	Config interface{} `json:",omitempty"`
+}
+
+// ProcessStats contains the runtime statistics of the application without its request statistics.
+type ProcessStats struct {
+	Go     string
+	Uptime string
+	Memory AppMemoryStats
+}
+
+// RouteSummary contains the slowest, the most popular and the failing routes,
+// the routes whose clients often abort requests, the routes that consumed the most server time in total
+// and the routes that transferred the most bytes in their requests and responses.
+// Window is "all" for lifetime statistics or the duration of the recent window they cover,
+// in which case the requests, errors and average and total response times of the routes are from the window.
+//
+// Routes are classified as "static" or "api" unless configured otherwise via Classify,
+// Classes contains the totals of each class.
+type RouteSummary struct {
+	Window    string
+	Classes   map[string]*ClassTotals
+	Slow      []*Route
+	Popular   []*Route
+	Failing   []*Route
+	Aborted   []*Route
+	Expensive []*Route
+	Heavy     []*Route
+
+	// Apdex score of all routes over the whole lifetime, see WithApdexThreshold
+	Apdex *float64 `json:",omitempty"`
+}
+
+// Route statistics
+// Response times are available in nanoseconds and in milliseconds with 3 decimals.
+type Route struct {
+	Route             string
+	Class             string
+	Protocol          string
+	Method            string `json:",omitempty"`
+	Requests          uint64
+	Errors            uint64
+	ErrorRate         float64
+	ResponseTimeNs    int64
+	MinResponseTimeNs int64
+	MaxResponseTimeNs int64
+	ResponseTimeMs    float64
+	MinResponseTimeMs float64
+	MaxResponseTimeMs float64
+	MaxObservedAt     time.Time
+	Percentiles       map[string]float64 `json:",omitempty"`
+	StatusClasses     map[string]uint64  `json:",omitempty"`
+
+	// Deprecated: rounded milliseconds without a unit in the name,
+	// use the fields with the Ns or Ms suffix instead. They will be removed in the next release.
+	ResponseTime    uint64
+	MinResponseTime uint64
+	MaxResponseTime uint64
+
+	// Accumulated response time of all requests and its percentage of the time of all routes
+	TotalTimeMs float64
+	TimeShare   float64
+
+	// Requests excluded from the response times because they were made during the warm-up period
+	WarmupRequests uint64 `json:",omitempty"`
+
+	// Requests aborted by the client, their share of all requests and the average time after which that happened.
+	// They are excluded from the response times unless WithAbortedLatency is used.
+	// These are always lifetime values, even in windowed summaries.
+	ClientAborted  uint64  `json:",omitempty"`
+	AbortRate      float64 `json:",omitempty"`
+	AbortedAfterMs float64 `json:",omitempty"`
+
+	// Average response times of successful, client error and server error responses over the whole lifetime.
+	// They are 0 if the route had no response of the class.
+	ResponseTime2xxMs float64 `json:",omitempty"`
+	ResponseTime4xxMs float64 `json:",omitempty"`
+	ResponseTime5xxMs float64 `json:",omitempty"`
+
+	// Time until the first byte of the response was written, only available for requests measured by the Recorder middleware.
+	// Streaming routes flush their responses and are ranked by it in the Slow summary instead of the response time.
+	Streaming      bool    `json:",omitempty"`
+	FirstByteNs    int64   `json:",omitempty"`
+	FirstByteMs    float64 `json:",omitempty"`
+	MaxFirstByteMs float64 `json:",omitempty"`
+
+	// Only available for requests measured by both the Arrival and the Recorder middleware
+	HandlerTimeMs    float64 `json:",omitempty"`
+	MiddlewareTimeMs float64 `json:",omitempty"`
+
+	// Bytes read from the request bodies and written in the responses over the whole lifetime, in total and per request
+	RequestBytes        uint64  `json:",omitempty"`
+	ResponseBytes       uint64  `json:",omitempty"`
+	AverageRequestSize  float64 `json:",omitempty"`
+	AverageResponseSize float64 `json:",omitempty"`
+
+	// Apdex score between 0 (all users frustrated) and 1 (all satisfied) over the whole lifetime,
+	// nil if no request was measured yet
+	Apdex *float64 `json:",omitempty"`
+}
+
+// Snapshot collects the current statistics of all default sections,
+// the same data the statistics endpoint serves without query parameters.
+func (stats *Collector) Snapshot() *Snapshot {
+	return stats.SnapshotSections(AllSections...)
+}
+
+// SnapshotSections collects the current statistics, limited to the given sections.
+// Only the data needed for the requested sections is gathered.
+func (stats *Collector) SnapshotSections(sections ...Section) *Snapshot {
+	return stats.snapshot(stats.defaultSummaryParameters(), newSectionSet(sections))
+}
+
+// snapshot collects the requested sections of the current statistics.
+func (stats *Collector) snapshot(parameters summaryParameters, sections sectionSet) *Snapshot {
+	snapshot := &Snapshot{
+		SchemaVersion: SchemaVersion,
+		Generated:     stats.clock.Now(),
+		Scope:         stats.scope,
+		timeUnit:      stats.timeUnit,
+	}
+
+	if sections[SectionSystem] {
+		snapshot.System = stats.systemStats()
+	}
+
+	if sections[SectionApp] {
+		snapshot.App = stats.appStats()
+	}
+
+	if sections[SectionRoutes] {
+		snapshot.Routes = stats.routeSummary(parameters, snapshot.Generated)
+		snapshot.routeCounters = stats.routeCounters()
+	}
+
+	if sections[SectionHistory] {
+		snapshot.History = stats.history.Buckets(snapshot.Generated)
+	}
+
+	if sections[SectionRatios] {
+		snapshot.Ratios = stats.ratioStats()
+	}
+
+	if sections[SectionMetrics] {
+		snapshot.Metrics = stats.metrics.stats()
+	}
+
+	if sections[SectionCaching] {
+		snapshot.Caching = stats.cachingStats()
+	}
+
+	if sections[SectionComparison] {
+		snapshot.Comparison = stats.comparisonStats(snapshot.Generated)
+	}
+
+	if sections[SectionHeatmap] {
+		snapshot.Heatmap = stats.heatmap.Heatmap(stats.heatmap.weekly)
+	}
+
+	if sections[SectionOrigins] && stats.origins != nil {
+		snapshot.Origins = stats.origins.Stats()
+	}
+
+	if sections[SectionSLO] {
+		snapshot.SLO = stats.sloStats(snapshot.Generated)
+	}
+
+	if sections[SectionProcess] {
+		snapshot.Process = stats.processStats()
+	}
+
+	return snapshot
+}
+
+// appStats collects the statistics of the application.
+func (stats *Collector) appStats() *AppStats {
+	process := stats.processStats()
+	goroutines, threads := stats.sampleThreads()
+
+	// GOMAXPROCS follows the CPU quota of the container in recent Go versions, so it is read every time
+	runtimeInfo := stats.runtimeInfo
+	runtimeInfo.GOMAXPROCS = runtime.GOMAXPROCS(0)
+
+	return &AppStats{
+		Go:        process.Go,
+		Runtime:   runtimeInfo,
+		Uptime:    process.Uptime,
+		Requests:  stats.RequestCount(),
+		Recent:    stats.recentLoad(stats.clock.Now()),
+		Memory:    process.Memory,
+		GC:        readGCStats(),
+		CPU:       stats.processCPUStats(),
+		State:     stats.processState(),
+		Bandwidth: stats.bandwidth.Stats(),
+		Visitors:  stats.visitorStats(),
+		Gauges:    stats.sampleAppGauges(),
+		Config:    stats.exposedConfig(),
+
+		Goroutines:    goroutines,
+		MaxGoroutines: atomic.LoadUint64(&stats.maxGoroutines),
+		Threads:       threads,
+		MaxThreads:    atomic.LoadUint64(&stats.maxThreads),
+
+		CgoCalls: uint64(runtime.NumCgoCall()),
+
+		Connections:  stats.connections.Stats(),
+		MemoryBudget: stats.memoryBudgetStats(),
+
+		WarmupRequests:  atomic.LoadUint64(&stats.warmupCount),
+		IgnoredRequests: atomic.LoadUint64(&stats.ignoredCount),
+	}
+}
+
+// processStats collects the runtime statistics of the application.
+func (stats *Collector) processStats() *ProcessStats {
+	return &ProcessStats{
+		Go:     strings.Replace(runtime.Version(), "go", "", 1),
+		Uptime: strings.TrimSpace(humanize.RelTime(stats.appStart, stats.clock.Now(), "", "")),
+		Memory: readMemoryStats(),
+	}
+}
+
+// routeSummary collects the slowest, the most popular, the failing and the most expensive routes,
+// over the whole lifetime or the given recent window, optionally limited to a single class.
+// The class totals always include every class.
+func (stats *Collector) routeSummary(parameters summaryParameters, now time.Time) *RouteSummary {
+	routeSummary := &RouteSummary{
+		Window:  windowName(parameters.window),
+		Classes: make(map[string]*ClassTotals),
+	}
+
+	totalTime := 0.0
+	windowed := parameters.window > 0
+	var apdex apdexCounters
+
+	for _, tracked := range stats.trackedRoutes() {
+		routeStats := tracked.stats
+		route := stats.routeInfo(tracked.path, routeStats, parameters.quantiles)
+		apdex.fold(&routeStats.apdex)
+
+		if windowed {
+			route.applyWindow(routeStats, now, parameters.window)
+		}
+
+		routeSummary.Classes[route.Class] = routeSummary.Classes[route.Class].add(route, routeStats)
+
+		if parameters.class != "" && route.Class != parameters.class {
+			continue
+		}
+
+		if parameters.method != "" && route.Method != parameters.method {
+			continue
+		}
+
+		totalTime += route.TotalTimeMs
+
+		if route.TotalTimeMs > 0 {
+			routeSummary.Expensive = append(routeSummary.Expensive, route)
+		}
+
+		if route.RequestBytes+route.ResponseBytes > 0 {
+			routeSummary.Heavy = append(routeSummary.Heavy, route)
+		}
+
+		if route.Requests > 0 && stats.slowLatency(route, windowed) >= stats.slowThreshold {
+			routeSummary.Slow = append(routeSummary.Slow, route)
+		}
+
+		if route.Requests >= stats.popularMin && route.Requests > 0 {
+			routeSummary.Popular = append(routeSummary.Popular, route)
+		}
+
+		if route.Errors >= 1 {
+			routeSummary.Failing = append(routeSummary.Failing, route)
+		}
+
+		if route.AbortRate >= abortRateThreshold {
+			routeSummary.Aborted = append(routeSummary.Aborted, route)
+		}
+	}
+
+	routeSummary.Apdex = apdex.score()
+
+	// The shares are calculated from the same values as the total so that they add up to 100%
+	for _, route := range routeSummary.Expensive {
+		route.TimeShare = route.TotalTimeMs / totalTime * 100
+	}
+
+	sort.Slice(routeSummary.Slow, func(i, j int) bool {
+		return stats.slowLatency(routeSummary.Slow[i], windowed) > stats.slowLatency(routeSummary.Slow[j], windowed)
+	})
+
+	sort.Slice(routeSummary.Popular, func(i, j int) bool {
+		return routeSummary.Popular[i].Requests > routeSummary.Popular[j].Requests
+	})
+
+	sort.Slice(routeSummary.Failing, func(i, j int) bool {
+		return routeSummary.Failing[i].Errors > routeSummary.Failing[j].Errors
+	})
+
+	sort.Slice(routeSummary.Aborted, func(i, j int) bool {
+		return routeSummary.Aborted[i].AbortRate > routeSummary.Aborted[j].AbortRate
+	})
+
+	sort.Slice(routeSummary.Expensive, func(i, j int) bool {
+		return routeSummary.Expensive[i].TotalTimeMs > routeSummary.Expensive[j].TotalTimeMs
+	})
+
+	sort.Slice(routeSummary.Heavy, func(i, j int) bool {
+		return routeSummary.Heavy[i].RequestBytes+routeSummary.Heavy[i].ResponseBytes > routeSummary.Heavy[j].RequestBytes+routeSummary.Heavy[j].ResponseBytes
+	})
+
+	if parameters.limit > 0 {
+		routeSummary.Slow = firstRoutes(routeSummary.Slow, parameters.limit)
+		routeSummary.Popular = firstRoutes(routeSummary.Popular, parameters.limit)
+		routeSummary.Failing = firstRoutes(routeSummary.Failing, parameters.limit)
+		routeSummary.Aborted = firstRoutes(routeSummary.Aborted, parameters.limit)
+		routeSummary.Expensive = firstRoutes(routeSummary.Expensive, parameters.limit)
+		routeSummary.Heavy = firstRoutes(routeSummary.Heavy, parameters.limit)
+	}
+
+	return routeSummary
+}
+
+// firstRoutes returns at most limit routes from the start of the list.
+func firstRoutes(routes []*Route, limit int) []*Route {
+	if len(routes) > limit {
+		return routes[:limit]
+	}
+
+	return routes
+}
+
+// routeInfo creates the exported statistics of a single route.
+// Response times are in milliseconds.
+func (stats *Collector) routeInfo(path string, routeStats *RouteStatistics, quantiles []float64) *Route {
+	maxResponseTime, maxObservedAt := routeStats.maxResponseTime.load()
+
+	route := &Route{
+		Route:          path,
+		Class:          routeStats.Class(),
+		Protocol:       routeStats.Protocol(),
+		Method:         routeStats.Method(),
+		Requests:       atomic.LoadUint64(&routeStats.requestCount),
+		Errors:         atomic.LoadUint64(&routeStats.errorCount),
+		MaxObservedAt:  maxObservedAt,
+		Percentiles:    stats.percentiles(routeStats, quantiles),
+		TotalTimeMs:    float64(atomic.LoadUint64(&routeStats.responseTime)) / float64(time.Millisecond),
+		WarmupRequests: atomic.LoadUint64(&routeStats.warmupCount),
+		RequestBytes:   atomic.LoadUint64(&routeStats.requestBytes),
+		ResponseBytes:  atomic.LoadUint64(&routeStats.responseSizes.total),
+		ClientAborted:  atomic.LoadUint64(&routeStats.abortedCount),
+		AbortRate:      routeStats.abortRate(),
+		AbortedAfterMs: roundMilliseconds(routeStats.averageAbortTime()),
+		Apdex:          routeStats.apdex.score(),
+	}
+
+	route.setResponseTimes(
+		routeStats.AverageResponseTime(),
+		time.Duration(atomic.LoadUint64(&routeStats.minResponseTime)),
+		time.Duration(maxResponseTime),
+	)
+
+	routeStats.firstByte.apply(route)
+	routeStats.statusClasses.apply(route)
+	route.StatusClasses = routeStats.statusCounts.Counts()
+
+	if route.Requests > 0 {
+		route.ErrorRate = float64(route.Errors) / float64(route.Requests)
+		route.AverageRequestSize = float64(route.RequestBytes) / float64(route.Requests)
+		route.AverageResponseSize = float64(route.ResponseBytes) / float64(route.Requests)
+	}
+
+	splitCount := atomic.LoadUint64(&routeStats.splitCount)
+
+	if splitCount > 0 {
+		splitHandler := atomic.LoadUint64(&routeStats.splitHandler)
+		splitTotal := atomic.LoadUint64(&routeStats.splitTotal)
+		route.HandlerTimeMs = float64(splitHandler) / float64(splitCount) / float64(time.Millisecond)
+		route.MiddlewareTimeMs = float64(splitTotal-splitHandler) / float64(splitCount) / float64(time.Millisecond)
+	}
+
+	return route
+}
+
+// setResponseTimes sets the average, minimum and maximum response time in all units.
+func (route *Route) setResponseTimes(average time.Duration, min time.Duration, max time.Duration) {
+	route.ResponseTimeNs = int64(average)
+	route.MinResponseTimeNs = int64(min)
+	route.MaxResponseTimeNs = int64(max)
+	route.ResponseTimeMs = roundMilliseconds(average)
+	route.MinResponseTimeMs = roundMilliseconds(min)
+	route.MaxResponseTimeMs = roundMilliseconds(max)
+	route.ResponseTime = uint64(average / time.Millisecond)
+	route.MinResponseTime = uint64(min / time.Millisecond)
+	route.MaxResponseTime = uint64(max / time.Millisecond)
+}
+
+// roundMilliseconds converts a duration to milliseconds with 3 decimals.
+func roundMilliseconds(duration time.Duration) float64 {
+	return float64(duration/time.Microsecond) / 1000
+}
@@ -0,0 +1,115 @@
+package stats
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// maxCachedResponses limits the number of distinct query variants kept in the snapshot cache.
+const maxCachedResponses = 64
+
+// WithSnapshotTTL serves the same rendered statistics to all requests within the given duration,
+// separately for every format and query. The default of zero renders every request.
+func WithSnapshotTTL(ttl time.Duration) Option {
+	return func(stats *Collector) {
+		stats.snapshotTTL = ttl
+	}
+}
+
+// snapshotCache keeps the recently rendered responses of the statistics route.
+type snapshotCache struct {
+	responses map[string]*cachedResponse
+	mutex     sync.Mutex
+}
+
+// cachedResponse is a rendered response of the statistics route.
+// Its mutex is held while rendering, so concurrent requests wait for a single render.
+type cachedResponse struct {
+	generated time.Time
+	status    int
+	header    http.Header
+	body      []byte
+	mutex     sync.Mutex
+}
+
+// get returns the cache entry for the given key, or nil if the cache is full.
+func (cache *snapshotCache) get(key string) *cachedResponse {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	if cache.responses == nil {
+		cache.responses = make(map[string]*cachedResponse)
+	}
+
+	cached, exists := cache.responses[key]
+
+	if !exists {
+		if len(cache.responses) >= maxCachedResponses {
+			return nil
+		}
+
+		cached = &cachedResponse{}
+		cache.responses[key] = cached
+	}
+
+	return cached
+}
+
+// serveCached serves the response from the cache and renders it again if it expired.
+func (stats *Collector) serveCached(response http.ResponseWriter, request *http.Request, render http.HandlerFunc) {
+	cached := stats.snapshotCache.get(stats.responseFormat(request) + "?" + request.URL.Query().Encode())
+
+	if cached == nil {
+		render(response, request)
+		return
+	}
+
+	cached.mutex.Lock()
+	now := stats.clock.Now()
+
+	if cached.header == nil || now.Sub(cached.generated) >= stats.snapshotTTL {
+		buffer := &bufferedResponse{header: http.Header{}, status: http.StatusOK}
+		render(buffer, request)
+		cached.generated = now
+		cached.status = buffer.status
+		cached.header = buffer.header
+		cached.body = buffer.body.Bytes()
+	}
+
+	status, header, body := cached.status, cached.header, cached.body
+	age := now.Sub(cached.generated)
+	cached.mutex.Unlock()
+
+	for key, values := range header {
+		response.Header()[key] = values
+	}
+
+	response.Header().Set("Age", strconv.Itoa(int(age/time.Second)))
+	response.WriteHeader(status)
+	response.Write(body)
+}
+
+// bufferedResponse is a response writer that keeps the response in memory.
+type bufferedResponse struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+// Header returns the response headers.
+func (buffer *bufferedResponse) Header() http.Header {
+	return buffer.header
+}
+
+// Write appends to the response body.
+func (buffer *bufferedResponse) Write(data []byte) (int, error) {
+	return buffer.body.Write(data)
+}
+
+// WriteHeader sets the status code.
+func (buffer *bufferedResponse) WriteHeader(status int) {
+	buffer.status = status
+}
@@ -0,0 +1,72 @@
+package stats
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// snapshotEpochCount is the number of recently served snapshots that can be used as a base for a diff.
+const snapshotEpochCount = 8
+
+// snapshotEpochs keeps the most recently served snapshots, identified by their time,
+// and the last pinned snapshot, which doesn't expire.
+type snapshotEpochs struct {
+	snapshots [snapshotEpochCount]*Snapshot
+	pinned    *Snapshot
+	next      int
+	mutex     sync.Mutex
+}
+
+// add stores a snapshot and returns its epoch.
+func (epochs *snapshotEpochs) add(snapshot *Snapshot) string {
+	epochs.mutex.Lock()
+	epochs.snapshots[epochs.next] = snapshot
+	epochs.next = (epochs.next + 1) % len(epochs.snapshots)
+	epochs.mutex.Unlock()
+
+	return snapshotEpoch(snapshot)
+}
+
+// pin stores a snapshot until the next one is pinned and returns its epoch.
+func (epochs *snapshotEpochs) pin(snapshot *Snapshot) string {
+	epochs.mutex.Lock()
+	epochs.pinned = snapshot
+	epochs.mutex.Unlock()
+
+	return snapshotEpoch(snapshot)
+}
+
+// clear removes all stored snapshots, e.g. because the counters were reset.
+func (epochs *snapshotEpochs) clear() {
+	epochs.mutex.Lock()
+	epochs.snapshots = [snapshotEpochCount]*Snapshot{}
+	epochs.pinned = nil
+	epochs.next = 0
+	epochs.mutex.Unlock()
+}
+
+// find returns the stored snapshot with the given epoch or ETag, or nil if it has expired.
+func (epochs *snapshotEpochs) find(epoch string) *Snapshot {
+	epoch = strings.Trim(strings.TrimPrefix(epoch, "W/"), `"`)
+
+	epochs.mutex.Lock()
+	defer epochs.mutex.Unlock()
+
+	for _, snapshot := range epochs.snapshots {
+		if snapshot != nil && snapshotEpoch(snapshot) == epoch {
+			return snapshot
+		}
+	}
+
+	if epochs.pinned != nil && snapshotEpoch(epochs.pinned) == epoch {
+		return epochs.pinned
+	}
+
+	return nil
+}
+
+// snapshotEpoch returns the identifier of a snapshot.
+func snapshotEpoch(snapshot *Snapshot) string {
+	return strconv.FormatInt(snapshot.Generated.UnixNano(), 10)
+}
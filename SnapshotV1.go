@@ -0,0 +1,54 @@
+package stats
+
+// snapshotV1 is the structure served before SchemaVersion was introduced.
+// Deprecated: It will be removed with the next schema version.
+type snapshotV1 struct {
+	System *SystemStats    `json:",omitempty"`
+	App    *AppStats       `json:",omitempty"`
+	Routes *routeSummaryV1 `json:",omitempty"`
+}
+
+// routeSummaryV1 is the route summary structure of schema version 1.
+type routeSummaryV1 struct {
+	Slow    []*routeV1
+	Popular []*routeV1
+}
+
+// routeV1 is the route structure of schema version 1.
+type routeV1 struct {
+	Route        string
+	Requests     uint64
+	ResponseTime uint64
+}
+
+// v1 converts the snapshot to schema version 1.
+func (snapshot *Snapshot) v1() *snapshotV1 {
+	old := &snapshotV1{
+		System: snapshot.System,
+		App:    snapshot.App,
+	}
+
+	if snapshot.Routes != nil {
+		old.Routes = &routeSummaryV1{
+			Slow:    routesV1(snapshot.Routes.Slow),
+			Popular: routesV1(snapshot.Routes.Popular),
+		}
+	}
+
+	return old
+}
+
+// routesV1 converts a list of routes to schema version 1.
+func routesV1(routes []*Route) []*routeV1 {
+	old := make([]*routeV1, len(routes))
+
+	for i, route := range routes {
+		old[i] = &routeV1{
+			Route:        route.Route,
+			Requests:     route.Requests,
+			ResponseTime: route.ResponseTime,
+		}
+	}
+
+	return old
+}
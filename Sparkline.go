@@ -0,0 +1,139 @@
+package stats
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Sparkline dimensions in pixels
+const (
+	sparklineWidth  = 120
+	sparklineHeight = 24
+)
+
+// Sparkline metrics
+const (
+	metricRPS     = "rps"
+	metricLatency = "latency"
+)
+
+// Sparkline renders the values as a small SVG line chart.
+// The chart is scaled to the highest value; all-zero series are drawn as a flat line at the bottom.
+func Sparkline(values []float64) string {
+	svg := strings.Builder{}
+	fmt.Fprintf(&svg, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`, sparklineWidth, sparklineHeight, sparklineWidth, sparklineHeight)
+
+	if len(values) == 0 {
+		svg.WriteString(`</svg>`)
+		return svg.String()
+	}
+
+	// A single point is drawn as a horizontal line
+	if len(values) == 1 {
+		values = []float64{values[0], values[0]}
+	}
+
+	max := 0.0
+
+	for _, value := range values {
+		if value > max {
+			max = value
+		}
+	}
+
+	svg.WriteString(`<path fill="none" stroke="currentColor" stroke-width="1" d="`)
+	step := float64(sparklineWidth) / float64(len(values)-1)
+
+	for i, value := range values {
+		// Keep half a pixel of padding so the stroke isn't clipped
+		y := float64(sparklineHeight) - 0.5
+
+		if max > 0 {
+			y -= value / max * (sparklineHeight - 1)
+		}
+
+		command := "L"
+
+		if i == 0 {
+			command = "M"
+		}
+
+		fmt.Fprintf(&svg, "%s%.1f %.1f", command, float64(i)*step, y)
+	}
+
+	svg.WriteString(`"/></svg>`)
+	return svg.String()
+}
+
+// trend returns the per-minute values of the metric over the last hour.
+func (stats *RouteStatistics) trend(metric string, now time.Time) ([]float64, bool) {
+	buckets := stats.minutes.Buckets(now)
+	values := make([]float64, len(buckets))
+
+	for i, bucket := range buckets {
+		switch metric {
+		case metricRPS:
+			values[i] = float64(bucket.Requests) / time.Minute.Seconds()
+		case metricLatency:
+			values[i] = bucket.ResponseTimeMs
+		default:
+			return nil, false
+		}
+	}
+
+	return values, true
+}
+
+// sparkline is the template function rendering the trend of a route.
+func (stats *Collector) sparkline(path string, metric string) template.HTML {
+	stats.routesMutex.RLock()
+	routeStats, exists := stats.routes[path]
+	stats.routesMutex.RUnlock()
+
+	if !exists {
+		return ""
+	}
+
+	values, ok := routeStats.trend(metric, stats.clock.Now())
+
+	if !ok {
+		return ""
+	}
+
+	return template.HTML(Sparkline(values))
+}
+
+// showSparkline serves the trend of a route as an SVG image.
+// The "route" query parameter selects the route and "metric" is either "rps" or "latency".
+func (stats *Collector) showSparkline(response http.ResponseWriter, request *http.Request) {
+	query := request.URL.Query()
+	path := query.Get("route")
+
+	stats.routesMutex.RLock()
+	routeStats, exists := stats.routes[path]
+	stats.routesMutex.RUnlock()
+
+	if !exists {
+		http.Error(response, "Unknown route: "+path, http.StatusNotFound)
+		return
+	}
+
+	metric := query.Get("metric")
+
+	if metric == "" {
+		metric = metricRPS
+	}
+
+	values, ok := routeStats.trend(metric, stats.clock.Now())
+
+	if !ok {
+		http.Error(response, "Unknown metric: "+metric, http.StatusBadRequest)
+		return
+	}
+
+	response.Header().Set("Content-Type", "image/svg+xml")
+	response.Write([]byte(Sparkline(values)))
+}
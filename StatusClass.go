@@ -0,0 +1,105 @@
+package stats
+
+import (
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// WithBlendedSlowRoutes ranks the Slow summary by the average response time of all responses.
+// By default, routes with successful responses are ranked by the response time of those only,
+// so that slow failures like timeouts don't hide how fast a route usually is or the other way round.
+func WithBlendedSlowRoutes() Option {
+	return func(stats *Collector) {
+		stats.blendedSlowRoutes = true
+	}
+}
+
+// meanCounter accumulates durations for their average.
+type meanCounter struct {
+	count uint64
+	total uint64
+}
+
+// add adds a duration.
+func (counter *meanCounter) add(duration time.Duration) {
+	atomic.AddUint64(&counter.count, 1)
+	atomic.AddUint64(&counter.total, uint64(duration))
+}
+
+// fold adds the durations of another counter.
+func (counter *meanCounter) fold(other *meanCounter) {
+	atomic.AddUint64(&counter.count, atomic.LoadUint64(&other.count))
+	atomic.AddUint64(&counter.total, atomic.LoadUint64(&other.total))
+}
+
+// average returns the average duration, 0 if nothing was added.
+func (counter *meanCounter) average() time.Duration {
+	count := atomic.LoadUint64(&counter.count)
+
+	if count == 0 {
+		return 0
+	}
+
+	return time.Duration(atomic.LoadUint64(&counter.total) / count)
+}
+
+// statusClassLatency keeps the average response times of the 1xx to 5xx status classes.
+type statusClassLatency [5]meanCounter
+
+// record adds the response time of a measured request to its status class.
+func (classes *statusClassLatency) record(record *RequestRecord) {
+	class := record.StatusCode/100 - 1
+
+	if class < 0 || class >= len(classes) {
+		return
+	}
+
+	classes[class].add(record.Duration)
+}
+
+// fold adds the response times of another route.
+func (classes *statusClassLatency) fold(other *statusClassLatency) {
+	for class := range classes {
+		classes[class].fold(&other[class])
+	}
+}
+
+// apply sets the response times by status class of the exported route.
+func (classes *statusClassLatency) apply(route *Route) {
+	route.ResponseTime2xxMs = roundMilliseconds(classes[1].average())
+	route.ResponseTime4xxMs = roundMilliseconds(classes[3].average())
+	route.ResponseTime5xxMs = roundMilliseconds(classes[4].average())
+}
+
+// statusClassCounts counts the requests of the 1xx to 5xx status classes.
+type statusClassCounts [5]uint64
+
+// record counts a finished request in its status class.
+func (counts *statusClassCounts) record(record *RequestRecord) {
+	class := record.StatusCode/100 - 1
+
+	if class >= 0 && class < len(counts) {
+		atomic.AddUint64(&counts[class], 1)
+	}
+}
+
+// fold adds the counts of another route.
+func (counts *statusClassCounts) fold(other *statusClassCounts) {
+	for class := range counts {
+		atomic.AddUint64(&counts[class], atomic.LoadUint64(&other[class]))
+	}
+}
+
+// Counts returns the non-zero counts by status class like "2xx".
+func (counts *statusClassCounts) Counts() map[string]uint64 {
+	exported := make(map[string]uint64, len(counts))
+
+	for class := range counts {
+		if count := atomic.LoadUint64(&counts[class]); count > 0 {
+			exported[strconv.Itoa(class+1)+"xx"] = count
+		}
+	}
+
+	return exported
+}
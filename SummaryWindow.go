@@ -0,0 +1,64 @@
+package stats
+
+import (
+	"fmt"
+	"time"
+)
+
+// maxSummaryWindow is the longest summary window, limited by the per-route minute history.
+const maxSummaryWindow = time.Hour
+
+// WithSummaryWindow computes the Slow, Popular, Failing and Expensive route summaries
+// from the requests of the given recent duration instead of the whole lifetime.
+// The window is rounded up to full minutes and may be at most one hour, other values are ignored.
+// Requests can override it with the "window" query parameter, "all" selects the lifetime.
+func WithSummaryWindow(window time.Duration) Option {
+	return func(stats *Collector) {
+		if window > 0 && window <= maxSummaryWindow {
+			stats.summaryWindow = window
+		}
+	}
+}
+
+// parseWindow parses the "window" query parameter.
+func parseWindow(value string) (time.Duration, error) {
+	if value == "all" {
+		return 0, nil
+	}
+
+	window, err := time.ParseDuration(value)
+
+	if err != nil || window <= 0 || window > maxSummaryWindow {
+		return 0, fmt.Errorf("Invalid window: %s", value)
+	}
+
+	return window, nil
+}
+
+// windowName returns the name of the summary window echoed in the JSON.
+func windowName(window time.Duration) string {
+	if window <= 0 {
+		return "all"
+	}
+
+	return window.String()
+}
+
+// applyWindow replaces the request counts and response times of a route with those of the window.
+// Like the average response time, the window only counts measured requests, without warm-up and aborted ones.
+// The minimum, maximum, percentiles and status classes still cover the whole lifetime.
+func (route *Route) applyWindow(routeStats *RouteStatistics, now time.Time, window time.Duration) {
+	requests, errors, responseTime := routeStats.minutes.window(now, window)
+	route.Requests = requests
+	route.Errors = errors
+	route.ErrorRate = 0
+	route.TotalTimeMs = float64(responseTime) / float64(time.Millisecond)
+	average := time.Duration(0)
+
+	if requests > 0 {
+		route.ErrorRate = float64(errors) / float64(requests)
+		average = time.Duration(responseTime / requests)
+	}
+
+	route.setResponseTimes(average, time.Duration(route.MinResponseTimeNs), time.Duration(route.MaxResponseTimeNs))
+}
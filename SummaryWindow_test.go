@@ -0,0 +1,59 @@
+package stats_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aerogo/stats"
+	"github.com/aerogo/stats/testutil"
+)
+
+func TestSummaryWindowSlowRoutes(t *testing.T) {
+	clock := testutil.NewFakeClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	collector := stats.NewCollector(stats.WithClock(clock), stats.WithSummaryWindow(15*time.Minute))
+	defer collector.Close(context.Background())
+
+	collector.Track("/old", time.Second)
+	clock.Advance(2 * time.Hour)
+	collector.Track("/recent", 50*time.Millisecond)
+	collector.Track("/fast", time.Millisecond)
+
+	slow := collector.Snapshot().Routes.Slow
+
+	if len(slow) != 1 || slow[0].Route != "/recent" {
+		for _, route := range slow {
+			t.Errorf("slow route %s with %d requests in the window", route.Route, route.Requests)
+		}
+
+		t.Fatalf("expected only /recent in the Slow summary")
+	}
+}
+
+func TestSummaryWindowExcludesWarmup(t *testing.T) {
+	clock := testutil.NewFakeClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	collector := stats.NewCollector(stats.WithClock(clock), stats.WithStartTime(clock.Now()), stats.WithWarmup(time.Minute), stats.WithSummaryWindow(15*time.Minute))
+	defer collector.Close(context.Background())
+
+	for i := 0; i < 10; i++ {
+		collector.Track("/", time.Second)
+	}
+
+	clock.Advance(2 * time.Minute)
+	collector.Track("/", time.Millisecond)
+
+	route := collector.Snapshot().Routes.Popular[0]
+
+	if route.Requests != 1 || route.ResponseTimeMs != 1 {
+		t.Errorf("%d requests of %vms in the window, expected a single measured one of 1ms", route.Requests, route.ResponseTimeMs)
+	}
+}
+
+func TestSummaryWindowLimit(t *testing.T) {
+	collector := stats.NewCollector(stats.WithSummaryWindow(2 * time.Hour))
+	defer collector.Close(context.Background())
+
+	if window := collector.Snapshot().Routes.Window; window != "all" {
+		t.Errorf("window %s longer than the minute history was accepted", window)
+	}
+}
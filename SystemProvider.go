@@ -0,0 +1,86 @@
+package stats
+
+import "time"
+
+// SystemProvider reads the statistics of the host system.
+// The default implementation is based on gosigar.
+type SystemProvider interface {
+	LoadAverage() (LoadAverage, error)
+	Memory() (MemoryInfo, error)
+	Uptime() (time.Duration, error)
+	Swap() (SwapInfo, error)
+	CPU() (CPUTimes, error)
+	FDUsage() (FDUsage, error)
+	ProcessCPU() (ProcessCPUTime, error)
+	ProcessState() (ProcessState, error)
+}
+
+// LoadAverage is the system load over the last 1, 5 and 15 minutes.
+type LoadAverage struct {
+	One     float64
+	Five    float64
+	Fifteen float64
+}
+
+// MemoryInfo is the memory usage of the system in bytes.
+// The actual values exclude the memory used by buffers and caches.
+type MemoryInfo struct {
+	Total      uint64
+	Used       uint64
+	Free       uint64
+	ActualUsed uint64
+	ActualFree uint64
+}
+
+// SwapInfo is the swap usage of the system in bytes.
+type SwapInfo struct {
+	Total uint64
+	Used  uint64
+	Free  uint64
+}
+
+// CPUTimes are the cumulative CPU times of all cores in ticks.
+type CPUTimes struct {
+	User    uint64
+	Nice    uint64
+	Sys     uint64
+	Idle    uint64
+	Wait    uint64
+	Irq     uint64
+	SoftIrq uint64
+	Stolen  uint64
+}
+
+// FDUsage is the number of open file descriptors of the process and its limit.
+type FDUsage struct {
+	Open  uint64
+	Limit uint64
+}
+
+// ProcessCPUTime is the cumulative CPU time consumed by the current process.
+type ProcessCPUTime struct {
+	User   time.Duration
+	System time.Duration
+}
+
+// ProcessState is the scheduling state of the current process, e.g. "running" or "sleeping".
+type ProcessState struct {
+	State    string
+	Priority int
+	Nice     int
+}
+
+// WithSystemProvider replaces the source of the system statistics.
+func WithSystemProvider(provider SystemProvider) Option {
+	return func(stats *Collector) {
+		stats.system = provider
+	}
+}
+
+// OnError registers a handler for errors while reading the system statistics or pushing metrics.
+// A system statistics error is reported once instead of on every request of the statistics.
+func OnError(handler func(error)) Option {
+	return func(stats *Collector) {
+		stats.onError = handler
+	}
+}
@@ -0,0 +1,169 @@
+package stats
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"runtime"
+	"time"
+
+	humanize "github.com/dustin/go-humanize"
+)
+
+// SystemStats contains statistics about the host system.
+// Values that could not be read are omitted and the reason is listed in CollectionErrors.
+// Values that the platform doesn't support are omitted without an error.
+type SystemStats struct {
+	Platform         SystemPlatform
+	Uptime           *string `json:",omitempty"`
+	CPUs             int
+	LoadAverage      *LoadAverage       `json:",omitempty"`
+	Memory           *SystemMemoryStats `json:",omitempty"`
+	Swap             *SystemSwapStats   `json:",omitempty"`
+	CPU              *CPUTimes          `json:",omitempty"`
+	FileDescriptors  *FDUsage           `json:",omitempty"`
+	CollectionErrors []SystemError      `json:",omitempty"`
+}
+
+// SystemError is a failure to read one of the system metrics.
+type SystemError struct {
+	Metric string
+	Error  string
+}
+
+// SystemPlatform describes the platform and the metrics it supports.
+type SystemPlatform struct {
+	OS        string
+	Arch      string
+	Supported []string
+}
+
+// SystemMemoryStats contains the memory usage of the host system.
+type SystemMemoryStats struct {
+	Total string
+	Free  string
+	Cache string
+
+	total uint64
+	free  uint64
+}
+
+// SystemSwapStats contains the swap usage of the host system.
+type SystemSwapStats struct {
+	Total string
+	Used  string
+	Free  string
+}
+
+// systemStats collects the statistics of the host system.
+func (stats *Collector) systemStats() *SystemStats {
+	system := &SystemStats{
+		Platform: SystemPlatform{
+			OS:        runtime.GOOS,
+			Arch:      runtime.GOARCH,
+			Supported: []string{},
+		},
+		CPUs: runtime.NumCPU(),
+	}
+
+	failed := func(metric string, err error) bool {
+		if errors.Is(err, ErrUnsupported) {
+			return true
+		}
+
+		system.Platform.Supported = append(system.Platform.Supported, metric)
+
+		stats.reportSystemError(metric, err)
+
+		if err != nil {
+			system.CollectionErrors = append(system.CollectionErrors, SystemError{
+				Metric: metric,
+				Error:  err.Error(),
+			})
+		}
+
+		return err != nil
+	}
+
+	if uptime, err := stats.system.Uptime(); !failed("uptime", err) {
+		formatted := formatUptime(uptime)
+		system.Uptime = &formatted
+	}
+
+	if avg, err := stats.system.LoadAverage(); !failed("load average", err) {
+		system.LoadAverage = &avg
+	}
+
+	if mem, err := stats.system.Memory(); !failed("memory", err) {
+		system.Memory = &SystemMemoryStats{
+			Total: humanize.Bytes(mem.Total),
+			Free:  humanize.Bytes(mem.Free),
+			Cache: humanize.Bytes(mem.Used - mem.ActualUsed),
+			total: mem.Total,
+			free:  mem.Free,
+		}
+	}
+
+	if swap, err := stats.system.Swap(); !failed("swap", err) {
+		system.Swap = &SystemSwapStats{
+			Total: humanize.Bytes(swap.Total),
+			Used:  humanize.Bytes(swap.Used),
+			Free:  humanize.Bytes(swap.Free),
+		}
+	}
+
+	if cpu, err := stats.system.CPU(); !failed("cpu", err) {
+		system.CPU = &cpu
+	}
+
+	if fds, err := stats.system.FDUsage(); !failed("file descriptors", err) {
+		system.FileDescriptors = &fds
+	}
+
+	return system
+}
+
+// reportSystemError passes a failed metric to the error handler.
+// Each error is only reported once until the metric recovers or fails differently.
+func (stats *Collector) reportSystemError(metric string, err error) {
+	if stats.onError == nil {
+		return
+	}
+
+	stats.systemErrorsMutex.Lock()
+	defer stats.systemErrorsMutex.Unlock()
+
+	if err == nil {
+		delete(stats.systemErrors, metric)
+		return
+	}
+
+	if stats.systemErrors[metric] == err.Error() {
+		return
+	}
+
+	stats.systemErrors[metric] = err.Error()
+	stats.onError(fmt.Errorf("system %s: %w", metric, err))
+}
+
+// formatUptime formats the system uptime like "3 days, 4:05".
+func formatUptime(uptime time.Duration) string {
+	buffer := bytes.Buffer{}
+	days := int(uptime / (24 * time.Hour))
+	hours := int(uptime/time.Hour) % 24
+	minutes := int(uptime/time.Minute) % 60
+
+	if days == 1 {
+		fmt.Fprintf(&buffer, "1 day, ")
+	} else if days > 1 {
+		fmt.Fprintf(&buffer, "%d days, ", days)
+	}
+
+	if hours != 0 {
+		fmt.Fprintf(&buffer, "%d:%02d", hours, minutes)
+	} else {
+		fmt.Fprintf(&buffer, "%d min", minutes)
+	}
+
+	return buffer.String()
+}
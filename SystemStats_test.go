@@ -0,0 +1,104 @@
+package stats_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aerogo/stats"
+	"github.com/aerogo/stats/testutil"
+)
+
+// servedSystem returns the system section of the served JSON.
+func servedSystem(t *testing.T, collector *stats.Collector) map[string]interface{} {
+	t.Helper()
+	request := httptest.NewRequest(http.MethodGet, stats.DefaultPath+"?format=json&sections=system", nil)
+	response := httptest.NewRecorder()
+	collector.ServeHTTP(response, request)
+
+	var output struct {
+		System map[string]interface{}
+	}
+
+	if err := json.Unmarshal(response.Body.Bytes(), &output); err != nil {
+		t.Fatal(err)
+	}
+
+	return output.System
+}
+
+func TestUnsupportedSystemMetricsOmitted(t *testing.T) {
+	provider := &testutil.FakeSystemProvider{
+		Load: stats.LoadAverage{One: 1},
+		Errors: map[string]error{
+			"load average":     stats.ErrUnsupported,
+			"swap":             stats.ErrUnsupported,
+			"file descriptors": stats.ErrUnsupported,
+		},
+	}
+
+	system := servedSystem(t, stats.NewCollector(stats.WithSystemProvider(provider)))
+
+	for _, field := range []string{"LoadAverage", "Swap", "FileDescriptors", "CollectionErrors"} {
+		if value, exists := system[field]; exists {
+			t.Errorf("unsupported metric included as %s: %v", field, value)
+		}
+	}
+
+	for _, field := range []string{"Uptime", "Memory", "CPU"} {
+		if _, exists := system[field]; !exists {
+			t.Errorf("supported metric %s omitted", field)
+		}
+	}
+
+	platform := system["Platform"].(map[string]interface{})
+	supported, _ := json.Marshal(platform["Supported"])
+
+	if string(supported) != `["uptime","memory","cpu"]` {
+		t.Errorf("supported metrics %s", supported)
+	}
+}
+
+func TestSystemCollectionErrors(t *testing.T) {
+	provider := &testutil.FakeSystemProvider{
+		Errors: map[string]error{
+			"memory": errors.New("permission denied"),
+		},
+	}
+
+	var reported []error
+	collector := stats.NewCollector(stats.WithSystemProvider(provider), stats.OnError(func(err error) {
+		reported = append(reported, err)
+	}))
+
+	system := servedSystem(t, collector)
+
+	if _, exists := system["Memory"]; exists {
+		t.Error("memory included although it failed")
+	}
+
+	collectionErrors, _ := json.Marshal(system["CollectionErrors"])
+
+	if string(collectionErrors) != `[{"Error":"permission denied","Metric":"memory"}]` {
+		t.Errorf("collection errors %s", collectionErrors)
+	}
+
+	// The error is reported once, not on every scrape
+	servedSystem(t, collector)
+
+	if len(reported) != 1 || reported[0].Error() != "system memory: permission denied" {
+		t.Fatalf("reported errors %v", reported)
+	}
+
+	// A metric that recovers and fails again is reported again
+	delete(provider.Errors, "memory")
+	servedSystem(t, collector)
+	provider.Errors["memory"] = errors.New("permission denied")
+	servedSystem(t, collector)
+
+	if len(reported) != 2 {
+		t.Errorf("reported errors %v", reported)
+	}
+}
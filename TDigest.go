@@ -0,0 +1,251 @@
+package stats
+
+import (
+	"math"
+	"runtime"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultCompression is the t-digest compression used by the TDigest backend.
+const DefaultCompression = 100
+
+// tdigestBatchSize is the number of values buffered before they are merged into the digest.
+const tdigestBatchSize = 32
+
+// centroid is a cluster of values in a t-digest.
+type centroid struct {
+	mean   float64
+	weight float64
+}
+
+// TDigestDistribution is a merging t-digest.
+// Memory usage is bounded by roughly 2 * compression centroids.
+type TDigestDistribution struct {
+	compression float64
+	mutex       sync.Mutex
+	centroids   []centroid
+	total       float64
+	min         float64
+	max         float64
+
+	// Lock-free insert buffer
+	batch   [tdigestBatchSize]float64
+	claimed int32
+	written int32
+}
+
+// TDigest creates a t-digest distribution with the default compression.
+func TDigest() Distribution {
+	return NewTDigest(DefaultCompression)
+}
+
+// TDigestCompression returns a factory for t-digests with a custom compression.
+// Higher values increase accuracy and memory usage.
+func TDigestCompression(compression float64) DistributionFactory {
+	return func() Distribution {
+		return NewTDigest(compression)
+	}
+}
+
+// NewTDigest creates a new t-digest with the given compression.
+func NewTDigest(compression float64) *TDigestDistribution {
+	return &TDigestDistribution{
+		compression: compression,
+		min:         math.Inf(1),
+		max:         math.Inf(-1),
+	}
+}
+
+// Record adds a response time to the digest.
+// Values are buffered and merged in small batches so that
+// most calls don't need to acquire the lock.
+func (digest *TDigestDistribution) Record(duration time.Duration) {
+	for {
+		slot := atomic.AddInt32(&digest.claimed, 1) - 1
+
+		if slot < tdigestBatchSize {
+			digest.batch[slot] = float64(duration)
+
+			if atomic.AddInt32(&digest.written, 1) == tdigestBatchSize {
+				digest.flush(tdigestBatchSize)
+			}
+
+			return
+		}
+
+		// The batch is full and being merged by another goroutine
+		runtime.Gosched()
+	}
+}
+
+// flush merges the first count values of the batch into the centroids and empties it.
+// All count slots must be claimed and written.
+func (digest *TDigestDistribution) flush(count int32) {
+	points := make([]centroid, count)
+
+	for i, value := range digest.batch[:count] {
+		points[i] = centroid{mean: value, weight: 1}
+	}
+
+	digest.mutex.Lock()
+	digest.merge(points)
+	digest.mutex.Unlock()
+
+	atomic.StoreInt32(&digest.written, 0)
+	atomic.StoreInt32(&digest.claimed, 0)
+}
+
+// flushPending merges a partially filled batch, so that queries include the most recent values.
+// It claims the remaining slots like a writer would, waits for the claimed slots to be written
+// and flushes them. A full batch is flushed by the writer that completes it.
+func (digest *TDigestDistribution) flushPending() {
+	for {
+		claimed := atomic.LoadInt32(&digest.claimed)
+
+		if claimed == 0 {
+			return
+		}
+
+		if claimed >= tdigestBatchSize {
+			runtime.Gosched()
+			continue
+		}
+
+		if !atomic.CompareAndSwapInt32(&digest.claimed, claimed, tdigestBatchSize) {
+			continue
+		}
+
+		for atomic.LoadInt32(&digest.written) != claimed {
+			runtime.Gosched()
+		}
+
+		digest.flush(claimed)
+		return
+	}
+}
+
+// Quantile returns the estimated value at quantile q (0 <= q <= 1).
+func (digest *TDigestDistribution) Quantile(q float64) time.Duration {
+	digest.flushPending()
+	digest.mutex.Lock()
+	defer digest.mutex.Unlock()
+
+	return time.Duration(digest.quantile(q))
+}
+
+// Merge adds all values of another digest to this digest.
+func (digest *TDigestDistribution) Merge(other *TDigestDistribution) {
+	other.flushPending()
+	other.mutex.Lock()
+	points := make([]centroid, len(other.centroids))
+	copy(points, other.centroids)
+	min, max := other.min, other.max
+	other.mutex.Unlock()
+
+	digest.mutex.Lock()
+	digest.merge(points)
+	digest.min = math.Min(digest.min, min)
+	digest.max = math.Max(digest.max, max)
+	digest.mutex.Unlock()
+}
+
+// merge combines the given points with the existing centroids.
+// The caller must hold the lock.
+func (digest *TDigestDistribution) merge(points []centroid) {
+	if len(points) == 0 {
+		return
+	}
+
+	all := append(points, digest.centroids...)
+
+	sort.Slice(all, func(i, j int) bool {
+		return all[i].mean < all[j].mean
+	})
+
+	total := 0.0
+
+	for _, point := range all {
+		total += point.weight
+	}
+
+	merged := make([]centroid, 0, len(digest.centroids)+1)
+	current := all[0]
+	weightSoFar := 0.0
+	limit := digest.kInverse(digest.k(0) + 1)
+
+	for _, point := range all[1:] {
+		if (weightSoFar+current.weight+point.weight)/total <= limit {
+			current.mean += (point.mean - current.mean) * point.weight / (current.weight + point.weight)
+			current.weight += point.weight
+			continue
+		}
+
+		weightSoFar += current.weight
+		merged = append(merged, current)
+		current = point
+		limit = digest.kInverse(digest.k(weightSoFar/total) + 1)
+	}
+
+	merged = append(merged, current)
+	digest.centroids = merged
+	digest.total = total
+	digest.min = math.Min(digest.min, all[0].mean)
+	digest.max = math.Max(digest.max, all[len(all)-1].mean)
+}
+
+// quantile interpolates between centroid means.
+// The caller must hold the lock.
+func (digest *TDigestDistribution) quantile(q float64) float64 {
+	switch len(digest.centroids) {
+	case 0:
+		return 0
+	case 1:
+		return digest.centroids[0].mean
+	}
+
+	if q <= 0 {
+		return digest.min
+	}
+
+	if q >= 1 {
+		return digest.max
+	}
+
+	target := q * digest.total
+	cumulative := 0.0
+	previousCenter := 0.0
+	previousMean := digest.min
+
+	for _, c := range digest.centroids {
+		center := cumulative + c.weight/2
+
+		if target < center {
+			t := (target - previousCenter) / (center - previousCenter)
+			return previousMean + t*(c.mean-previousMean)
+		}
+
+		cumulative += c.weight
+		previousCenter = center
+		previousMean = c.mean
+	}
+
+	t := (target - previousCenter) / (digest.total - previousCenter)
+	return previousMean + t*(digest.max-previousMean)
+}
+
+// k is the scale function mapping a quantile to a centroid index.
+func (digest *TDigestDistribution) k(q float64) float64 {
+	return digest.compression / (2 * math.Pi) * math.Asin(2*q-1)
+}
+
+// kInverse maps a centroid index back to a quantile.
+func (digest *TDigestDistribution) kInverse(k float64) float64 {
+	if k >= digest.compression/4 {
+		return 1
+	}
+
+	return (math.Sin(k*2*math.Pi/digest.compression) + 1) / 2
+}
@@ -0,0 +1,64 @@
+package stats_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aerogo/stats"
+)
+
+func TestTDigestPendingValues(t *testing.T) {
+	digest := stats.NewTDigest(stats.DefaultCompression)
+
+	for i := 1; i <= 10; i++ {
+		digest.Record(time.Duration(i) * time.Millisecond)
+	}
+
+	if median := digest.Quantile(0.5); median < 4*time.Millisecond || median > 7*time.Millisecond {
+		t.Errorf("p50 of 1ms..10ms is %s", median)
+	}
+
+	if max := digest.Quantile(1); max != 10*time.Millisecond {
+		t.Errorf("p100 is %s", max)
+	}
+}
+
+func TestTDigestMergePendingValues(t *testing.T) {
+	digest := stats.NewTDigest(stats.DefaultCompression)
+	other := stats.NewTDigest(stats.DefaultCompression)
+	digest.Record(time.Millisecond)
+	other.Record(3 * time.Millisecond)
+	digest.Merge(other)
+
+	if max := digest.Quantile(1); max != 3*time.Millisecond {
+		t.Errorf("p100 after merge is %s", max)
+	}
+}
+
+func TestTDigestConcurrentQuantile(t *testing.T) {
+	digest := stats.NewTDigest(stats.DefaultCompression)
+	var wait sync.WaitGroup
+
+	for writer := 0; writer < 4; writer++ {
+		wait.Add(1)
+
+		go func() {
+			defer wait.Done()
+
+			for i := 0; i < 1000; i++ {
+				digest.Record(time.Millisecond)
+			}
+		}()
+	}
+
+	for i := 0; i < 100; i++ {
+		digest.Quantile(0.99)
+	}
+
+	wait.Wait()
+
+	if median := digest.Quantile(0.5); median != time.Millisecond {
+		t.Errorf("p50 is %s", median)
+	}
+}
@@ -0,0 +1,261 @@
+package stats
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	humanize "github.com/dustin/go-humanize"
+)
+
+// maxTextLineLength is the maximum length of a table line in the plaintext output.
+const maxTextLineLength = 120
+
+// textColumns are the column headers of the route table.
+var textColumns = []string{"ROUTE", "REQUESTS", "AVG", "MIN", "MAX", "ERRORS"}
+
+// ANSI colors, limited to the basic 8 colors for compatibility
+const (
+	colorReset  = "\x1b[0m"
+	colorRed    = "\x1b[31m"
+	colorYellow = "\x1b[33m"
+)
+
+// Response times in milliseconds at which routes are highlighted in colored output
+const (
+	warningResponseTime  = 10
+	criticalResponseTime = 100
+)
+
+// lowMemoryRatio is the ratio of free system memory below which a warning is shown in colored output.
+const lowMemoryRatio = 0.1
+
+// RenderText writes the snapshot as plaintext with aligned columns.
+// If color is true, slow routes, errors and memory pressure are highlighted with ANSI colors
+// unless the NO_COLOR environment variable is set.
+func RenderText(w io.Writer, snapshot *Snapshot, color bool) error {
+	if os.Getenv("NO_COLOR") != "" {
+		color = false
+	}
+
+	return renderText(w, snapshot, color)
+}
+
+// renderText writes the snapshot as plaintext with aligned columns, optionally using colors.
+func renderText(w io.Writer, snapshot *Snapshot, color bool) error {
+	buffer := bytes.Buffer{}
+
+	if snapshot.App != nil {
+		app := snapshot.App
+		fmt.Fprintln(&buffer, "App")
+		fmt.Fprintf(&buffer, "  %-10s %s\n", "Go", app.Go)
+		fmt.Fprintf(&buffer, "  %-10s GOMAXPROCS=%d GOGC=%s race=%t cgo=%t\n", "Runtime", app.Runtime.GOMAXPROCS, app.Runtime.GOGC, app.Runtime.Race, app.Runtime.CGO)
+		fmt.Fprintf(&buffer, "  %-10s %s\n", "Uptime", app.Uptime)
+		fmt.Fprintf(&buffer, "  %-10s %d\n", "Requests", app.Requests)
+		fmt.Fprintf(&buffer, "  %-10s %s allocated, %s GC threshold, %d objects\n", "Memory", app.Memory.Allocated, app.Memory.GCThreshold, app.Memory.Objects)
+
+		if app.GC != nil {
+			fmt.Fprintf(&buffer, "  %-10s %d cycles, %.1f/min, pauses %.3fms last, %.3fms avg, %.3fms max\n", "GC", app.GC.Cycles, app.GC.PerMinute, app.GC.LastPauseMs, app.GC.AvgPauseMs, app.GC.MaxPauseMs)
+		}
+
+		fmt.Fprintf(&buffer, "  %-10s %d goroutines (max %d), %d threads (max %d), %d cgo calls\n", "Threads", app.Goroutines, app.MaxGoroutines, app.Threads, app.MaxThreads, app.CgoCalls)
+
+		if app.CPU != nil {
+			fmt.Fprintf(&buffer, "  %-10s %.1fs user, %.1fs system, %.2f cores\n", "CPU", app.CPU.UserSeconds, app.CPU.SystemSeconds, app.CPU.Cores)
+		}
+
+		fmt.Fprintf(&buffer, "  %-10s %s received, %s sent (%s/s in, %s/s out)\n", "Bandwidth", app.Bandwidth.Received, app.Bandwidth.Sent, humanize.Bytes(uint64(app.Bandwidth.ReceiveRate)), humanize.Bytes(uint64(app.Bandwidth.SendRate)))
+
+		names := make([]string, 0, len(app.Gauges))
+
+		for name := range app.Gauges {
+			names = append(names, name)
+		}
+
+		sort.Strings(names)
+
+		for _, name := range names {
+			fmt.Fprintf(&buffer, "  %-10s %d\n", name, app.Gauges[name])
+		}
+
+		fmt.Fprintln(&buffer)
+	}
+
+	if snapshot.Process != nil {
+		process := snapshot.Process
+		fmt.Fprintln(&buffer, "Process")
+		fmt.Fprintf(&buffer, "  %-10s %s\n", "Go", process.Go)
+		fmt.Fprintf(&buffer, "  %-10s %s\n", "Uptime", process.Uptime)
+		fmt.Fprintf(&buffer, "  %-10s %s allocated, %s GC threshold, %d objects\n", "Memory", process.Memory.Allocated, process.Memory.GCThreshold, process.Memory.Objects)
+		fmt.Fprintln(&buffer)
+	}
+
+	if snapshot.System != nil {
+		system := snapshot.System
+		fmt.Fprintln(&buffer, "System")
+		fmt.Fprintf(&buffer, "  %-10s %s/%s\n", "Platform", system.Platform.OS, system.Platform.Arch)
+
+		if system.Uptime != nil {
+			fmt.Fprintf(&buffer, "  %-10s %s\n", "Uptime", *system.Uptime)
+		}
+
+		fmt.Fprintf(&buffer, "  %-10s %d\n", "CPUs", system.CPUs)
+
+		if system.LoadAverage != nil {
+			fmt.Fprintf(&buffer, "  %-10s %.2f %.2f %.2f\n", "Load", system.LoadAverage.One, system.LoadAverage.Five, system.LoadAverage.Fifteen)
+		}
+
+		if system.Memory != nil {
+			fmt.Fprintf(&buffer, "  %-10s %s total, %s free, %s cache\n", "Memory", system.Memory.Total, system.Memory.Free, system.Memory.Cache)
+
+			if color && system.Memory.total > 0 && float64(system.Memory.free) < lowMemoryRatio*float64(system.Memory.total) {
+				fmt.Fprintf(&buffer, "  %sWarning: less than %d%% of the system memory is free%s\n", colorRed, int(lowMemoryRatio*100), colorReset)
+			}
+		}
+
+		if system.Swap != nil {
+			fmt.Fprintf(&buffer, "  %-10s %s total, %s used, %s free\n", "Swap", system.Swap.Total, system.Swap.Used, system.Swap.Free)
+		}
+
+		if system.FileDescriptors != nil {
+			fmt.Fprintf(&buffer, "  %-10s %d open, %d limit\n", "Files", system.FileDescriptors.Open, system.FileDescriptors.Limit)
+		}
+
+		for _, err := range system.CollectionErrors {
+			fmt.Fprintf(&buffer, "  %-10s %s: %s\n", "Error", err.Metric, err.Error)
+		}
+
+		fmt.Fprintln(&buffer)
+	}
+
+	if snapshot.Routes != nil {
+		writeRouteTable(&buffer, snapshot.Routes.Popular, snapshot.timeUnit, color)
+	}
+
+	_, err := w.Write(buffer.Bytes())
+	return err
+}
+
+// writeRouteTable writes the routes as a table whose column widths adapt to the content.
+func writeRouteTable(buffer *bytes.Buffer, routes []*Route, unit time.Duration, color bool) {
+	rows := make([][]string, len(routes))
+
+	for i, route := range routes {
+		rows[i] = []string{
+			route.Route,
+			strconv.FormatUint(route.Requests, 10),
+			formatDuration(milliseconds(route.ResponseTimeMs), unit),
+			formatDuration(milliseconds(route.MinResponseTimeMs), unit),
+			formatDuration(milliseconds(route.MaxResponseTimeMs), unit),
+			strconv.FormatUint(route.Errors, 10),
+		}
+	}
+
+	widths := make([]int, len(textColumns))
+
+	for column, header := range textColumns {
+		widths[column] = len(header)
+
+		for _, row := range rows {
+			if utf8.RuneCountInString(row[column]) > widths[column] {
+				widths[column] = utf8.RuneCountInString(row[column])
+			}
+		}
+	}
+
+	// The route column gets whatever space the numeric columns leave
+	other := 0
+
+	for _, width := range widths[1:] {
+		other += width + 2
+	}
+
+	if widths[0]+other > maxTextLineLength {
+		widths[0] = maxTextLineLength - other
+
+		if widths[0] < len(textColumns[0]) {
+			widths[0] = len(textColumns[0])
+		}
+	}
+
+	writeRow(buffer, textColumns, widths, nil)
+
+	for i, row := range rows {
+		row[0] = truncatePath(row[0], widths[0])
+
+		if color {
+			writeRow(buffer, row, widths, routeColors(routes[i]))
+		} else {
+			writeRow(buffer, row, widths, nil)
+		}
+	}
+}
+
+// routeColors returns the color of each cell in the row of the route.
+func routeColors(route *Route) []string {
+	colors := make([]string, len(textColumns))
+
+	switch {
+	case route.ResponseTimeMs >= criticalResponseTime:
+		colors[0] = colorRed
+		colors[2] = colorRed
+	case route.ResponseTimeMs >= warningResponseTime:
+		colors[0] = colorYellow
+		colors[2] = colorYellow
+	}
+
+	if route.Errors > 0 {
+		colors[5] = colorRed
+	}
+
+	return colors
+}
+
+// writeRow writes a single table row. The first column is left-aligned, the others right-aligned.
+// Colors are applied after padding so that escape codes don't affect the alignment.
+func writeRow(buffer *bytes.Buffer, row []string, widths []int, colors []string) {
+	for column, cell := range row {
+		padding := strings.Repeat(" ", widths[column]-utf8.RuneCountInString(cell))
+
+		if column > 0 {
+			buffer.WriteString("  ")
+			buffer.WriteString(padding)
+		}
+
+		if colors != nil && colors[column] != "" {
+			buffer.WriteString(colors[column])
+			buffer.WriteString(cell)
+			buffer.WriteString(colorReset)
+		} else {
+			buffer.WriteString(cell)
+		}
+
+		if column == 0 {
+			buffer.WriteString(padding)
+		}
+	}
+
+	buffer.WriteByte('\n')
+}
+
+// truncatePath shortens a route path to the given length,
+// keeping its beginning and its more specific end.
+func truncatePath(path string, length int) string {
+	if len(path) <= length {
+		return path
+	}
+
+	if length <= 3 {
+		return path[:length]
+	}
+
+	head := (length - 3) / 3
+	tail := length - 3 - head
+	return path[:head] + "..." + path[len(path)-tail:]
+}
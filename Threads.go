@@ -0,0 +1,36 @@
+package stats
+
+import (
+	"errors"
+	"runtime"
+	"runtime/pprof"
+)
+
+// threadProfile counts the OS threads created by the runtime.
+// The runtime never destroys threads except for locked goroutines that exit,
+// so it is a close upper bound of the current thread count.
+var threadProfile = pprof.Lookup("threadcreate")
+
+// sampleThreads raises the high-water marks of the goroutines and OS threads.
+func (stats *Collector) sampleThreads() (goroutines uint64, threads uint64) {
+	goroutines = uint64(runtime.NumGoroutine())
+	threads = uint64(threadProfile.Count())
+	atomicMax(&stats.maxGoroutines, goroutines)
+	atomicMax(&stats.maxThreads, threads)
+	return goroutines, threads
+}
+
+// processState returns the state of the application process, or nil if the platform doesn't provide it.
+func (stats *Collector) processState() *ProcessState {
+	state, err := stats.system.ProcessState()
+
+	if err != nil {
+		if !errors.Is(err, ErrUnsupported) {
+			stats.reportSystemError("process state", err)
+		}
+
+		return nil
+	}
+
+	return &state
+}
@@ -0,0 +1,63 @@
+package stats
+
+import (
+	"strconv"
+	"time"
+)
+
+// WithTimeUnit sets the unit of response times in human readable output (text and HTML).
+// By default, durations are scaled automatically to µs, ms or s.
+// Machine readable output always uses fields with an explicit unit suffix like ResponseTimeMs.
+func WithTimeUnit(unit time.Duration) Option {
+	return func(stats *Collector) {
+		stats.timeUnit = unit
+	}
+}
+
+// formatDuration formats a duration in the given unit or, if unit is zero, in an automatically chosen unit.
+func formatDuration(duration time.Duration, unit time.Duration) string {
+	if unit == 0 {
+		switch {
+		case duration < time.Millisecond:
+			unit = time.Microsecond
+		case duration < time.Second:
+			unit = time.Millisecond
+		default:
+			unit = time.Second
+		}
+	}
+
+	value := float64(duration) / float64(unit)
+	precision := 0
+
+	if value < 10 && value != float64(int64(value)) {
+		precision = 1
+	}
+
+	return strconv.FormatFloat(value, 'f', precision, 64) + " " + unitSymbol(unit)
+}
+
+// unitSymbol returns the symbol of a time unit.
+func unitSymbol(unit time.Duration) string {
+	switch unit {
+	case time.Nanosecond:
+		return "ns"
+	case time.Microsecond:
+		return "µs"
+	case time.Millisecond:
+		return "ms"
+	case time.Second:
+		return "s"
+	case time.Minute:
+		return "min"
+	case time.Hour:
+		return "h"
+	default:
+		return "× " + unit.String()
+	}
+}
+
+// milliseconds converts a duration in milliseconds back to a time.Duration.
+func milliseconds(ms float64) time.Duration {
+	return time.Duration(ms * float64(time.Millisecond))
+}
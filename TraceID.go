@@ -0,0 +1,64 @@
+package stats
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+// WithTraceID sets a function that returns the trace ID of the request handled with ctx.
+// Trace IDs are attached as exemplars to the latency histogram in the OpenMetrics output.
+func WithTraceID(traceID func(ctx context.Context) string) Option {
+	return func(stats *Collector) {
+		stats.traceID = traceID
+	}
+}
+
+// WithTraceIDExtractor sets the function that reads the trace ID from the request headers.
+// It replaces DefaultTraceIDExtractor, nil disables the extraction.
+func WithTraceIDExtractor(extract func(*http.Request) string) Option {
+	return func(stats *Collector) {
+		stats.traceIDExtractor = extract
+	}
+}
+
+// DefaultTraceIDExtractor reads the trace ID from W3C traceparent and B3 headers.
+func DefaultTraceIDExtractor(request *http.Request) string {
+	// traceparent: version-traceid-spanid-flags
+	if fields := strings.Split(request.Header.Get("traceparent"), "-"); len(fields) == 4 {
+		return fields[1]
+	}
+
+	if traceID := request.Header.Get("X-B3-TraceId"); traceID != "" {
+		return traceID
+	}
+
+	// b3: traceid-spanid-sampled-parentspanid
+	if fields := strings.Split(request.Header.Get("b3"), "-"); len(fields) >= 2 {
+		return fields[0]
+	}
+
+	return ""
+}
+
+// TraceID returns the trace ID of the request handled with ctx, for adapters filling RequestRecord.TraceID.
+// It returns an empty string if no trace ID function is configured.
+func (stats *Collector) TraceID(ctx context.Context) string {
+	if stats.traceID == nil {
+		return ""
+	}
+
+	return stats.traceID(ctx)
+}
+
+// RequestTraceID returns the trace ID of an HTTP request, for adapters filling RequestRecord.TraceID.
+// The request headers take precedence over the trace ID function.
+func (stats *Collector) RequestTraceID(request *http.Request) string {
+	if stats.traceIDExtractor != nil {
+		if traceID := stats.traceIDExtractor(request); traceID != "" {
+			return traceID
+		}
+	}
+
+	return stats.TraceID(request.Context())
+}
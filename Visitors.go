@@ -0,0 +1,202 @@
+package stats
+
+import (
+	"hash/fnv"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// WithUniqueVisitors estimates the number of unique visitors per day with a HyperLogLog sketch,
+// app-wide and for each of the given routes.
+// Visitors are identified by the client IP unless extract is set, e.g. to read a user ID header.
+// Identifiers are hashed immediately, only the fixed size sketches are kept.
+func WithUniqueVisitors(extract func(*http.Request) string, routes ...string) Option {
+	return func(stats *Collector) {
+		if extract == nil {
+			extract = clientAddress
+		}
+
+		stats.visitors = &visitors{
+			extract: extract,
+			routes:  make(map[string]bool, len(routes)),
+		}
+
+		for _, route := range routes {
+			stats.visitors.routes[route] = true
+		}
+	}
+}
+
+// visitors keeps the sketches of the current and the previous day.
+type visitors struct {
+	extract   func(*http.Request) string
+	routes    map[string]bool
+	mutex     sync.Mutex
+	day       time.Time
+	today     *visitorSketches
+	yesterday *visitorSketches
+}
+
+// visitorSketches are the sketches of a single day.
+type visitorSketches struct {
+	app    *HyperLogLog
+	routes map[string]*HyperLogLog
+}
+
+// VisitorStats contains the estimated number of unique visitors of today and yesterday in local time.
+// The estimates have a relative standard error of StandardError.
+type VisitorStats struct {
+	Today         uint64
+	Yesterday     uint64
+	Routes        map[string]VisitorCounts `json:",omitempty"`
+	StandardError float64
+}
+
+// VisitorCounts contains the estimated number of unique visitors of a route.
+type VisitorCounts struct {
+	Today     uint64
+	Yesterday uint64
+}
+
+// RequestVisitor returns the hashed visitor identifier of an HTTP request, for adapters filling RequestRecord.Visitor.
+// It returns 0 if unique visitors are not enabled or the request has no identifier.
+func (stats *Collector) RequestVisitor(request *http.Request) uint64 {
+	if stats.visitors == nil {
+		return 0
+	}
+
+	identifier := stats.visitors.extract(request)
+
+	if identifier == "" {
+		return 0
+	}
+
+	return hashVisitor(identifier)
+}
+
+// VisitorSketch returns a copy of today's app-wide sketch, e.g. to merge the visitors of several instances.
+// It returns nil if unique visitors are not enabled.
+func (stats *Collector) VisitorSketch() *HyperLogLog {
+	if stats.visitors == nil {
+		return nil
+	}
+
+	sketch := NewHyperLogLog()
+	sketch.Merge(stats.visitors.current(stats.clock.Now()).app)
+	return sketch
+}
+
+// record adds the visitor of a finished request.
+func (visitors *visitors) record(now time.Time, record *RequestRecord) {
+	sketches := visitors.current(now)
+	sketches.app.Add(record.Visitor)
+
+	if sketch := sketches.routes[record.Route]; sketch != nil {
+		sketch.Add(record.Visitor)
+	}
+}
+
+// current returns the sketches of the day of now, rotating them when a new day has started.
+func (visitors *visitors) current(now time.Time) *visitorSketches {
+	year, month, day := now.Date()
+	today := time.Date(year, month, day, 0, 0, 0, 0, now.Location())
+
+	visitors.mutex.Lock()
+	defer visitors.mutex.Unlock()
+
+	if visitors.today == nil || !today.Equal(visitors.day) {
+		if visitors.today != nil && today.Equal(visitors.day.AddDate(0, 0, 1)) {
+			visitors.yesterday = visitors.today
+		} else {
+			visitors.yesterday = nil
+		}
+
+		visitors.day = today
+		visitors.today = &visitorSketches{
+			app:    NewHyperLogLog(),
+			routes: make(map[string]*HyperLogLog, len(visitors.routes)),
+		}
+
+		for route := range visitors.routes {
+			visitors.today.routes[route] = NewHyperLogLog()
+		}
+	}
+
+	return visitors.today
+}
+
+// Stats returns the estimated unique visitors.
+func (visitors *visitors) Stats(now time.Time) *VisitorStats {
+	today := visitors.current(now)
+
+	visitors.mutex.Lock()
+	yesterday := visitors.yesterday
+	visitors.mutex.Unlock()
+
+	exported := &VisitorStats{
+		Today:         today.app.Estimate(),
+		StandardError: HyperLogLogError,
+	}
+
+	if yesterday != nil {
+		exported.Yesterday = yesterday.app.Estimate()
+	}
+
+	if len(visitors.routes) > 0 {
+		exported.Routes = make(map[string]VisitorCounts, len(visitors.routes))
+	}
+
+	for route, sketch := range today.routes {
+		counts := VisitorCounts{Today: sketch.Estimate()}
+
+		if yesterday != nil {
+			counts.Yesterday = yesterday.routes[route].Estimate()
+		}
+
+		exported.Routes[route] = counts
+	}
+
+	return exported
+}
+
+// clientAddress returns the IP address of the client without the port.
+func clientAddress(request *http.Request) string {
+	client, _, err := net.SplitHostPort(request.RemoteAddr)
+
+	if err != nil {
+		return request.RemoteAddr
+	}
+
+	return client
+}
+
+// hashVisitor hashes a visitor identifier to a uniformly distributed value that is never 0.
+func hashVisitor(identifier string) uint64 {
+	hash := fnv.New64a()
+	hash.Write([]byte(identifier))
+	value := hash.Sum64()
+
+	// FNV doesn't mix the bits of short strings well enough, finish with the SplitMix64 finalizer
+	value ^= value >> 30
+	value *= 0xbf58476d1ce4e5b9
+	value ^= value >> 27
+	value *= 0x94d049bb133111eb
+	value ^= value >> 31
+
+	if value == 0 {
+		value = 1
+	}
+
+	return value
+}
+
+// visitorStats returns the estimated unique visitors, nil if they are not enabled.
+func (stats *Collector) visitorStats() *VisitorStats {
+	if stats.visitors == nil {
+		return nil
+	}
+
+	return stats.visitors.Stats(stats.clock.Now())
+}
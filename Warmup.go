@@ -0,0 +1,22 @@
+package stats
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// WithWarmup excludes requests made within d after the statistics were created from the latency statistics
+// of their routes, so that cold caches after a deploy don't distort the lifetime averages.
+// These requests are still counted, the snapshot reports how many were excluded.
+// The hourly history still includes their response times.
+func WithWarmup(d time.Duration) Option {
+	return func(stats *Collector) {
+		stats.warmup = d
+	}
+}
+
+// inWarmup tells whether a request finished at now falls into the warm-up period.
+// Without a warm-up period the deadline is 0 and this is always false.
+func (stats *Collector) inWarmup(now time.Time) bool {
+	return now.UnixNano() < atomic.LoadInt64(&stats.warmupUntil)
+}
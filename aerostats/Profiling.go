@@ -0,0 +1,40 @@
+package aerostats
+
+import (
+	"net/http"
+	"net/http/pprof"
+)
+
+// profiles are the runtime profiles served by name, the ones the index page links to.
+var profiles = []string{
+	"allocs",
+	"block",
+	"goroutine",
+	"heap",
+	"mutex",
+	"threadcreate",
+}
+
+// EnableProfiling registers the net/http/pprof handlers on the app under the prefix, e.g. "/debug/pprof",
+// so that profiles can be captured with "go tool pprof http://host/debug/pprof/profile".
+// The index page is served under the prefix with a trailing slash.
+// The routes are protected by the endpoint middlewares of the statistics, e.g. stats.WithBearerToken,
+// without one they are public, so only enable profiling together with authentication in production.
+func (statistics *Statistics) EnableProfiling(prefix string) {
+	get := func(path string, serve http.HandlerFunc) {
+		statistics.Get(prefix+path, handler(statistics.Guard(serve)))
+	}
+
+	get("/", pprof.Index)
+	get("/cmdline", pprof.Cmdline)
+	get("/profile", pprof.Profile)
+	get("/symbol", pprof.Symbol)
+	get("/trace", pprof.Trace)
+
+	// Symbol lookups by address are posted by the pprof tool
+	statistics.Post(prefix+"/symbol", handler(statistics.Guard(pprof.Symbol)))
+
+	for _, name := range profiles {
+		get("/"+name, pprof.Handler(name).ServeHTTP)
+	}
+}
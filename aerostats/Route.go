@@ -0,0 +1,59 @@
+package aerostats
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/aerogo/aero"
+)
+
+// requestState is what the middlewares and the route handlers learn about a request in flight.
+// It is kept next to the request instead of in its context, aero doesn't support replacing the request.
+type requestState struct {
+	route       string
+	handlerTime time.Duration
+}
+
+// Get registers a GET route on the app whose requests are recorded under the pattern, e.g. "/users/:id".
+func (statistics *Statistics) Get(pattern string, handler aero.Handler) {
+	statistics.app.Get(pattern, statistics.route(pattern, handler))
+}
+
+// Post registers a POST route on the app whose requests are recorded under the pattern.
+func (statistics *Statistics) Post(pattern string, handler aero.Handler) {
+	statistics.app.Post(pattern, statistics.route(pattern, handler))
+}
+
+// Delete registers a DELETE route on the app whose requests are recorded under the pattern.
+func (statistics *Statistics) Delete(pattern string, handler aero.Handler) {
+	statistics.app.Delete(pattern, statistics.route(pattern, handler))
+}
+
+// route wraps the handler of a route so that the middleware learns its pattern.
+func (statistics *Statistics) route(pattern string, handler aero.Handler) aero.Handler {
+	return func(ctx aero.Context) error {
+		if state := statistics.state(ctx.Request().Internal()); state != nil {
+			state.route = pattern
+		}
+
+		return handler(ctx)
+	}
+}
+
+// track starts keeping the state of a request until the recording middleware deletes it.
+func (statistics *Statistics) track(request *http.Request) *requestState {
+	state := &requestState{}
+	statistics.requests.Store(request, state)
+	return state
+}
+
+// state returns the state of a request, nil if no recording middleware tracks it.
+func (statistics *Statistics) state(request *http.Request) *requestState {
+	state, exists := statistics.requests.Load(request)
+
+	if !exists {
+		return nil
+	}
+
+	return state.(*requestState)
+}
@@ -0,0 +1,199 @@
+// Package aerostats wires the statistics collector into an aero app.
+//
+//	statistics := aerostats.NewStatistics(app)
+//	app.Use(statistics.Middleware())
+//	statistics.Get("/users/:id", showUser)
+//
+// Routes registered via the Get, Post and Delete methods are recorded under their pattern,
+// requests to other routes under their path with IDs replaced by ":id".
+//
+// To measure only the handlers, use stats.WithScope and install HandlerMiddleware last:
+//
+//	statistics := aerostats.NewStatistics(app, stats.WithScope(stats.ScopeBoth))
+//	app.Use(statistics.Middleware(), sessions, logging, statistics.HandlerMiddleware())
+package aerostats
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aerogo/aero"
+	"github.com/aerogo/stats"
+)
+
+// Statistics is a collector whose endpoint is registered with an aero app.
+type Statistics struct {
+	*stats.Collector
+	app      *aero.Application
+	err      error
+	requests sync.Map
+}
+
+// NewStatistics creates a new collector for the app and registers its endpoint.
+func NewStatistics(app *aero.Application, options ...stats.Option) *Statistics {
+	defaults := []stats.Option{
+		stats.WithStartTime(app.StartTime()),
+	}
+
+	if app.Config != nil {
+		defaults = append(defaults, stats.WithAppConfig(app.Config, stats.SafeConfig{
+			Domain: app.Config.Domain,
+			Title:  app.Config.Title,
+		}))
+	}
+
+	statistics := &Statistics{
+		Collector: stats.NewCollector(append(defaults, options...)...),
+		app:       app,
+	}
+
+	endpoints, err := statistics.Endpoints()
+	statistics.err = err
+
+	// The routes pass through the app's middleware like any other route
+	for _, endpoint := range endpoints {
+		switch endpoint.Method {
+		case http.MethodGet:
+			statistics.Get(endpoint.Path, handler(endpoint.Handler))
+		case http.MethodPost:
+			statistics.Post(endpoint.Path, handler(endpoint.Handler))
+		case http.MethodDelete:
+			statistics.Delete(endpoint.Path, handler(endpoint.Handler))
+		}
+	}
+
+	return statistics
+}
+
+// Err returns the error that occurred while registering the statistics endpoint,
+// e.g. because the HTML template file could not be parsed.
+func (statistics *Statistics) Err() error {
+	return statistics.err
+}
+
+// Middleware returns an aero middleware that records every request under its route pattern
+// with the method, duration, status code and the request and response sizes.
+// aero doesn't tell middleware which route matched, so the pattern is only known for routes
+// registered via Get, Post and Delete, other requests are recorded under their normalized path.
+// Use it before all other middlewares, it measures the whole middleware stack with ScopeFull and ScopeBoth.
+// With ScopeHandler the requests are recorded by HandlerMiddleware instead.
+// Errors returned by handlers are listed in the recent errors.
+// Handlers can use stats.StartSegment, stats.RecordError and stats.ArrivalTime with ctx.Request().Context().
+func (statistics *Statistics) Middleware() aero.Middleware {
+	return func(next aero.Handler) aero.Handler {
+		return func(ctx aero.Context) error {
+			scope := statistics.Scope()
+
+			if scope == stats.ScopeHandler {
+				return next(ctx)
+			}
+
+			request := ctx.Request().Internal()
+			state := statistics.track(request)
+			defer statistics.requests.Delete(request)
+
+			start := time.Now()
+			finish := trackContext(request, start)
+			err := next(ctx)
+			statistics.record(ctx, state.route, time.Since(start), state.handlerTime, err, finish)
+			return err
+		}
+	}
+}
+
+// HandlerMiddleware returns an aero middleware that measures only the handler.
+// Use it after all other middlewares. With ScopeHandler it records the requests,
+// with ScopeBoth it passes the handler time to Middleware and with ScopeFull it does nothing.
+func (statistics *Statistics) HandlerMiddleware() aero.Middleware {
+	return func(next aero.Handler) aero.Handler {
+		return func(ctx aero.Context) error {
+			scope := statistics.Scope()
+
+			if scope == stats.ScopeFull {
+				return next(ctx)
+			}
+
+			request := ctx.Request().Internal()
+
+			if scope == stats.ScopeHandler {
+				state := statistics.track(request)
+				defer statistics.requests.Delete(request)
+
+				start := time.Now()
+				finish := trackContext(request, start)
+				err := next(ctx)
+				statistics.record(ctx, state.route, time.Since(start), 0, err, finish)
+				return err
+			}
+
+			start := time.Now()
+			err := next(ctx)
+
+			if state := statistics.state(request); state != nil {
+				state.handlerTime = time.Since(start)
+			}
+
+			return err
+		}
+	}
+}
+
+// trackContext stamps the arrival time into the context of the request and tracks its segments and errors.
+// aero doesn't support replacing the request, so the context is replaced in the request itself.
+func trackContext(request *http.Request, start time.Time) func(*stats.RequestRecord) {
+	tracked, finish := stats.TrackRequest(context.WithValue(request.Context(), stats.ArrivalTimeKey, start))
+	*request = *request.WithContext(tracked)
+	return finish
+}
+
+// record adds a finished request to the collector.
+// Requests to routes that weren't registered via Get, Post or Delete have no route pattern.
+// The segments and the error recorded by the handler are added by finish, an error returned by it takes precedence.
+func (statistics *Statistics) record(ctx aero.Context, route string, duration time.Duration, handlerTime time.Duration, err error, finish func(*stats.RequestRecord)) {
+	request := ctx.Request().Internal()
+
+	if route == "" {
+		route = stats.NormalizePath(ctx.Path())
+	}
+
+	record := stats.RequestRecord{
+		Route:       statistics.RouteKey(request, route),
+		Method:      ctx.Request().Method(),
+		StatusCode:  ctx.Status(),
+		Duration:    duration,
+		HandlerTime: handlerTime,
+		Conditional: ctx.Request().Header("If-None-Match") != "",
+		TraceID:     statistics.RequestTraceID(request),
+		Visitor:     statistics.RequestVisitor(request),
+		Origin:      statistics.RequestOrigin(request),
+		Aborted:     stats.Aborted(request.Context()),
+	}
+
+	if request.ContentLength > 0 {
+		record.RequestSize = uint64(request.ContentLength)
+	}
+
+	// aero sets the length of complete responses, streamed responses have no size
+	if size, err := strconv.ParseUint(ctx.Response().Internal().Header().Get("Content-Length"), 10, 64); err == nil {
+		record.ResponseSize = size
+	}
+
+	finish(&record)
+
+	if err != nil {
+		record.Error = err.Error()
+	}
+
+	statistics.Record(record)
+}
+
+// handler adapts a net/http handler to aero.
+func handler(handler http.HandlerFunc) aero.Handler {
+	return func(ctx aero.Context) error {
+		handler(ctx.Response().Internal(), ctx.Request().Internal())
+		return nil
+	}
+}
@@ -0,0 +1,32 @@
+// Package dashboard provides the full-featured HTML dashboard for the statistics.
+// All assets are embedded in the binary; no external resources are referenced.
+//
+//	statistics := aerostats.NewStatistics(app, dashboard.Option())
+package dashboard
+
+import (
+	"embed"
+	"html/template"
+	"io/fs"
+
+	"github.com/aerogo/stats"
+)
+
+//go:embed Dashboard.html
+var source string
+
+//go:embed assets
+var files embed.FS
+
+// Option installs the dashboard.
+func Option() stats.Option {
+	tmpl := template.Must(template.New("dashboard").Funcs(stats.TemplateFuncs).Parse(source))
+
+	assets, err := fs.Sub(files, "assets")
+
+	if err != nil {
+		panic(err)
+	}
+
+	return stats.WithDashboard(tmpl, assets)
+}
@@ -0,0 +1,30 @@
+"use strict";
+
+// Sort tables by clicking on a column header
+document.querySelectorAll("table.sortable").forEach(table => {
+	table.querySelectorAll("th").forEach((header, column) => {
+		let descending = true;
+
+		header.addEventListener("click", () => {
+			let body = table.tBodies[0];
+			let rows = Array.from(body.rows);
+
+			rows.sort((a, b) => {
+				let x = a.cells[column].dataset.value || a.cells[column].textContent;
+				let y = b.cells[column].dataset.value || b.cells[column].textContent;
+				let order = isNaN(x) || isNaN(y) ? x.localeCompare(y) : x - y;
+				return descending ? -order : order;
+			});
+
+			descending = !descending;
+			rows.forEach(row => body.appendChild(row));
+		});
+	});
+});
+
+// Refresh the page periodically
+let refresh = parseInt(document.body.dataset.refresh, 10);
+
+if(refresh > 0) {
+	setTimeout(() => location.reload(), refresh * 1000);
+}
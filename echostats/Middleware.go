@@ -0,0 +1,89 @@
+// Package echostats feeds the statistics collector from Echo.
+//
+//	collector := stats.NewCollector()
+//	e := echo.New()
+//	e.Use(echostats.Middleware(collector))
+//
+// The statistics can be mounted under a group with authentication:
+//
+//	admin := e.Group("/admin", middleware.BasicAuth(validate))
+//	admin.GET("/stats", echostats.Handler(collector))
+//
+// Register serves the full dashboard instead, passing the authentication middleware to every route:
+//
+//	echostats.Register(e, collector, middleware.BasicAuth(validate))
+package echostats
+
+import (
+	"time"
+
+	"github.com/aerogo/stats"
+	"github.com/labstack/echo/v4"
+)
+
+// Middleware records every request under its route pattern.
+// Errors returned by handlers are passed to Echo's error handler here instead of being returned,
+// so that the status code it sends is recorded and the error handler runs only once.
+// Install it first, middlewares outside of it don't see the errors.
+func Middleware(collector *stats.Collector) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			start := time.Now()
+			err := next(ctx)
+
+			if err != nil {
+				ctx.Error(err)
+			}
+
+			request := ctx.Request()
+			response := ctx.Response()
+			route := ctx.Path()
+
+			if route == "" {
+				route = stats.NormalizePath(request.URL.Path)
+			}
+
+			record := stats.RequestRecord{
+				Route:        collector.RouteKey(request, route),
+				Method:       request.Method,
+				StatusCode:   response.Status,
+				Duration:     time.Since(start),
+				ResponseSize: uint64(response.Size),
+				Conditional:  request.Header.Get("If-None-Match") != "",
+				TraceID:      collector.RequestTraceID(request),
+				Visitor:      collector.RequestVisitor(request),
+				Origin:       collector.RequestOrigin(request),
+				Aborted:      stats.Aborted(request.Context()),
+			}
+
+			if request.ContentLength > 0 {
+				record.RequestSize = uint64(request.ContentLength)
+			}
+
+			if err != nil {
+				record.Error = err.Error()
+			}
+
+			collector.Record(record)
+			return nil
+		}
+	}
+}
+
+// Handler serves the statistics.
+func Handler(collector *stats.Collector) echo.HandlerFunc {
+	return echo.WrapHandler(collector)
+}
+
+// Register registers all routes of the statistics endpoint, including the dashboard,
+// with the given middleware, e.g. for authentication.
+// The error is non-nil if the HTML template file could not be parsed, the routes are registered nonetheless.
+func Register(e *echo.Echo, collector *stats.Collector, middleware ...echo.MiddlewareFunc) error {
+	endpoints, err := collector.Endpoints()
+
+	for _, endpoint := range endpoints {
+		e.Add(endpoint.Method, endpoint.Path, echo.WrapHandler(endpoint.Handler), middleware...)
+	}
+
+	return err
+}
@@ -0,0 +1,118 @@
+package echostats_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aerogo/stats"
+	"github.com/aerogo/stats/echostats"
+	"github.com/labstack/echo/v4"
+)
+
+// popularRoute returns the route from the Popular summary of the collector.
+func popularRoute(t *testing.T, collector *stats.Collector, path string) *stats.Route {
+	t.Helper()
+
+	for _, route := range collector.Snapshot().Routes.Popular {
+		if route.Route == path {
+			return route
+		}
+	}
+
+	t.Fatalf("route %s not recorded, routes: %v", path, collector.Routes())
+	return nil
+}
+
+func TestMiddlewareRecordsRoutePattern(t *testing.T) {
+	collector := stats.NewCollector()
+	e := echo.New()
+	e.Use(echostats.Middleware(collector))
+	e.GET("/users/:id", func(ctx echo.Context) error {
+		return ctx.String(http.StatusOK, "user "+ctx.Param("id"))
+	})
+
+	for _, path := range []string{"/users/1", "/users/2"} {
+		response := httptest.NewRecorder()
+		e.ServeHTTP(response, httptest.NewRequest(http.MethodGet, path, nil))
+
+		if response.Code != http.StatusOK {
+			t.Fatalf("%s: status %d", path, response.Code)
+		}
+	}
+
+	route := popularRoute(t, collector, "/users/:id")
+
+	if route.Requests != 2 || route.ResponseBytes != uint64(2*len("user 1")) {
+		t.Errorf("%d requests and %d response bytes", route.Requests, route.ResponseBytes)
+	}
+}
+
+func TestMiddlewareRecordsErrorStatus(t *testing.T) {
+	collector := stats.NewCollector()
+	e := echo.New()
+	handled := 0
+
+	e.HTTPErrorHandler = func(err error, ctx echo.Context) {
+		handled++
+		e.DefaultHTTPErrorHandler(err, ctx)
+	}
+
+	e.Use(echostats.Middleware(collector))
+	e.GET("/teapot", func(ctx echo.Context) error {
+		return echo.NewHTTPError(http.StatusTeapot, "short and stout")
+	})
+
+	response := httptest.NewRecorder()
+	e.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/teapot", nil))
+
+	if response.Code != http.StatusTeapot {
+		t.Errorf("status %d", response.Code)
+	}
+
+	if handled != 1 {
+		t.Errorf("the error handler ran %d times", handled)
+	}
+
+	if count := popularRoute(t, collector, "/teapot").StatusClasses["4xx"]; count != 1 {
+		t.Errorf("%d 4xx responses recorded", count)
+	}
+}
+
+func TestHandlerInGroupWithAuth(t *testing.T) {
+	collector := stats.NewCollector()
+	collector.Track("/", 0)
+	e := echo.New()
+
+	requireToken := func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			if ctx.Request().Header.Get("Authorization") != "Bearer secret" {
+				return echo.NewHTTPError(http.StatusUnauthorized)
+			}
+
+			return next(ctx)
+		}
+	}
+
+	admin := e.Group("/admin", requireToken)
+	admin.GET("/stats", echostats.Handler(collector))
+
+	response := httptest.NewRecorder()
+	e.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/admin/stats", nil))
+
+	if response.Code != http.StatusUnauthorized {
+		t.Errorf("status without token %d", response.Code)
+	}
+
+	request := httptest.NewRequest(http.MethodGet, "/admin/stats", nil)
+	request.Header.Set("Authorization", "Bearer secret")
+	response = httptest.NewRecorder()
+	e.ServeHTTP(response, request)
+
+	var snapshot stats.Snapshot
+
+	if err := json.Unmarshal(response.Body.Bytes(), &snapshot); err != nil || snapshot.App == nil || snapshot.App.Requests != 1 {
+		t.Errorf("status %d, body %s", response.Code, response.Body.String())
+	}
+}
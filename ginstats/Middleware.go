@@ -0,0 +1,89 @@
+// Package ginstats feeds the statistics collector from Gin.
+//
+//	collector := stats.NewCollector()
+//	router := gin.New()
+//	router.Use(ginstats.Middleware(collector))
+//	ginstats.Register(router, collector)
+package ginstats
+
+import (
+	"time"
+
+	"github.com/aerogo/stats"
+	"github.com/gin-gonic/gin"
+)
+
+// recordedKey marks requests that are already recorded,
+// so that a middleware installed on the engine and on a group doesn't count them twice.
+const recordedKey = "github.com/aerogo/stats/ginstats.recorded"
+
+// Middleware records every request under its route pattern.
+// Requests that didn't match a route are recorded under their normalized path.
+func Middleware(collector *stats.Collector) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if _, recorded := ctx.Get(recordedKey); recorded {
+			ctx.Next()
+			return
+		}
+
+		ctx.Set(recordedKey, true)
+		start := time.Now()
+		ctx.Next()
+
+		route := ctx.FullPath()
+
+		if route == "" {
+			route = stats.NormalizePath(ctx.Request.URL.Path)
+		}
+
+		record := stats.RequestRecord{
+			Route:       collector.RouteKey(ctx.Request, route),
+			Method:      ctx.Request.Method,
+			StatusCode:  ctx.Writer.Status(),
+			Duration:    time.Since(start),
+			Conditional: ctx.Request.Header.Get("If-None-Match") != "",
+			TraceID:     collector.RequestTraceID(ctx.Request),
+			Visitor:     collector.RequestVisitor(ctx.Request),
+			Origin:      collector.RequestOrigin(ctx.Request),
+			Aborted:     stats.Aborted(ctx.Request.Context()),
+		}
+
+		if ctx.Request.ContentLength > 0 {
+			record.RequestSize = uint64(ctx.Request.ContentLength)
+		}
+
+		// Size is -1 if nothing was written
+		if ctx.Writer.Size() > 0 {
+			record.ResponseSize = uint64(ctx.Writer.Size())
+		}
+
+		if len(ctx.Errors) > 0 {
+			record.Error = ctx.Errors.Last().Error()
+		}
+
+		collector.Record(record)
+	}
+}
+
+// Handler serves the statistics.
+func Handler(collector *stats.Collector) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		collector.ServeHTTP(ctx.Writer, ctx.Request)
+	}
+}
+
+// Register registers all routes of the statistics endpoint, including the dashboard.
+// The error is non-nil if the HTML template file could not be parsed, the routes are registered nonetheless.
+func Register(routes gin.IRoutes, collector *stats.Collector) error {
+	endpoints, err := collector.Endpoints()
+
+	for _, endpoint := range endpoints {
+		handler := endpoint.Handler
+
+		routes.Handle(endpoint.Method, endpoint.Path, func(ctx *gin.Context) {
+			handler(ctx.Writer, ctx.Request)
+		})
+	}
+
+	return err
+}
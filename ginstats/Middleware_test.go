@@ -0,0 +1,119 @@
+package ginstats_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aerogo/stats"
+	"github.com/aerogo/stats/ginstats"
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// popularRoute returns the route from the Popular summary of the collector.
+func popularRoute(t *testing.T, collector *stats.Collector, path string) *stats.Route {
+	t.Helper()
+
+	for _, route := range collector.Snapshot().Routes.Popular {
+		if route.Route == path {
+			return route
+		}
+	}
+
+	t.Fatalf("route %s not recorded, routes: %v", path, collector.Routes())
+	return nil
+}
+
+// serve sends a GET request to the router.
+func serve(router http.Handler, path string) *httptest.ResponseRecorder {
+	response := httptest.NewRecorder()
+	router.ServeHTTP(response, httptest.NewRequest(http.MethodGet, path, nil))
+	return response
+}
+
+func TestMiddlewareRecordsFullPath(t *testing.T) {
+	collector := stats.NewCollector()
+	router := gin.New()
+	router.Use(ginstats.Middleware(collector))
+	router.GET("/users/:id", func(ctx *gin.Context) {
+		ctx.String(http.StatusOK, "user "+ctx.Param("id"))
+	})
+
+	serve(router, "/users/1")
+	serve(router, "/users/2")
+	route := popularRoute(t, collector, "/users/:id")
+
+	if route.Requests != 2 {
+		t.Errorf("%d requests, expected 2", route.Requests)
+	}
+
+	if route.ResponseBytes != 12 {
+		t.Errorf("%d response bytes, expected 12", route.ResponseBytes)
+	}
+}
+
+func TestMiddlewareUnmatchedRoute(t *testing.T) {
+	collector := stats.NewCollector()
+	router := gin.New()
+	router.Use(ginstats.Middleware(collector))
+
+	if response := serve(router, "/missing/123"); response.Code != http.StatusNotFound {
+		t.Fatalf("status %d", response.Code)
+	}
+
+	route := popularRoute(t, collector, stats.NormalizePath("/missing/123"))
+
+	if route.StatusClasses["4xx"] != 1 {
+		t.Errorf("status classes %v", route.StatusClasses)
+	}
+}
+
+func TestMiddlewareRecordsErrors(t *testing.T) {
+	collector := stats.NewCollector()
+	router := gin.New()
+	router.Use(ginstats.Middleware(collector))
+	router.GET("/fail", func(ctx *gin.Context) {
+		ctx.Error(errors.New("database unavailable"))
+		ctx.AbortWithStatus(http.StatusInternalServerError)
+	})
+
+	serve(router, "/fail")
+
+	if route := popularRoute(t, collector, "/fail"); route.Errors != 1 {
+		t.Errorf("%d errors, expected 1", route.Errors)
+	}
+}
+
+func TestMiddlewareInGroup(t *testing.T) {
+	collector := stats.NewCollector()
+	router := gin.New()
+	router.Use(ginstats.Middleware(collector))
+	api := router.Group("/api", ginstats.Middleware(collector))
+	api.GET("/items/:id", func(ctx *gin.Context) {
+		ctx.String(http.StatusOK, "item")
+	})
+
+	serve(router, "/api/items/1")
+
+	if route := popularRoute(t, collector, "/api/items/:id"); route.Requests != 1 {
+		t.Errorf("%d requests, expected the group not to count twice", route.Requests)
+	}
+}
+
+func TestRegister(t *testing.T) {
+	collector := stats.NewCollector()
+	router := gin.New()
+
+	if err := ginstats.Register(router, collector); err != nil {
+		t.Fatal(err)
+	}
+
+	if response := serve(router, stats.DefaultPath); response.Code != http.StatusOK {
+		t.Errorf("status %d: %s", response.Code, response.Body.String())
+	}
+}
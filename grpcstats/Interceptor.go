@@ -0,0 +1,91 @@
+// Package grpcstats feeds the statistics collector from a gRPC server.
+// Methods are recorded under their full name with the protocol "grpc"
+// and their status codes mapped to the equivalent HTTP status codes.
+//
+//	server := grpc.NewServer(
+//		grpc.UnaryInterceptor(grpcstats.UnaryServerInterceptor(collector)),
+//		grpc.StreamInterceptor(grpcstats.StreamServerInterceptor(collector)),
+//	)
+package grpcstats
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/aerogo/stats"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryServerInterceptor records every unary call.
+func UnaryServerInterceptor(collector *stats.Collector) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, request interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		response, err := handler(ctx, request)
+		record(ctx, collector, info.FullMethod, "unary", start, err)
+		return response, err
+	}
+}
+
+// StreamServerInterceptor records every stream, measured from its opening until the handler returns.
+func StreamServerInterceptor(collector *stats.Collector) grpc.StreamServerInterceptor {
+	return func(server interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(server, stream)
+		record(stream.Context(), collector, info.FullMethod, "stream", start, err)
+		return err
+	}
+}
+
+// record adds a finished call to the collector.
+func record(ctx context.Context, collector *stats.Collector, method string, kind string, start time.Time, err error) {
+	code := status.Code(err)
+
+	record := stats.RequestRecord{
+		Route:      method,
+		Protocol:   stats.ProtocolGRPC,
+		Method:     kind,
+		StatusCode: httpStatus(code),
+		Duration:   time.Since(start),
+		TraceID:    collector.TraceID(ctx),
+		Aborted:    code == codes.Canceled || stats.Aborted(ctx),
+	}
+
+	if err != nil {
+		record.Error = err.Error()
+	}
+
+	collector.Record(record)
+}
+
+// httpStatus maps a gRPC status code to the equivalent HTTP status code.
+func httpStatus(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.Canceled:
+		return 499
+	case codes.InvalidArgument, codes.FailedPrecondition, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,122 @@
+package grpcstats_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/aerogo/stats"
+	"github.com/aerogo/stats/grpcstats"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// serve starts a health server with the interceptors on an in-memory connection.
+func serve(t *testing.T, collector *stats.Collector) healthpb.HealthClient {
+	t.Helper()
+	listener := bufconn.Listen(1 << 20)
+
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(grpcstats.UnaryServerInterceptor(collector)),
+		grpc.StreamInterceptor(grpcstats.StreamServerInterceptor(collector)),
+	)
+
+	healthpb.RegisterHealthServer(server, health.NewServer())
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	dial := func(ctx context.Context, _ string) (net.Conn, error) {
+		return listener.DialContext(ctx)
+	}
+
+	conn, err := grpc.NewClient("passthrough:///bufconn", grpc.WithContextDialer(dial), grpc.WithTransportCredentials(insecure.NewCredentials()))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { conn.Close() })
+	return healthpb.NewHealthClient(conn)
+}
+
+// recordedRoute returns the route of the method from the Popular summary, nil if it wasn't recorded yet.
+func recordedRoute(collector *stats.Collector, method string) *stats.Route {
+	for _, route := range collector.Snapshot().Routes.Popular {
+		if route.Route == method {
+			return route
+		}
+	}
+
+	return nil
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	collector := stats.NewCollector()
+	client := serve(t, collector)
+
+	if _, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{}); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "missing"})
+
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	route := recordedRoute(collector, "/grpc.health.v1.Health/Check")
+
+	if route == nil {
+		t.Fatalf("method not recorded, routes: %v", collector.Routes())
+	}
+
+	if route.Protocol != stats.ProtocolGRPC || route.Requests != 2 {
+		t.Errorf("%d %s requests", route.Requests, route.Protocol)
+	}
+
+	if route.StatusClasses["2xx"] != 1 || route.StatusClasses["4xx"] != 1 {
+		t.Errorf("status classes %v, expected NotFound to map to 404", route.StatusClasses)
+	}
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	collector := stats.NewCollector()
+	client := serve(t, collector)
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := client.Watch(ctx, &healthpb.HealthCheckRequest{})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := stream.Recv(); err != nil {
+		t.Fatal(err)
+	}
+
+	if route := recordedRoute(collector, "/grpc.health.v1.Health/Watch"); route != nil {
+		t.Fatal("stream recorded before it was closed")
+	}
+
+	cancel()
+	deadline := time.Now().Add(time.Second)
+	route := recordedRoute(collector, "/grpc.health.v1.Health/Watch")
+
+	for route == nil && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+		route = recordedRoute(collector, "/grpc.health.v1.Health/Watch")
+	}
+
+	if route == nil {
+		t.Fatalf("stream not recorded after it was closed, routes: %v", collector.Routes())
+	}
+
+	if route.ClientAborted != 1 {
+		t.Errorf("%d aborted streams, expected the cancelled stream", route.ClientAborted)
+	}
+}
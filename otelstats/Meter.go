@@ -0,0 +1,138 @@
+// Package otelstats publishes the statistics as OpenTelemetry metrics,
+// so they are exported over OTLP with the same pipeline as the traces.
+//
+//	registration, err := otelstats.Register(collector, otel.GetMeterProvider())
+//	defer registration.Unregister()
+package otelstats
+
+import (
+	"context"
+
+	"github.com/aerogo/stats"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// instrumentationName is the name of the meter, the import path of the statistics package.
+const instrumentationName = "github.com/aerogo/stats"
+
+// instruments are the observable instruments the collector is read into.
+type instruments struct {
+	requests     metric.Int64ObservableCounter
+	errors       metric.Int64ObservableCounter
+	responseTime metric.Float64ObservableGauge
+	percentiles  metric.Float64ObservableGauge
+	received     metric.Int64ObservableCounter
+	sent         metric.Int64ObservableCounter
+	hitRates     metric.Float64ObservableGauge
+	allocated    metric.Int64ObservableGauge
+	gcCycles     metric.Int64ObservableCounter
+	goroutines   metric.Int64ObservableGauge
+	threads      metric.Int64ObservableGauge
+}
+
+// Register creates observable instruments for the route and runtime statistics on a meter of the provider.
+// The collector is read whenever the metrics are collected, e.g. by a periodic OTLP reader.
+// Route metrics carry the route, class and protocol as attributes, the percentiles additionally the quantile.
+func Register(collector *stats.Collector, provider metric.MeterProvider) (metric.Registration, error) {
+	meter := provider.Meter(instrumentationName)
+	var in instruments
+	var err error
+
+	if in.requests, err = meter.Int64ObservableCounter("http.server.requests", metric.WithDescription("Requests per route"), metric.WithUnit("{request}")); err != nil {
+		return nil, err
+	}
+
+	if in.errors, err = meter.Int64ObservableCounter("http.server.errors", metric.WithDescription("Server errors per route"), metric.WithUnit("{request}")); err != nil {
+		return nil, err
+	}
+
+	if in.responseTime, err = meter.Float64ObservableGauge("http.server.response_time.avg", metric.WithDescription("Average response time per route"), metric.WithUnit("ms")); err != nil {
+		return nil, err
+	}
+
+	if in.percentiles, err = meter.Float64ObservableGauge("http.server.response_time.percentile", metric.WithDescription("Response time percentiles per route"), metric.WithUnit("ms")); err != nil {
+		return nil, err
+	}
+
+	if in.received, err = meter.Int64ObservableCounter("http.server.request.bytes", metric.WithDescription("Bytes received in request bodies"), metric.WithUnit("By")); err != nil {
+		return nil, err
+	}
+
+	if in.sent, err = meter.Int64ObservableCounter("http.server.response.bytes", metric.WithDescription("Bytes sent in response bodies"), metric.WithUnit("By")); err != nil {
+		return nil, err
+	}
+
+	if in.hitRates, err = meter.Float64ObservableGauge("ratio.hit_rate", metric.WithDescription("Share of hits in all events per ratio"), metric.WithUnit("1")); err != nil {
+		return nil, err
+	}
+
+	if in.allocated, err = meter.Int64ObservableGauge("process.runtime.go.mem.heap_alloc", metric.WithDescription("Bytes of allocated heap objects"), metric.WithUnit("By")); err != nil {
+		return nil, err
+	}
+
+	if in.gcCycles, err = meter.Int64ObservableCounter("process.runtime.go.gc.count", metric.WithDescription("Completed GC cycles"), metric.WithUnit("{cycle}")); err != nil {
+		return nil, err
+	}
+
+	if in.goroutines, err = meter.Int64ObservableGauge("process.runtime.go.goroutines", metric.WithDescription("Running goroutines"), metric.WithUnit("{goroutine}")); err != nil {
+		return nil, err
+	}
+
+	if in.threads, err = meter.Int64ObservableGauge("process.runtime.go.threads", metric.WithDescription("OS threads including idle ones"), metric.WithUnit("{thread}")); err != nil {
+		return nil, err
+	}
+
+	return meter.RegisterCallback(
+		func(ctx context.Context, observer metric.Observer) error {
+			observe(collector, observer, &in)
+			return nil
+		},
+		in.requests, in.errors, in.responseTime, in.percentiles, in.received, in.sent, in.hitRates,
+		in.allocated, in.gcCycles, in.goroutines, in.threads,
+	)
+}
+
+// observe reads the current statistics into the instruments.
+func observe(collector *stats.Collector, observer metric.Observer, in *instruments) {
+	collector.ForEachRoute(func(path string, route stats.RouteSnapshot) bool {
+		attributes := []attribute.KeyValue{
+			attribute.String("http.route", path),
+			attribute.String("class", route.Class),
+			attribute.String("protocol", route.Protocol),
+		}
+
+		options := metric.WithAttributes(attributes...)
+		observer.ObserveInt64(in.requests, int64(route.Requests), options)
+		observer.ObserveInt64(in.errors, int64(route.Errors), options)
+
+		if route.Requests > 0 {
+			observer.ObserveFloat64(in.responseTime, route.ResponseTimeMs, options)
+		}
+
+		for quantile, value := range route.Percentiles {
+			observer.ObserveFloat64(in.percentiles, value, metric.WithAttributes(append(attributes, attribute.String("quantile", quantile))...))
+		}
+
+		return true
+	})
+
+	snapshot := collector.SnapshotSections(stats.SectionApp, stats.SectionRatios)
+
+	for name, ratio := range snapshot.Ratios {
+		observer.ObserveFloat64(in.hitRates, ratio.HitRate, metric.WithAttributes(attribute.String("ratio", name)))
+	}
+
+	app := snapshot.App
+
+	if app == nil {
+		return
+	}
+
+	observer.ObserveInt64(in.received, int64(app.Bandwidth.ReceivedBytes))
+	observer.ObserveInt64(in.sent, int64(app.Bandwidth.SentBytes))
+	observer.ObserveInt64(in.allocated, int64(app.Memory.AllocatedBytes))
+	observer.ObserveInt64(in.gcCycles, int64(app.Memory.GCCycles))
+	observer.ObserveInt64(in.goroutines, int64(app.Goroutines))
+	observer.ObserveInt64(in.threads, int64(app.Threads))
+}
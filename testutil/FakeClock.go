@@ -0,0 +1,103 @@
+// Package testutil contains helpers for testing code that uses the statistics.
+package testutil
+
+import (
+	"sync"
+	"time"
+
+	"github.com/aerogo/stats"
+)
+
+// FakeClock is a clock that only moves when it is advanced.
+//
+//	clock := testutil.NewFakeClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+//	collector := stats.NewCollector(stats.WithClock(clock))
+//	clock.Advance(time.Hour)
+type FakeClock struct {
+	now     time.Time
+	tickers []*fakeTicker
+	mutex   sync.Mutex
+}
+
+// NewFakeClock creates a clock that starts at the given time.
+func NewFakeClock(start time.Time) *FakeClock {
+	return &FakeClock{
+		now: start,
+	}
+}
+
+// Now returns the current fake time.
+func (clock *FakeClock) Now() time.Time {
+	clock.mutex.Lock()
+	defer clock.mutex.Unlock()
+
+	return clock.now
+}
+
+// Since returns the fake time elapsed since t.
+func (clock *FakeClock) Since(t time.Time) time.Duration {
+	return clock.Now().Sub(t)
+}
+
+// NewTicker returns a ticker that ticks when the clock is advanced past its next tick.
+func (clock *FakeClock) NewTicker(d time.Duration) stats.Ticker {
+	clock.mutex.Lock()
+	defer clock.mutex.Unlock()
+
+	ticker := &fakeTicker{
+		clock:    clock,
+		interval: d,
+		next:     clock.now.Add(d),
+		channel:  make(chan time.Time, 1),
+	}
+
+	clock.tickers = append(clock.tickers, ticker)
+	return ticker
+}
+
+// Advance moves the clock forward and fires all tickers that are due.
+// Like time.Ticker, a ticker drops ticks for slow receivers.
+func (clock *FakeClock) Advance(d time.Duration) {
+	clock.mutex.Lock()
+	defer clock.mutex.Unlock()
+
+	clock.now = clock.now.Add(d)
+
+	for _, ticker := range clock.tickers {
+		for !ticker.next.After(clock.now) {
+			select {
+			case ticker.channel <- ticker.next:
+			default:
+			}
+
+			ticker.next = ticker.next.Add(ticker.interval)
+		}
+	}
+}
+
+// fakeTicker is a ticker driven by a fake clock.
+type fakeTicker struct {
+	clock    *FakeClock
+	interval time.Duration
+	next     time.Time
+	channel  chan time.Time
+}
+
+// C returns the channel of the ticks.
+func (ticker *fakeTicker) C() <-chan time.Time {
+	return ticker.channel
+}
+
+// Stop removes the ticker from its clock.
+func (ticker *fakeTicker) Stop() {
+	clock := ticker.clock
+	clock.mutex.Lock()
+	defer clock.mutex.Unlock()
+
+	for i, other := range clock.tickers {
+		if other == ticker {
+			clock.tickers = append(clock.tickers[:i], clock.tickers[i+1:]...)
+			return
+		}
+	}
+}
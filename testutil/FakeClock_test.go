@@ -0,0 +1,89 @@
+package testutil_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aerogo/stats"
+	"github.com/aerogo/stats/testutil"
+)
+
+var start = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestFakeClockAdvance(t *testing.T) {
+	clock := testutil.NewFakeClock(start)
+
+	if !clock.Now().Equal(start) {
+		t.Fatalf("clock starts at %v", clock.Now())
+	}
+
+	clock.Advance(time.Hour)
+
+	if !clock.Now().Equal(start.Add(time.Hour)) {
+		t.Errorf("clock at %v after an hour", clock.Now())
+	}
+
+	if since := clock.Since(start); since != time.Hour {
+		t.Errorf("%v since the start", since)
+	}
+}
+
+func TestFakeTicker(t *testing.T) {
+	clock := testutil.NewFakeClock(start)
+	ticker := clock.NewTicker(time.Minute)
+	clock.Advance(59 * time.Second)
+
+	select {
+	case <-ticker.C():
+		t.Fatal("tick before the interval")
+	default:
+	}
+
+	clock.Advance(time.Second)
+
+	select {
+	case tick := <-ticker.C():
+		if !tick.Equal(start.Add(time.Minute)) {
+			t.Errorf("tick at %v", tick)
+		}
+	default:
+		t.Fatal("no tick after the interval")
+	}
+
+	// Like time.Ticker, ticks are dropped for slow receivers
+	clock.Advance(10 * time.Minute)
+	<-ticker.C()
+
+	select {
+	case <-ticker.C():
+		t.Error("more than one pending tick")
+	default:
+	}
+
+	ticker.Stop()
+	clock.Advance(time.Hour)
+
+	select {
+	case <-ticker.C():
+		t.Error("tick after Stop")
+	default:
+	}
+}
+
+func TestCollectorUsesClock(t *testing.T) {
+	clock := testutil.NewFakeClock(start)
+	collector := stats.NewCollector(stats.WithClock(clock), stats.WithSystemProvider(&testutil.FakeSystemProvider{}))
+	clock.Advance(90 * time.Minute)
+	collector.Track("/", time.Millisecond)
+	snapshot := collector.Snapshot()
+
+	if !snapshot.Generated.Equal(clock.Now()) {
+		t.Errorf("snapshot generated at %v, the clock is at %v", snapshot.Generated, clock.Now())
+	}
+
+	current := snapshot.History[len(snapshot.History)-1]
+
+	if !current.Start.Equal(start.Add(time.Hour)) || current.Requests != 1 {
+		t.Errorf("current interval starts at %v with %d requests", current.Start, current.Requests)
+	}
+}
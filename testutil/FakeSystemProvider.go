@@ -0,0 +1,62 @@
+package testutil
+
+import (
+	"time"
+
+	"github.com/aerogo/stats"
+)
+
+// FakeSystemProvider returns fixed system statistics.
+// A metric listed in Errors fails with the given error,
+// use stats.ErrUnsupported to simulate a metric that the platform lacks.
+type FakeSystemProvider struct {
+	Load    stats.LoadAverage
+	Mem     stats.MemoryInfo
+	Up      time.Duration
+	SwapUse stats.SwapInfo
+	CPUUse  stats.CPUTimes
+	FDs     stats.FDUsage
+	Process stats.ProcessCPUTime
+	State   stats.ProcessState
+	Errors  map[string]error
+}
+
+// LoadAverage returns the fake system load.
+func (provider *FakeSystemProvider) LoadAverage() (stats.LoadAverage, error) {
+	return provider.Load, provider.Errors["load average"]
+}
+
+// Memory returns the fake memory usage.
+func (provider *FakeSystemProvider) Memory() (stats.MemoryInfo, error) {
+	return provider.Mem, provider.Errors["memory"]
+}
+
+// Uptime returns the fake uptime.
+func (provider *FakeSystemProvider) Uptime() (time.Duration, error) {
+	return provider.Up, provider.Errors["uptime"]
+}
+
+// Swap returns the fake swap usage.
+func (provider *FakeSystemProvider) Swap() (stats.SwapInfo, error) {
+	return provider.SwapUse, provider.Errors["swap"]
+}
+
+// CPU returns the fake CPU times.
+func (provider *FakeSystemProvider) CPU() (stats.CPUTimes, error) {
+	return provider.CPUUse, provider.Errors["cpu"]
+}
+
+// FDUsage returns the fake file descriptor usage.
+func (provider *FakeSystemProvider) FDUsage() (stats.FDUsage, error) {
+	return provider.FDs, provider.Errors["file descriptors"]
+}
+
+// ProcessCPU returns the fake process CPU time.
+func (provider *FakeSystemProvider) ProcessCPU() (stats.ProcessCPUTime, error) {
+	return provider.Process, provider.Errors["process cpu"]
+}
+
+// ProcessState returns the fake process state.
+func (provider *FakeSystemProvider) ProcessState() (stats.ProcessState, error) {
+	return provider.State, provider.Errors["process state"]
+}
@@ -0,0 +1,47 @@
+// Package zapstats logs recorded requests with zap.
+//
+//	collector := stats.NewCollector(stats.WithSink(zapstats.NewSink(logger), stats.SampleEvery(100)))
+package zapstats
+
+import (
+	"github.com/aerogo/stats"
+	"go.uber.org/zap"
+)
+
+// Sink logs every request it receives as a structured event.
+type Sink struct {
+	logger *zap.Logger
+}
+
+// NewSink creates a sink that logs to the given logger.
+func NewSink(logger *zap.Logger) *Sink {
+	return &Sink{
+		logger: logger,
+	}
+}
+
+// Emit logs a recorded request. Server errors are logged at error level.
+func (sink *Sink) Emit(record stats.RequestRecord) {
+	fields := []zap.Field{
+		zap.String("route", record.Route),
+		zap.String("method", record.Method),
+		zap.Int("status", record.StatusCode),
+		zap.Duration("duration", record.Duration),
+		zap.Uint64("response_size", record.ResponseSize),
+	}
+
+	if record.TraceID != "" {
+		fields = append(fields, zap.String("trace_id", record.TraceID))
+	}
+
+	if record.Error != "" {
+		fields = append(fields, zap.String("error", record.Error))
+	}
+
+	if record.StatusCode >= 500 {
+		sink.logger.Error("request", fields...)
+		return
+	}
+
+	sink.logger.Info("request", fields...)
+}
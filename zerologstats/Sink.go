@@ -0,0 +1,49 @@
+// Package zerologstats logs recorded requests with zerolog.
+//
+//	collector := stats.NewCollector(stats.WithSink(zerologstats.NewSink(logger), stats.SlowOrFailed(time.Second)))
+package zerologstats
+
+import (
+	"github.com/aerogo/stats"
+	"github.com/rs/zerolog"
+)
+
+// Sink logs every request it receives as a structured event.
+type Sink struct {
+	logger zerolog.Logger
+}
+
+// NewSink creates a sink that logs to the given logger.
+func NewSink(logger zerolog.Logger) *Sink {
+	return &Sink{
+		logger: logger,
+	}
+}
+
+// Emit logs a recorded request. Server errors are logged at error level.
+func (sink *Sink) Emit(record stats.RequestRecord) {
+	var event *zerolog.Event
+
+	if record.StatusCode >= 500 {
+		event = sink.logger.Error()
+	} else {
+		event = sink.logger.Info()
+	}
+
+	event = event.
+		Str("route", record.Route).
+		Str("method", record.Method).
+		Int("status", record.StatusCode).
+		Dur("duration", record.Duration).
+		Uint64("response_size", record.ResponseSize)
+
+	if record.TraceID != "" {
+		event = event.Str("trace_id", record.TraceID)
+	}
+
+	if record.Error != "" {
+		event = event.Str("error", record.Error)
+	}
+
+	event.Msg("request")
+}